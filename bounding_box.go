@@ -1,9 +1,6 @@
 package geojson
 
-import (
-	"encoding/json"
-	"errors"
-)
+import "errors"
 
 // ErrOddBoundingBox is returned from MarshalJSON if the number of values passed
 // to a BoundingBox is not an odd amount.
@@ -19,9 +16,23 @@ type BoundingBox []float64
 // accurate validation would involve knowing about the geometries, features, or
 // feature collections.
 func (b BoundingBox) MarshalJSON() ([]byte, error) {
-	if len(b)%2 != 0 {
-		return nil, ErrOddBoundingBox
-	}
+	return b.AppendJSON(nil)
+}
 
-	return json.Marshal([]float64(b))
+// Union returns the bounding box of both b and o with as many dimensions as
+// they have in common, or the other when one is nil
+func (b BoundingBox) Union(o BoundingBox) BoundingBox {
+	switch {
+	case b == nil:
+		return append(BoundingBox(nil), o...)
+	case o == nil:
+		return append(BoundingBox(nil), b...)
+	}
+	n := min(len(b), len(o)) / 2
+	u := make(BoundingBox, 2*n)
+	for i := range n {
+		u[i] = min(b[i], o[i])
+		u[n+i] = max(b[len(b)/2+i], o[len(o)/2+i])
+	}
+	return u
 }
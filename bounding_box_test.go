@@ -30,3 +30,20 @@ func TestBoundingBoxMustBeEven(t *testing.T) {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 }
+
+func TestBoundingBoxUnion(t *testing.T) {
+	// Success with the dimensions in common and nil boxes
+	for _, test := range []struct {
+		b, o, expected BoundingBox
+	}{
+		{BoundingBox{0, 0, 1, 1}, BoundingBox{-1, 0.5, 0.5, 2}, BoundingBox{-1, 0, 1, 2}},
+		{BoundingBox{0, 0, 5, 1, 1, 6}, BoundingBox{2, -1, 3, 3}, BoundingBox{0, -1, 3, 3}},
+		{nil, BoundingBox{1, 2, 3, 4}, BoundingBox{1, 2, 3, 4}},
+		{BoundingBox{1, 2, 3, 4}, nil, BoundingBox{1, 2, 3, 4}},
+		{nil, nil, nil},
+	} {
+		if u := test.b.Union(test.o); !reflect.DeepEqual(u, test.expected) {
+			t.Errorf("expected %v but got %v", test.expected, u)
+		}
+	}
+}
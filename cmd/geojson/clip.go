@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var clipCommand = &command{
+	name:    "clip",
+	usage:   "clip -bbox minx,miny,maxx,maxy [-from format] [-o output] [input]",
+	summary: "clip geometries to a bounding box",
+	run:     runClip,
+}
+
+// runClip cuts every geometry to the box. Features left without any part of
+// their geometry are dropped, as are features without a geometry.
+func runClip(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	bbox := fs.String("bbox", "", "`minx,miny,maxx,maxy` of the box to clip to")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	output := fs.String("o", "", "output `file` (default standard output)")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("too many arguments")
+	}
+
+	b, err := parseBox(*bbox)
+	if err != nil {
+		return err
+	}
+
+	input := ""
+	if len(args) == 1 {
+		input = args[0]
+	}
+
+	return geoprocess(e, *from, input, *output, func(r reader) reader {
+		return &geometryReader{r: r, dropNull: true, fn: func(g *geojson.Geometry) (*geojson.Geometry, error) {
+			return b.clipGeometry(g), nil
+		}}
+	})
+}
+
+// parseBox parses the comma separated minx, miny, maxx and maxy of a box
+func parseBox(s string) (box, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return box{}, usageError("-bbox must be minx,miny,maxx,maxy")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return box{}, usageError(fmt.Sprintf("invalid -bbox value %q", p))
+		}
+		v[i] = f
+	}
+	if v[0] > v[2] || v[1] > v[3] {
+		return box{}, usageError("-bbox minimums must not exceed the maximums")
+	}
+	return box{v[0], v[1], v[2], v[3]}, nil
+}
+
+// clipGeometry returns the part of g inside the box or nil if there's none.
+// A LineString cut in several pieces becomes a MultiLineString.
+func (b box) clipGeometry(g *geojson.Geometry) *geojson.Geometry {
+	o := g.Object
+
+	switch {
+	case g.Point != nil:
+		if !b.contains(g.Point.Coordinates) {
+			return nil
+		}
+		return g
+	case g.MultiPoint != nil:
+		var ps geojson.Positions
+		for _, p := range g.MultiPoint.Coordinates {
+			if b.contains(p) {
+				ps = append(ps, p)
+			}
+		}
+		if len(ps) == 0 {
+			return nil
+		}
+		o.Type = "MultiPoint"
+		return &geojson.Geometry{Object: o, MultiPoint: &geojson.MultiPoint{Object: o, Coordinates: ps}}
+	case g.LineString != nil:
+		return lineGeometry(o, b.clipLine(g.LineString.Coordinates))
+	case g.MultiLineString != nil:
+		var lines []geojson.Positions
+		for _, l := range g.MultiLineString.Coordinates {
+			lines = append(lines, b.clipLine(l)...)
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		o.Type = "MultiLineString"
+		return &geojson.Geometry{Object: o, MultiLineString: &geojson.MultiLineString{Object: o, Coordinates: lines}}
+	case g.Polygon != nil:
+		rings := b.clipPolygon(g.Polygon.Coordinates)
+		if rings == nil {
+			return nil
+		}
+		o.Type = "Polygon"
+		return &geojson.Geometry{Object: o, Polygon: &geojson.Polygon{Object: o, Coordinates: rings}}
+	case g.MultiPolygon != nil:
+		var polygons [][]geojson.Positions
+		for _, p := range g.MultiPolygon.Coordinates {
+			if rings := b.clipPolygon(p); rings != nil {
+				polygons = append(polygons, rings)
+			}
+		}
+		if len(polygons) == 0 {
+			return nil
+		}
+		o.Type = "MultiPolygon"
+		return &geojson.Geometry{Object: o, MultiPolygon: &geojson.MultiPolygon{Object: o, Coordinates: polygons}}
+	case g.GeometryCollection != nil:
+		var geometries []geojson.Geometry
+		for i := range g.GeometryCollection.Geometries {
+			if c := b.clipGeometry(&g.GeometryCollection.Geometries[i]); c != nil {
+				geometries = append(geometries, *c)
+			}
+		}
+		if len(geometries) == 0 {
+			return nil
+		}
+		o.Type = "GeometryCollection"
+		return &geojson.Geometry{Object: o, GeometryCollection: &geojson.GeometryCollection{Object: o, Geometries: geometries}}
+	}
+	return nil
+}
+
+// lineGeometry returns a LineString for a single line or a MultiLineString
+func lineGeometry(o geojson.Object, lines []geojson.Positions) *geojson.Geometry {
+	switch len(lines) {
+	case 0:
+		return nil
+	case 1:
+		o.Type = "LineString"
+		return &geojson.Geometry{Object: o, LineString: &geojson.LineString{Object: o, Coordinates: lines[0]}}
+	}
+	o.Type = "MultiLineString"
+	return &geojson.Geometry{Object: o, MultiLineString: &geojson.MultiLineString{Object: o, Coordinates: lines}}
+}
+
+// clipLine returns the pieces of the line inside the box
+func (b box) clipLine(line geojson.Positions) []geojson.Positions {
+	if len(line) == 1 {
+		if b.contains(line[0]) {
+			return []geojson.Positions{line}
+		}
+		return nil
+	}
+
+	var pieces []geojson.Positions
+	var piece geojson.Positions
+	for i := 1; i < len(line); i++ {
+		p, q := line[i-1], line[i]
+		t0, t1, ok := b.clip(p, q)
+		if !ok {
+			continue
+		}
+
+		start, end := interpolate(p, q, t0), interpolate(p, q, t1)
+		if len(piece) == 0 || t0 > 0 {
+			if len(piece) > 1 {
+				pieces = append(pieces, piece)
+			}
+			piece = geojson.Positions{start}
+		}
+		piece = append(piece, end)
+
+		if t1 < 1 {
+			pieces = append(pieces, piece)
+			piece = nil
+		}
+	}
+	if len(piece) > 1 {
+		pieces = append(pieces, piece)
+	}
+	return pieces
+}
+
+// clipPolygon clips every ring with the Sutherland-Hodgman algorithm. It
+// returns nil when nothing of the exterior ring is left, holes left without
+// an area are dropped.
+func (b box) clipPolygon(rings []geojson.Positions) []geojson.Positions {
+	var clipped []geojson.Positions
+	for i, r := range rings {
+		c := b.clipRing(r)
+		if c == nil {
+			if i == 0 {
+				return nil
+			}
+			continue
+		}
+		clipped = append(clipped, c)
+	}
+	return clipped
+}
+
+// clipRing returns the closed ring cut to the box or nil if fewer than four
+// positions are left
+func (b box) clipRing(ring geojson.Positions) geojson.Positions {
+	if len(ring) > 1 && positionEqual(ring[0], ring[len(ring)-1], 0) {
+		ring = ring[:len(ring)-1]
+	}
+
+	// each edge keeps the positions for which inside is true
+	edges := []struct {
+		axis  int
+		value float64
+		min   bool
+	}{
+		{0, b.minX, true},
+		{0, b.maxX, false},
+		{1, b.minY, true},
+		{1, b.maxY, false},
+	}
+	for _, e := range edges {
+		inside := func(p geojson.Position) bool {
+			if e.min {
+				return p[e.axis] >= e.value
+			}
+			return p[e.axis] <= e.value
+		}
+
+		var out geojson.Positions
+		for i, p := range ring {
+			prev := ring[(i+len(ring)-1)%len(ring)]
+			if len(p) < 2 || len(prev) < 2 {
+				continue
+			}
+
+			in, prevIn := inside(p), inside(prev)
+			if in != prevIn {
+				t := (e.value - prev[e.axis]) / (p[e.axis] - prev[e.axis])
+				out = append(out, interpolate(prev, p, t))
+			}
+			if in {
+				out = append(out, p)
+			}
+		}
+		ring = out
+	}
+
+	if len(ring) < 3 {
+		return nil
+	}
+	return append(ring, ring[0])
+}
+
+// interpolate returns the position at fraction t from p to q in every
+// dimension both have
+func interpolate(p, q geojson.Position, t float64) geojson.Position {
+	switch t {
+	case 0:
+		return p
+	case 1:
+		return q
+	}
+
+	r := make(geojson.Position, min(len(p), len(q)))
+	for i := range r {
+		r[i] = p[i] + (q[i]-p[i])*t
+	}
+	return r
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+func TestClip(t *testing.T) {
+	b := box{0, 0, 10, 10}
+
+	// Success splitting a line leaving and entering the box
+	line := geojson.Positions{{-5, 5}, {5, 5}, {5, 15}, {8, 15}, {8, 5}}
+	expected := []geojson.Positions{{{0, 5}, {5, 5}, {5, 10}}, {{8, 10}, {8, 5}}}
+	if pieces := b.clipLine(line); !reflect.DeepEqual(pieces, expected) {
+		t.Errorf("expected %v but got %v", expected, pieces)
+	}
+	g := b.clipGeometry(&geojson.Geometry{LineString: &geojson.LineString{Coordinates: line}})
+	if g == nil || g.MultiLineString == nil || g.Type != "MultiLineString" {
+		t.Errorf("expected a MultiLineString but got %#v", g)
+	}
+
+	// Success cutting a polygon to the box
+	ring := geojson.Positions{{5, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 5}}
+	expected = []geojson.Positions{{{5, 10}, {5, 5}, {10, 5}, {10, 10}, {5, 10}}}
+	if rings := b.clipPolygon([]geojson.Positions{ring}); !reflect.DeepEqual(rings, expected) {
+		t.Errorf("expected %v but got %v", expected, rings)
+	}
+
+	// Fail with a polygon outside the box
+	if rings := b.clipPolygon([]geojson.Positions{{{20, 20}, {30, 20}, {30, 30}, {20, 20}}}); rings != nil {
+		t.Errorf("expected nil but got %v", rings)
+	}
+
+	// Success dropping the features outside the box
+	expected2 := "{\"type\":\"FeatureCollection\",\"features\":[\n" +
+		`{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[102,0.5]},"properties":{"prop0":"value0"}}` +
+		"\n]}\n"
+	if stdout, stderr, status := runCommand(testCollection, "clip", "-bbox", "101.5,0.4,102.1,0.6"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected2 {
+		t.Errorf("expected %q but got %q", expected2, stdout)
+	}
+
+	// Success dropping the features without a geometry
+	expected2 = "{\"type\":\"FeatureCollection\",\"features\":[\n" +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,1]},"properties":null}` +
+		"\n]}\n"
+	input := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null},{"type":"Feature","geometry":{"type":"Point","coordinates":[1,1]},"properties":null}]}`
+	if stdout, stderr, status := runCommand(input, "clip", "-bbox", "0,0,10,10"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected2 {
+		t.Errorf("expected %q but got %q", expected2, stdout)
+	}
+
+	// Fail on an invalid box
+	if _, _, status := runCommand(testCollection, "clip", "-bbox", "1,1,0,0"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
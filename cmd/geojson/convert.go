@@ -0,0 +1,59 @@
+package main
+
+import "fmt"
+
+var convertCommand = &command{
+	name:    "convert",
+	usage:   "convert [-from format] [-to format] [-o output] [input]",
+	summary: "convert between GeoJSON, NDJSON, CSV, WKT, WKB and TopoJSON",
+	run:     runConvert,
+}
+
+// runConvert reads the input and writes it in another format. Formats default
+// to the ones matching the file extensions. Features are streamed unless the
+// input or output format requires the whole collection.
+func runConvert(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	to := fs.String("to", "", fmt.Sprintf("output `format`: %s (default from the output extension)", formatNames()))
+	output := fs.String("o", "", "output `file` (default standard output)")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("too many arguments")
+	}
+
+	input := ""
+	if len(args) == 1 {
+		input = args[0]
+	}
+
+	inFormat, err := lookupFormat(*from, input)
+	if err != nil {
+		return err
+	}
+	outFormat, err := lookupFormat(*to, *output)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(e, input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := createOutput(e, *output)
+	if err != nil {
+		return err
+	}
+
+	if err := transcode(inFormat.newReader(in), outFormat, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCollection = `{"type":"FeatureCollection","features":[
+{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[102,0.5]},"properties":{"prop0":"value0"}},
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[102,0],[103,1],[104,0],[105,1]]},"properties":{"prop0":"value0","prop1":0}},
+{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]]]},"properties":{"prop0":"value0","prop1":{"this":"that"}}}
+]}
+`
+
+func TestConvert(t *testing.T) {
+	// Success converting a collection to WKT
+	expected := "POINT (102 0.5)\nLINESTRING (102 0, 103 1, 104 0, 105 1)\nPOLYGON ((100 0, 101 0, 101 1, 100 1, 100 0))\n"
+	if stdout, stderr, status := runCommand(testCollection, "convert", "-to", "wkt"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success converting a lone geometry to WKT and back
+	expected = `{"type":"Point","coordinates":[1,2]}` + "\n"
+	if stdout, stderr, status := runCommand("POINT (1 2)\n", "convert", "-from", "wkt"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success round tripping through every format that keeps properties
+	for _, f := range []string{"geojson", "ndjson", "topojson"} {
+		converted, stderr, status := runCommand(testCollection, "convert", "-to", f)
+		if status != 0 {
+			t.Errorf("expected status 0 for %v but got %v: %v", f, status, stderr)
+			continue
+		}
+		if stdout, stderr, status := runCommand(converted, "convert", "-from", f); status != 0 {
+			t.Errorf("expected status 0 for %v but got %v: %v", f, status, stderr)
+		} else if stdout != testCollection {
+			t.Errorf("expected %q for %v but got %q", testCollection, f, stdout)
+		}
+	}
+
+	// Success skipping features without a geometry in WKT and WKB
+	withNull := `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null},
+{"type":"Feature","geometry":null,"properties":null},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":null}
+]}
+`
+	expected = `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":null}
+]}
+`
+	for _, f := range []string{"wkt", "wkb"} {
+		converted, stderr, status := runCommand(withNull, "convert", "-to", f)
+		if status != 0 {
+			t.Errorf("expected status 0 for %v but got %v: %v", f, status, stderr)
+			continue
+		}
+		if stdout, stderr, status := runCommand(converted, "convert", "-from", f); status != 0 {
+			t.Errorf("expected status 0 for %v but got %v: %v", f, status, stderr)
+		} else if stdout != expected {
+			t.Errorf("expected %q for %v but got %q", expected, f, stdout)
+		}
+	}
+
+	// Success selecting formats from the file extensions
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.geojson"), filepath.Join(dir, "out.wkt")
+	if err := os.WriteFile(in, []byte(testCollection), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, status := runCommand("", "convert", in, "-o", out); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if b, err := os.ReadFile(out); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if !strings.HasPrefix(string(b), "POINT (102 0.5)\n") {
+		t.Errorf("expected WKT but got %q", string(b))
+	}
+
+	// Fail with an unknown format
+	if _, _, status := runCommand(testCollection, "convert", "-to", "shp"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+
+	// Fail with invalid input
+	if _, _, status := runCommand(`{"type":`, "convert"); status != 1 {
+		t.Errorf("expected status 1 but got %v", status)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/wkt"
+)
+
+// errNoGeometryColumn happens when a CSV header has neither a WKT column nor
+// a pair of coordinate columns
+var errNoGeometryColumn = errors.New("csv: no WKT or longitude/latitude columns found")
+
+// Column names recognised as geometry when reading CSV, compared without case
+var (
+	wktColumns = []string{"wkt", "geometry", "geom", "the_geom"}
+	xColumns   = []string{"lon", "lng", "long", "longitude", "x"}
+	yColumns   = []string{"lat", "latitude", "y"}
+)
+
+// csvReader reads one Feature per CSV row. The geometry is taken from a WKT
+// column or from a pair of longitude and latitude columns. An "id" column
+// becomes the Feature ID and every other column a property. Numeric and
+// boolean values are converted, empty values become null.
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+	// wkt is the index of the WKT column, x and y of the coordinate columns
+	wkt, x, y int
+	id        int
+	coll      *geojson.FeatureCollection
+}
+
+func newCSVReader(r io.Reader) reader {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	return &csvReader{
+		r:    c,
+		wkt:  -1,
+		x:    -1,
+		y:    -1,
+		id:   -1,
+		coll: &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}},
+	}
+}
+
+func (r *csvReader) Read() (*geojson.GeoJSON, error) {
+	if r.header == nil {
+		h, err := r.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.header = h
+
+		for i, name := range h {
+			switch n := strings.ToLower(strings.TrimSpace(name)); {
+			case r.wkt < 0 && contains(wktColumns, n):
+				r.wkt = i
+			case r.x < 0 && contains(xColumns, n):
+				r.x = i
+			case r.y < 0 && contains(yColumns, n):
+				r.y = i
+			case r.id < 0 && n == "id":
+				r.id = i
+			}
+		}
+		if r.wkt < 0 && (r.x < 0 || r.y < 0) {
+			return nil, errNoGeometryColumn
+		}
+	}
+
+	row, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := r.r.FieldPos(0)
+
+	f := &geojson.Feature{Properties: geojson.Properties{}}
+	if r.wkt >= 0 {
+		if s := field(row, r.wkt); s != "" {
+			f.Geometry = new(geojson.Geometry)
+			if err := wkt.Unmarshal([]byte(s), f.Geometry); err != nil {
+				return nil, fmt.Errorf("csv: line %d: %w", line, err)
+			}
+		}
+	} else if x, y := field(row, r.x), field(row, r.y); x != "" && y != "" {
+		lon, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			return nil, fmt.Errorf("csv: line %d: invalid longitude %q", line, x)
+		}
+		lat, err := strconv.ParseFloat(y, 64)
+		if err != nil {
+			return nil, fmt.Errorf("csv: line %d: invalid latitude %q", line, y)
+		}
+		f.Geometry = &geojson.Geometry{
+			Object: geojson.Object{Type: "Point"},
+			Point: &geojson.Point{
+				Object:      geojson.Object{Type: "Point"},
+				Coordinates: geojson.Position{lon, lat},
+			},
+		}
+	}
+
+	for i, name := range r.header {
+		switch {
+		case i == r.wkt:
+			continue
+		case r.wkt < 0 && (i == r.x || i == r.y):
+			continue
+		case i == r.id:
+			f.ID = parseValue(field(row, i))
+			continue
+		}
+		f.Properties[name] = parseValue(field(row, i))
+	}
+
+	return featureObject(f), nil
+}
+
+func (r *csvReader) Collection() *geojson.FeatureCollection {
+	return r.coll
+}
+
+func field(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue converts a CSV value to a JSON value. Numbers with leading zeros
+// such as postal codes are kept as strings.
+func parseValue(s string) interface{} {
+	switch s {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	digits := strings.TrimPrefix(s, "-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return s
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXnN_ ") {
+		return v
+	}
+	return s
+}
+
+// csvWriter buffers every Feature so the header can include the properties of
+// all of them. The geometry is written as a WKT column.
+type csvWriter struct {
+	w        io.Writer
+	features []*geojson.Feature
+}
+
+func newCSVWriter(w io.Writer, c *geojson.FeatureCollection) writer {
+	return &csvWriter{w: w}
+}
+
+func (w *csvWriter) Write(g *geojson.GeoJSON) error {
+	w.features = append(w.features, asFeature(g))
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	// columns are in order of first appearance
+	hasID := false
+	seen := make(map[string]bool)
+	var columns []string
+	for _, f := range w.features {
+		hasID = hasID || f.ID != nil
+
+		keys := make([]string, 0, len(f.Properties))
+		for k := range f.Properties {
+			if !seen[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			seen[k] = true
+			columns = append(columns, k)
+		}
+	}
+
+	c := csv.NewWriter(w.w)
+	header := []string{"WKT"}
+	if hasID {
+		header = append(header, "id")
+	}
+	if err := c.Write(append(header, columns...)); err != nil {
+		return err
+	}
+
+	for _, f := range w.features {
+		row := make([]string, 0, len(header)+len(columns))
+
+		s := ""
+		if f.Geometry != nil {
+			b, err := wkt.Marshal(f.Geometry)
+			if err != nil {
+				return err
+			}
+			s = string(b)
+		}
+		row = append(row, s)
+
+		if hasID {
+			v, err := formatValue(f.ID)
+			if err != nil {
+				return err
+			}
+			row = append(row, v)
+		}
+		for _, k := range columns {
+			v, err := formatValue(f.Properties[k])
+			if err != nil {
+				return err
+			}
+			row = append(row, v)
+		}
+
+		if err := c.Write(row); err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+	return c.Error()
+}
+
+// formatValue converts a JSON value to a CSV value, objects and arrays are
+// written as JSON
+func formatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	// Success reading longitude & latitude columns
+	in := "id,name,lat,lon,zip,count\n7,Home,10.5,20.25,01234,3\n"
+	expected := `{"type":"Feature","id":7,"geometry":{"type":"Point","coordinates":[20.25,10.5]},"properties":{"count":3,"name":"Home","zip":"01234"}}` + "\n"
+	if stdout, stderr, status := runCommand(in, "convert", "-from", "csv", "-to", "ndjson"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success writing and reading a WKT column
+	converted, stderr, status := runCommand(testCollection, "convert", "-to", "csv")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+		return
+	}
+	expected = "WKT,id,prop0,prop1\n" +
+		"POINT (102 0.5),1,value0,\n" +
+		"\"LINESTRING (102 0, 103 1, 104 0, 105 1)\",,value0,0\n" +
+		"\"POLYGON ((100 0, 101 0, 101 1, 100 1, 100 0))\",,value0,\"{\"\"this\"\":\"\"that\"\"}\"\n"
+	if converted != expected {
+		t.Errorf("expected %q but got %q", expected, converted)
+	}
+
+	expected = `{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[102,0.5]},"properties":{"prop0":"value0","prop1":null}}` + "\n"
+	if stdout, stderr, status := runCommand(converted, "convert", "-from", "csv", "-to", "ndjson"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if first := stdout[:len(expected)]; first != expected {
+		t.Errorf("expected %q but got %q", expected, first)
+	}
+
+	// Fail without geometry columns
+	if _, _, status := runCommand("name\nHome\n", "convert", "-from", "csv"); status != 1 {
+		t.Errorf("expected status 1 but got %v", status)
+	}
+}
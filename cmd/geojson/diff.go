@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var diffCommand = &command{
+	name:    "diff",
+	usage:   "diff [-key name] [-tolerance t] [-from format] [-geojson] old new",
+	summary: "report the features added, removed and modified between two datasets",
+	run:     runDiff,
+}
+
+// Kinds of featureChange
+const (
+	changeAdded    = "added"
+	changeRemoved  = "removed"
+	changeModified = "modified"
+)
+
+// featureChange is the difference between two features with the same key
+type featureChange struct {
+	Kind string `json:"type"`
+	// Key is the value identifying the feature in both datasets
+	Key interface{} `json:"key"`
+	// Geometry is set when the geometries differ
+	Geometry bool `json:"geometry,omitempty"`
+	// Properties are the changed properties by key
+	Properties map[string]propertyChange `json:"properties,omitempty"`
+
+	old, new *geojson.Feature
+}
+
+// propertyChange holds the old and new values of a property. A missing side
+// means the property didn't exist.
+type propertyChange struct {
+	Old *interface{} `json:"old,omitempty"`
+	New *interface{} `json:"new,omitempty"`
+}
+
+func runDiff(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	key := fs.String("key", "id", "property `name` identifying features, id for the Feature ID")
+	tolerance := fs.Float64("tolerance", 0, "maximum coordinate difference of unchanged geometries")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	asGeoJSON := fs.Bool("geojson", false, "write the changes as a FeatureCollection")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError("expected old and new inputs")
+	}
+
+	var datasets [2][]*geojson.Feature
+	for i, name := range args {
+		if datasets[i], err = readFeatures(e, *from, name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	changes, unchanged, err := diffFeatures(datasets[0], datasets[1], *key, *tolerance)
+	if err != nil {
+		return err
+	}
+
+	if *asGeoJSON {
+		return writeChangeSet(e.stdout, changes)
+	}
+	return printChanges(e.stdout, changes, unchanged)
+}
+
+// readFeatures reads every object of the named input as a Feature
+func readFeatures(e *env, from, name string) ([]*geojson.Feature, error) {
+	f, err := lookupFormat(from, name)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := openInput(e, name)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	r := f.newReader(in)
+	var features []*geojson.Feature
+	for {
+		g, err := r.Read()
+		if err == io.EOF {
+			return features, nil
+		} else if err != nil {
+			return nil, err
+		}
+		features = append(features, asFeature(g))
+	}
+}
+
+// featureKey returns the key of f. The key "id" is the Feature ID, falling
+// back to an "id" property.
+func featureKey(f *geojson.Feature, key string) interface{} {
+	if key == "id" && f.ID != nil {
+		return f.ID
+	}
+	return f.Properties[key]
+}
+
+// diffFeatures matches the features of old and new by key and returns the
+// changes: removed and modified ones in the order of old followed by the
+// added ones in the order of new
+func diffFeatures(old, new []*geojson.Feature, key string, tolerance float64) ([]*featureChange, int, error) {
+	index := func(features []*geojson.Feature) (map[string]*geojson.Feature, []interface{}, error) {
+		m := make(map[string]*geojson.Feature, len(features))
+		keys := make([]interface{}, len(features))
+		for i, f := range features {
+			k := value(featureKey(f, key))
+			if k == nil {
+				return nil, nil, fmt.Errorf("feature %d has no %s", i+1, key)
+			}
+			b, err := json.Marshal(k)
+			if err != nil {
+				return nil, nil, err
+			}
+			if m[string(b)] != nil {
+				return nil, nil, fmt.Errorf("duplicate %s %s", key, b)
+			}
+			m[string(b)] = f
+			keys[i] = k
+		}
+		return m, keys, nil
+	}
+
+	oldIndex, oldKeys, err := index(old)
+	if err != nil {
+		return nil, 0, fmt.Errorf("old: %w", err)
+	}
+	newIndex, newKeys, err := index(new)
+	if err != nil {
+		return nil, 0, fmt.Errorf("new: %w", err)
+	}
+
+	var changes []*featureChange
+	unchanged := 0
+	for i, o := range old {
+		b, _ := json.Marshal(oldKeys[i])
+		n := newIndex[string(b)]
+		if n == nil {
+			changes = append(changes, &featureChange{Kind: changeRemoved, Key: oldKeys[i], old: o})
+			continue
+		}
+
+		c := &featureChange{
+			Kind:       changeModified,
+			Key:        oldKeys[i],
+			Geometry:   !geometryEqual(o.Geometry, n.Geometry, tolerance),
+			Properties: diffProperties(o.Properties, n.Properties),
+			old:        o,
+			new:        n,
+		}
+		if c.Geometry || len(c.Properties) > 0 {
+			changes = append(changes, c)
+		} else {
+			unchanged++
+		}
+	}
+
+	for i, n := range new {
+		b, _ := json.Marshal(newKeys[i])
+		if oldIndex[string(b)] == nil {
+			changes = append(changes, &featureChange{Kind: changeAdded, Key: newKeys[i], new: n})
+		}
+	}
+
+	return changes, unchanged, nil
+}
+
+func diffProperties(old, new geojson.Properties) map[string]propertyChange {
+	changes := make(map[string]propertyChange)
+	for k, o := range old {
+		o := o
+		if n, ok := new[k]; !ok {
+			changes[k] = propertyChange{Old: &o}
+		} else if !reflect.DeepEqual(value(o), value(n)) {
+			changes[k] = propertyChange{Old: &o, New: &n}
+		}
+	}
+	for k, n := range new {
+		n := n
+		if _, ok := old[k]; !ok {
+			changes[k] = propertyChange{New: &n}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// printChanges writes a line per change followed by the geometry and property
+// changes of modified features, then a summary
+func printChanges(w io.Writer, changes []*featureChange, unchanged int) error {
+	bw := bufio.NewWriter(w)
+
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+		key, _ := formatValue(c.Key)
+
+		switch c.Kind {
+		case changeAdded:
+			fmt.Fprintf(bw, "+ %s\n", key)
+			continue
+		case changeRemoved:
+			fmt.Fprintf(bw, "- %s\n", key)
+			continue
+		}
+
+		fmt.Fprintf(bw, "~ %s\n", key)
+		if c.Geometry {
+			o, n := "null", "null"
+			if v := c.old.Geometry.Value(); v != nil {
+				o = v.GeoJSONType()
+			}
+			if v := c.new.Geometry.Value(); v != nil {
+				n = v.GeoJSONType()
+			}
+			if o != n {
+				fmt.Fprintf(bw, "    geometry: %s -> %s\n", o, n)
+			} else {
+				fmt.Fprintf(bw, "    geometry: %s coordinates changed\n", o)
+			}
+		}
+
+		names := make([]string, 0, len(c.Properties))
+		for k := range c.Properties {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			p := c.Properties[k]
+			fmt.Fprintf(bw, "    %s: %s -> %s\n", k, changedValue(p.Old), changedValue(p.New))
+		}
+	}
+
+	fmt.Fprintf(bw, "%d added, %d removed, %d modified, %d unchanged\n",
+		counts[changeAdded], counts[changeRemoved], counts[changeModified], unchanged)
+	return bw.Flush()
+}
+
+func changedValue(v *interface{}) string {
+	if v == nil {
+		return "(missing)"
+	}
+	b, err := json.Marshal(*v)
+	if err != nil {
+		return fmt.Sprint(*v)
+	}
+	return string(b)
+}
+
+// writeChangeSet writes the changes as a FeatureCollection with one Feature per
+// line. Each Feature is the new version, or the old one when removed, with a
+// "change" foreign member describing the change.
+func writeChangeSet(w io.Writer, changes []*featureChange) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+
+	for i, c := range changes {
+		f := c.new
+		if f == nil {
+			f = c.old
+		}
+
+		b, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		change, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+
+		// the Feature is an object so the member goes before its last "}"
+		b = append(b[:len(b)-1], `,"change":`...)
+		b = append(append(b, change...), '}')
+
+		if i > 0 {
+			bw.WriteString(",\n")
+		}
+		bw.Write(b)
+	}
+
+	if len(changes) > 0 {
+		bw.WriteByte('\n')
+	}
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.geojson")
+	if err := os.WriteFile(old, []byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[102,0.5]},"properties":{"prop0":"value0"}},
+{"type":"Feature","id":3,"geometry":{"type":"Point","coordinates":[0,0]},"properties":{"prop0":"value0"}},
+{"type":"Feature","id":4,"geometry":null,"properties":null}
+]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	updated := filepath.Join(dir, "new.geojson")
+	if err := os.WriteFile(updated, []byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[102.0001,0.5]},"properties":{"prop0":"value1","zip":"01234"}},
+{"type":"Feature","id":2,"geometry":null,"properties":{}},
+{"type":"Feature","id":4,"geometry":null,"properties":null}
+]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Success reporting the changes by ID
+	expected := `~ 1
+    geometry: Point coordinates changed
+    prop0: "value0" -> "value1"
+    zip: (missing) -> "01234"
+- 3
++ 2
+1 added, 1 removed, 1 modified, 1 unchanged
+`
+	if stdout, stderr, status := runCommand("", "diff", old, updated); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success ignoring coordinate differences within the tolerance
+	stdout, stderr, status := runCommand("", "diff", "-tolerance", "0.001", "-geojson", old, updated)
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if expected := `"change":{"type":"modified","key":1,"properties":{"prop0":{"old":"value0","new":"value1"},"zip":{"new":"01234"}}}}`; !strings.Contains(stdout, expected) {
+		t.Errorf("expected %q in %q", expected, stdout)
+	} else if !strings.Contains(stdout, `"change":{"type":"added","key":2}}`) {
+		t.Errorf("expected an added feature in %q", stdout)
+	}
+
+	// Fail when the old features have no key
+	if _, stderr, status := runCommand("", "diff", "-key", "prop1", old, updated); status != 1 {
+		t.Errorf("expected status 1 but got %v", status)
+	} else if !strings.Contains(stderr, "old: feature 1 has no prop1") {
+		t.Errorf("expected a missing key error but got %q", stderr)
+	}
+
+	// Fail with a single input
+	if _, _, status := runCommand("", "diff", old); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
@@ -0,0 +1,582 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// expr is a compiled filter expression evaluated against a Feature. Values are
+// nil, bool, float64, string, []interface{}, map[string]interface{} or box.
+type expr func(f *geojson.Feature) interface{}
+
+// syntaxError is returned by parseExpr for an invalid expression
+type syntaxError struct {
+	// offset is the byte offset of the error in the expression
+	offset int
+	msg    string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.offset+1, e.msg)
+}
+
+// function is a builtin of the expression language
+type function struct {
+	// args is the number of arguments
+	args int
+	call func(f *geojson.Feature, args []interface{}) interface{}
+}
+
+var functions = map[string]function{
+	// has reports whether the feature has the named property, even if null
+	"has": {1, func(f *geojson.Feature, args []interface{}) interface{} {
+		name, ok := args[0].(string)
+		if !ok {
+			return false
+		}
+		_, ok = f.Properties[name]
+		return ok
+	}},
+	// prop returns the named property, for names that aren't identifiers
+	"prop": {1, func(f *geojson.Feature, args []interface{}) interface{} {
+		name, _ := args[0].(string)
+		return value(f.Properties[name])
+	}},
+	// id returns the feature ID
+	"id": {0, func(f *geojson.Feature, args []interface{}) interface{} {
+		return value(f.ID)
+	}},
+	// type returns the geometry type or "null"
+	"type": {0, func(f *geojson.Feature, args []interface{}) interface{} {
+		if v := f.Geometry.Value(); v != nil {
+			return v.GeoJSONType()
+		}
+		return "null"
+	}},
+	// bbox returns the box of minx, miny, maxx and maxy
+	"bbox": {4, func(f *geojson.Feature, args []interface{}) interface{} {
+		b, ok := numbers(args)
+		if !ok {
+			return nil
+		}
+		return box{b[0], b[1], b[2], b[3]}
+	}},
+	// point returns the box of a single position
+	"point": {2, func(f *geojson.Feature, args []interface{}) interface{} {
+		b, ok := numbers(args)
+		if !ok {
+			return nil
+		}
+		return box{b[0], b[1], b[0], b[1]}
+	}},
+	// within reports whether the geometry lies entirely inside a box
+	"within": {1, func(f *geojson.Feature, args []interface{}) interface{} {
+		b, ok := args[0].(box)
+		return ok && b.within(f.Geometry)
+	}},
+	// intersects reports whether the geometry and a box share any point
+	"intersects": {1, func(f *geojson.Feature, args []interface{}) interface{} {
+		b, ok := args[0].(box)
+		return ok && b.intersects(f.Geometry)
+	}},
+}
+
+// parseExpr compiles an expression. The language has:
+//
+//   - literals: numbers, "strings" or 'strings', true, false and null
+//   - properties: name, name.field, name[0] or `quoted name`; missing ones are
+//     null
+//   - geometry: the geometry of the feature as an object, such as
+//     geometry.type, or null without one; a property named geometry is
+//     `geometry`
+//   - operators by increasing precedence: ||, &&, comparisons (== != < <=
+//     > >=), + and -, multiplication (* / %), and the unary ! and -
+//   - functions: has(name), prop(name), id(), type(), bbox(minx, miny, maxx,
+//     maxy), point(x, y), within(box) and intersects(box)
+//
+// Ordering compares numbers or strings and is false for other values.
+// Arithmetic on anything but numbers gives null.
+func parseExpr(s string) (expr, error) {
+	p := &exprParser{s: s}
+	p.next()
+
+	e := p.parseOr()
+	if p.err == nil && p.tok.kind != tokEOF {
+		p.fail("unexpected %s", p.tok)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return e, nil
+}
+
+// truthy reports whether an expression result selects a Feature
+func truthy(v interface{}) bool {
+	return v != nil && v != false
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	num    float64
+	offset int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators are matched longest first
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "[", "]", ".", ","}
+
+type exprParser struct {
+	s   string
+	pos int
+	tok token
+	err error
+}
+
+func (p *exprParser) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = &syntaxError{offset: p.tok.offset, msg: fmt.Sprintf(format, args...)}
+	}
+	// stop consuming input so parsing unwinds quickly
+	p.tok = token{kind: tokEOF, offset: len(p.s)}
+	p.pos = len(p.s)
+}
+
+// next scans the next token into p.tok
+func (p *exprParser) next() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	p.tok = token{offset: start}
+	if p.pos >= len(p.s) {
+		p.tok.kind = tokEOF
+		return
+	}
+
+	switch c := p.s[p.pos]; {
+	case c >= '0' && c <= '9' || c == '.' && p.pos+1 < len(p.s) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9':
+		p.scanNumber()
+	case c == '"' || c == '\'':
+		p.scanString(c)
+	case c == '`':
+		end := strings.IndexByte(p.s[p.pos+1:], '`')
+		if end < 0 {
+			p.fail("unterminated quoted name")
+			return
+		}
+		p.tok.kind = tokIdent
+		p.tok.text = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.s) && (p.s[p.pos] == '_' || unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos]))) {
+			p.pos++
+		}
+		p.tok.kind = tokIdent
+		p.tok.text = p.s[start:p.pos]
+	default:
+		for _, op := range operators {
+			if strings.HasPrefix(p.s[p.pos:], op) {
+				p.tok.kind = tokOp
+				p.tok.text = op
+				p.pos += len(op)
+				return
+			}
+		}
+		p.fail("unexpected character %q", c)
+	}
+}
+
+func (p *exprParser) scanNumber() {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c >= '0' && c <= '9' || c == '.' {
+			p.pos++
+		} else if c == 'e' || c == 'E' {
+			p.pos++
+			if p.pos < len(p.s) && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+				p.pos++
+			}
+		} else {
+			break
+		}
+	}
+
+	text := p.s[start:p.pos]
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.fail("invalid number %q", text)
+		return
+	}
+	p.tok.kind = tokNumber
+	p.tok.text = text
+	p.tok.num = n
+}
+
+func (p *exprParser) scanString(quote byte) {
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch c := p.s[p.pos]; c {
+		case quote:
+			p.pos++
+			p.tok.kind = tokString
+			p.tok.text = b.String()
+			return
+		case '\\':
+			p.pos++
+			if p.pos < len(p.s) {
+				b.WriteByte(p.s[p.pos])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	p.fail("unterminated string")
+}
+
+// accept consumes the operator op if it's the current token
+func (p *exprParser) accept(op string) bool {
+	if p.tok.kind == tokOp && p.tok.text == op {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) {
+	if !p.accept(op) {
+		p.fail("expected %q but found %s", op, p.tok)
+	}
+}
+
+func (p *exprParser) parseOr() expr {
+	l := p.parseAnd()
+	for p.accept("||") {
+		a, b := l, p.parseAnd()
+		l = func(f *geojson.Feature) interface{} {
+			return truthy(a(f)) || truthy(b(f))
+		}
+	}
+	return l
+}
+
+func (p *exprParser) parseAnd() expr {
+	l := p.parseComparison()
+	for p.accept("&&") {
+		a, b := l, p.parseComparison()
+		l = func(f *geojson.Feature) interface{} {
+			return truthy(a(f)) && truthy(b(f))
+		}
+	}
+	return l
+}
+
+func (p *exprParser) parseComparison() expr {
+	l := p.parseAdditive()
+	if p.tok.kind != tokOp {
+		return l
+	}
+
+	var cmp func(a, b interface{}) bool
+	switch p.tok.text {
+	case "==":
+		cmp = equal
+	case "!=":
+		cmp = func(a, b interface{}) bool { return !equal(a, b) }
+	case "<":
+		cmp = ordered(func(c int) bool { return c < 0 })
+	case "<=":
+		cmp = ordered(func(c int) bool { return c <= 0 })
+	case ">":
+		cmp = ordered(func(c int) bool { return c > 0 })
+	case ">=":
+		cmp = ordered(func(c int) bool { return c >= 0 })
+	default:
+		return l
+	}
+	p.next()
+
+	r := p.parseAdditive()
+	return func(f *geojson.Feature) interface{} {
+		return cmp(l(f), r(f))
+	}
+}
+
+func (p *exprParser) parseAdditive() expr {
+	l := p.parseMultiplicative()
+	for p.tok.kind == tokOp && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text
+		p.next()
+		l = arithmetic(op, l, p.parseMultiplicative())
+	}
+	return l
+}
+
+func (p *exprParser) parseMultiplicative() expr {
+	l := p.parseUnary()
+	for p.tok.kind == tokOp && (p.tok.text == "*" || p.tok.text == "/" || p.tok.text == "%") {
+		op := p.tok.text
+		p.next()
+		l = arithmetic(op, l, p.parseUnary())
+	}
+	return l
+}
+
+func (p *exprParser) parseUnary() expr {
+	switch {
+	case p.accept("!"):
+		e := p.parseUnary()
+		return func(f *geojson.Feature) interface{} {
+			return !truthy(e(f))
+		}
+	case p.accept("-"):
+		e := p.parseUnary()
+		return func(f *geojson.Feature) interface{} {
+			if n, ok := e(f).(float64); ok {
+				return -n
+			}
+			return nil
+		}
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() expr {
+	t := p.tok
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		return constant(t.num)
+	case tokString:
+		p.next()
+		return constant(t.text)
+	case tokIdent:
+		p.next()
+		if p.tok.kind == tokOp && p.tok.text == "(" && !strings.HasPrefix(p.s[t.offset:], "`") {
+			return p.parseCall(t)
+		}
+		switch t.text {
+		case "true":
+			return constant(true)
+		case "false":
+			return constant(false)
+		case "null":
+			return constant(nil)
+		case "geometry":
+			if !strings.HasPrefix(p.s[t.offset:], "`") {
+				return p.parsePath(geometryValue)
+			}
+		}
+		name := t.text
+		return p.parsePath(func(f *geojson.Feature) interface{} {
+			return value(f.Properties[name])
+		})
+	case tokOp:
+		if p.accept("(") {
+			e := p.parseOr()
+			p.expect(")")
+			return e
+		}
+	}
+
+	p.fail("unexpected %s", t)
+	return constant(nil)
+}
+
+// parsePath parses the fields and indexes following a property name or
+// geometry, whose value is e
+func (p *exprParser) parsePath(e expr) expr {
+	for {
+		switch {
+		case p.accept("."):
+			if p.tok.kind != tokIdent {
+				p.fail("expected a field name but found %s", p.tok)
+				return e
+			}
+			e = index(e, constant(p.tok.text))
+			p.next()
+		case p.accept("["):
+			i := p.parseOr()
+			p.expect("]")
+			e = index(e, i)
+		default:
+			return e
+		}
+	}
+}
+
+func (p *exprParser) parseCall(name token) expr {
+	fn, ok := functions[name.text]
+	if !ok {
+		p.tok = name
+		p.fail("unknown function %q", name.text)
+		return constant(nil)
+	}
+	p.expect("(")
+
+	var args []expr
+	for p.err == nil && !p.accept(")") {
+		if len(args) > 0 {
+			p.expect(",")
+		}
+		args = append(args, p.parseOr())
+	}
+	if p.err == nil && len(args) != fn.args {
+		p.tok = name
+		p.fail("%s takes %d arguments but got %d", name.text, fn.args, len(args))
+	}
+
+	return func(f *geojson.Feature) interface{} {
+		values := make([]interface{}, len(args))
+		for i, a := range args {
+			values[i] = a(f)
+		}
+		return fn.call(f, values)
+	}
+}
+
+// geometryValue returns the geometry of f as generic JSON or nil without one
+func geometryValue(f *geojson.Feature) interface{} {
+	if f.Geometry.Value() == nil {
+		return nil
+	}
+	b, err := json.Marshal(f.Geometry)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func constant(v interface{}) expr {
+	return func(*geojson.Feature) interface{} {
+		return v
+	}
+}
+
+// index returns the field of an object or the element of an array
+func index(e, i expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		switch v := e(f).(type) {
+		case map[string]interface{}:
+			if k, ok := i(f).(string); ok {
+				return value(v[k])
+			}
+		case []interface{}:
+			if n, ok := i(f).(float64); ok && n >= 0 && n < float64(len(v)) && n == math.Trunc(n) {
+				return value(v[int(n)])
+			}
+		}
+		return nil
+	}
+}
+
+// value normalises a decoded JSON value
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return n
+	case int:
+		return float64(v)
+	case geojson.Properties:
+		return map[string]interface{}(v)
+	}
+	return v
+}
+
+func numbers(args []interface{}) ([]float64, bool) {
+	n := make([]float64, len(args))
+	for i, a := range args {
+		f, ok := a.(float64)
+		if !ok {
+			return nil, false
+		}
+		n[i] = f
+	}
+	return n, true
+}
+
+func arithmetic(op string, l, r expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		a, ok := l(f).(float64)
+		if !ok {
+			return nil
+		}
+		b, ok := r(f).(float64)
+		if !ok {
+			return nil
+		}
+
+		switch op {
+		case "+":
+			return a + b
+		case "-":
+			return a - b
+		case "*":
+			return a * b
+		case "/":
+			return a / b
+		}
+		return math.Mod(a, b)
+	}
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// ordered returns a comparison of numbers or strings using test on the result
+// of comparing them
+func ordered(test func(c int) bool) func(a, b interface{}) bool {
+	return func(a, b interface{}) bool {
+		switch a := a.(type) {
+		case float64:
+			if b, ok := b.(float64); ok {
+				switch {
+				case a < b:
+					return test(-1)
+				case a > b:
+					return test(1)
+				case a == b:
+					return test(0)
+				}
+			}
+		case string:
+			if b, ok := b.(string); ok {
+				return test(strings.Compare(a, b))
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+func TestParseExpr(t *testing.T) {
+	f := &geojson.Feature{
+		ID: "a",
+		Geometry: &geojson.Geometry{LineString: &geojson.LineString{
+			Coordinates: geojson.Positions{{0, 0}, {10, 10}},
+		}},
+		Properties: geojson.Properties{
+			"population": 250000.0,
+			"name":       "Springfield",
+			"tags":       []interface{}{"city", "capital"},
+			"address":    map[string]interface{}{"zip": "01234"},
+			"with space": true,
+			"empty":      nil,
+		},
+	}
+
+	matches := []string{
+		"population > 1e5",
+		"population >= 250000 && population <= 2.5e5",
+		"name == 'Springfield' || false",
+		`name < "T" && !(name == "Shelbyville")`,
+		"tags[1] == \"capital\" && address.zip == \"01234\"",
+		"`with space`",
+		"prop('with space') && has('empty') && !has('missing')",
+		"empty == null && missing == null",
+		"(population / 1000 - 50) * 2 % 7 == 400 % 7",
+		"-population < 0",
+		"id() == 'a' && type() == 'LineString'",
+		"geometry != null && geometry.type == 'LineString' && geometry.coordinates[1][0] == 10",
+		"`geometry` == null",
+		"within(bbox(-1, -1, 10, 10))",
+		"intersects(bbox(4, 5, 5, 6))",
+		"intersects(point(5, 5))",
+	}
+	for _, s := range matches {
+		e, err := parseExpr(s)
+		if err != nil {
+			t.Errorf("%s: expected nil but got '%v'", s, err)
+		} else if !truthy(e(f)) {
+			t.Errorf("%s: expected a match", s)
+		}
+	}
+
+	rejects := []string{
+		"population < 1e5",
+		"name > 1",
+		"missing",
+		"missing + 1 == 1",
+		"tags[2] == 'city'",
+		"within(bbox(1, 1, 10, 10))",
+		"intersects(bbox(6, 0, 10, 4))",
+		"within(1)",
+		"geometry == null",
+	}
+	for _, s := range rejects {
+		e, err := parseExpr(s)
+		if err != nil {
+			t.Errorf("%s: expected nil but got '%v'", s, err)
+		} else if truthy(e(f)) {
+			t.Errorf("%s: expected no match", s)
+		}
+	}
+
+	// Success testing for a null geometry rather than a property
+	e, err := parseExpr("geometry == null")
+	if err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if !truthy(e(&geojson.Feature{Properties: geojson.Properties{"geometry": "x"}})) {
+		t.Errorf("expected a feature without a geometry to match")
+	}
+
+	// Fail on invalid expressions
+	invalid := map[string]int{
+		"":                  0,
+		"population >":      12,
+		"(a":                2,
+		"a ? b":             2,
+		"'open":             0,
+		"unknown(1)":        0,
+		"bbox(1, 2)":        0,
+		"a.1":               1,
+		"a == 1 b":          7,
+		"1e":                0,
+		"within(bbox(1,2":   15,
+		"`unterminated":     0,
+		"has('a') && has(":  16,
+		"intersects(1, 2)":  0,
+		"tags[0":            6,
+		"name == 'x' ) ":    12,
+		"population > 1e5)": 16,
+	}
+	for s, offset := range invalid {
+		_, err := parseExpr(s)
+		var se *syntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%s: expected a syntax error but got '%v'", s, err)
+		} else if se.offset != offset {
+			t.Errorf("%s: expected offset %v but got %v", s, offset, se.offset)
+		}
+	}
+}
+
+func TestBoxIntersects(t *testing.T) {
+	b := box{0, 0, 1, 1}
+
+	// Success with a polygon around the box
+	around := &geojson.Geometry{Polygon: &geojson.Polygon{Coordinates: []geojson.Positions{
+		{{-5, -5}, {5, -5}, {5, 5}, {-5, 5}, {-5, -5}},
+	}}}
+	if !b.intersects(around) {
+		t.Errorf("expected the polygon to intersect the box")
+	}
+	if b.within(around) {
+		t.Errorf("expected the polygon not to be within the box")
+	}
+
+	// Fail with the box in a hole of the polygon
+	around.Polygon.Coordinates = append(around.Polygon.Coordinates, geojson.Positions{{-2, -2}, {2, -2}, {2, 2}, {-2, 2}, {-2, -2}})
+	if b.intersects(around) {
+		t.Errorf("expected the hole not to intersect the box")
+	}
+
+	// Fail without a geometry
+	if b.intersects(nil) || b.within(nil) {
+		t.Errorf("expected a nil geometry not to match")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var filterCommand = &command{
+	name:    "filter",
+	usage:   "filter [-from format] [-to format] [-o output] expression [input]",
+	summary: "keep the features matching an expression",
+	run:     runFilter,
+}
+
+// runFilter streams the features of the input and writes the ones the
+// expression selects. The output is always a collection, a lone Feature or
+// Geometry is treated as a collection of one.
+func runFilter(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	to := fs.String("to", "", fmt.Sprintf("output `format`: %s (default from the output extension)", formatNames()))
+	output := fs.String("o", "", "output `file` (default standard output)")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(args) == 0:
+		return usageError("missing expression")
+	case len(args) > 2:
+		return usageError("too many arguments")
+	}
+
+	match, err := parseExpr(args[0])
+	if err != nil {
+		return usageError(err.Error())
+	}
+
+	input := ""
+	if len(args) == 2 {
+		input = args[1]
+	}
+
+	inFormat, err := lookupFormat(*from, input)
+	if err != nil {
+		return err
+	}
+	outFormat, err := lookupFormat(*to, *output)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(e, input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := createOutput(e, *output)
+	if err != nil {
+		return err
+	}
+
+	r := &filterReader{r: inFormat.newReader(in), match: match}
+	if err := transcode(r, outFormat, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// filterReader returns the features of r for which match is truthy
+type filterReader struct {
+	r     reader
+	match expr
+	coll  *geojson.FeatureCollection
+}
+
+func (r *filterReader) Read() (*geojson.GeoJSON, error) {
+	for {
+		g, err := r.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		if f := asFeature(g); truthy(r.match(f)) {
+			return featureObject(f), nil
+		}
+	}
+}
+
+func (r *filterReader) Foreign() []member {
+	return foreignOf(r.r)
+}
+
+func (r *filterReader) Collection() *geojson.FeatureCollection {
+	if c := r.r.Collection(); c != nil {
+		return c
+	}
+	if r.coll == nil {
+		r.coll = &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}}
+	}
+	return r.coll
+}
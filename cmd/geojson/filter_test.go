@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	// Success keeping the matching features
+	expected := `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[102,0],[103,1],[104,0],[105,1]]},"properties":{"prop0":"value0","prop1":0}}` + "\n"
+	if stdout, stderr, status := runCommand(testCollection, "filter", "-to", "ndjson", "prop1 == 0 && intersects(bbox(102.5, 0, 103.5, 2))"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success writing an empty collection when nothing matches
+	expected = "{\"type\":\"FeatureCollection\",\"features\":[\n]}\n"
+	if stdout, stderr, status := runCommand(`{"type":"Point","coordinates":[0,0]}`, "filter", "within(bbox(1, 1, 2, 2))"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Fail on an invalid expression
+	if _, stderr, status := runCommand(testCollection, "filter", "prop1 >"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	} else if !strings.Contains(stderr, "syntax error at column 8") {
+		t.Errorf("expected a syntax error but got %q", stderr)
+	}
+
+	// Fail without an expression
+	if _, _, status := runCommand(testCollection, "filter"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
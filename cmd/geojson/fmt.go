@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// errNonFinite happens when formatting a coordinate that's NaN or infinite
+var errNonFinite = errors.New("coordinates must be finite numbers")
+
+var fmtCommand = &command{
+	name:    "fmt",
+	usage:   "fmt [-compact] [-precision n] [-l] [-w] [file ...]",
+	summary: "rewrite GeoJSON in a stable, diff friendly layout",
+	run:     runFmt,
+}
+
+// fmtOptions control the layout written by fmt
+type fmtOptions struct {
+	// compact writes coordinate arrays on a single line instead of one
+	// position per line
+	compact bool
+	// precision is the maximum number of decimal places of coordinates and
+	// bounding boxes, negative for as many as needed
+	precision int
+}
+
+func runFmt(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	var o fmtOptions
+	fs.BoolVar(&o.compact, "compact", false, "write coordinate arrays on a single line")
+	fs.IntVar(&o.precision, "precision", -1, "round coordinates to `n` decimal places, negative to keep them as is")
+	list := fs.Bool("l", false, "list files whose formatting differs")
+	write := fs.Bool("w", false, "write the result to the file instead of standard output")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		if *write || *list {
+			return usageError("-w and -l require files")
+		}
+		return formatGeoJSON(e.stdin, e.stdout, o)
+	}
+
+	for _, name := range files {
+		if err := fmtFile(e, name, o, *list, *write); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fmtFile formats a single file. With list or write set the file is only
+// listed or rewritten when its formatting differs.
+func fmtFile(e *env, name string, o fmtOptions, list, write bool) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := formatGeoJSON(bytes.NewReader(src), &out, o); err != nil {
+		return err
+	}
+
+	if !list && !write {
+		_, err := e.stdout.Write(out.Bytes())
+		return err
+	}
+	if bytes.Equal(src, out.Bytes()) {
+		return nil
+	}
+	if list {
+		fmt.Fprintln(e.stdout, name)
+	}
+	if write {
+		return replaceFile(name, out.Bytes())
+	}
+	return nil
+}
+
+// replaceFile atomically replaces the contents of the named file
+func replaceFile(name string, b []byte) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(fi.Mode().Perm()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// formatGeoJSON reads GeoJSON from r and writes it to w in the layout of o
+func formatGeoJSON(r io.Reader, w io.Writer, o fmtOptions) error {
+	f := &format{newWriter: func(w io.Writer, c *geojson.FeatureCollection) writer {
+		return &fmtWriter{w: w, o: o, coll: c}
+	}}
+	return transcode(newGeoJSONReader(r), f, w)
+}
+
+// fmtWriter writes GeoJSON with members in a fixed order: type, id, bbox, crs,
+// geometry, properties, then coordinates, geometries or features. Foreign
+// members of features come last. Objects are indented by two spaces and
+// property keys sorted. The features are buffered so members found after them
+// still come first.
+type fmtWriter struct {
+	w    io.Writer
+	o    fmtOptions
+	coll *geojson.FeatureCollection
+
+	n        int
+	features []byte
+}
+
+func (w *fmtWriter) Write(g *geojson.GeoJSON) error {
+	return w.WriteForeign(g, nil)
+}
+
+func (w *fmtWriter) WriteForeign(g *geojson.GeoJSON, foreign []member) error {
+	var err error
+	if w.coll == nil {
+		if w.n > 0 {
+			return errMultipleObjects
+		}
+		w.n++
+
+		if g.Feature != nil {
+			w.features, err = w.o.appendFeature(nil, g.Feature, foreign, 0)
+		} else {
+			w.features, err = w.o.appendGeometry(nil, g.Geometry, 0)
+		}
+		return err
+	}
+
+	if w.n > 0 {
+		w.features = append(w.features, ',')
+	}
+	w.n++
+	w.features = append(w.features, '\n')
+	w.features = indent(w.features, 2)
+	w.features, err = w.o.appendFeature(w.features, asFeature(g), foreign, 2)
+	return err
+}
+
+func (w *fmtWriter) Close() error {
+	bw := bufio.NewWriter(w.w)
+
+	if w.coll == nil {
+		if w.n == 0 {
+			w.features = append(w.features, "null"...)
+		}
+		bw.Write(w.features)
+		bw.WriteByte('\n')
+		return bw.Flush()
+	}
+
+	b, err := w.o.appendObject(nil, "FeatureCollection", nil, &w.coll.Object, 1)
+	if err != nil {
+		return err
+	}
+	b = append(b, ",\n"...)
+	b = indent(b, 1)
+	b = append(b, `"features": [`...)
+	bw.Write(b)
+	if w.n > 0 {
+		bw.Write(w.features)
+		bw.WriteByte('\n')
+		bw.Write(indent(nil, 1))
+	}
+	bw.WriteString("]\n}\n")
+
+	return bw.Flush()
+}
+
+func indent(b []byte, depth int) []byte {
+	for i := 0; i < depth; i++ {
+		b = append(b, "  "...)
+	}
+	return b
+}
+
+// appendMember appends the start of an object member on its own line
+func appendMember(b []byte, key string, depth int, first bool) []byte {
+	if !first {
+		b = append(b, ',')
+	}
+	b = append(b, '\n')
+	b = indent(b, depth)
+	b = strconv.AppendQuote(b, key)
+	return append(b, ": "...)
+}
+
+// appendObject appends "{" followed by the type, id, bbox and crs members.
+// The object is left open for the remaining members.
+func (o fmtOptions) appendObject(b []byte, typ string, id interface{}, obj *geojson.Object, depth int) ([]byte, error) {
+	b = append(b, '{')
+	b = appendMember(b, "type", depth, true)
+	b = strconv.AppendQuote(b, typ)
+
+	if id != nil {
+		v, err := marshalValue(id, "")
+		if err != nil {
+			return nil, err
+		}
+		b = appendMember(b, "id", depth, false)
+		b = append(b, v...)
+	}
+
+	if obj.BoundingBox != nil {
+		b = appendMember(b, "bbox", depth, false)
+		var err error
+		if b, err = o.appendPosition(b, geojson.Position(*obj.BoundingBox)); err != nil {
+			return nil, err
+		}
+	}
+
+	if obj.CRS != nil {
+		c, err := json.Marshal(obj.CRS)
+		if err != nil {
+			return nil, err
+		}
+		b = appendMember(b, "crs", depth, false)
+		b = append(b, c...)
+	}
+
+	return b, nil
+}
+
+// closeObject appends the "}" ending an object whose members are at depth
+func closeObject(b []byte, depth int) []byte {
+	b = append(b, '\n')
+	b = indent(b, depth-1)
+	return append(b, '}')
+}
+
+// appendFeature appends a Feature whose "{" is at depth. Foreign members come
+// last in their original order.
+func (o fmtOptions) appendFeature(b []byte, f *geojson.Feature, foreign []member, depth int) ([]byte, error) {
+	depth++
+
+	b, err := o.appendObject(b, "Feature", f.ID, &f.Object, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	b = appendMember(b, "geometry", depth, false)
+	if f.Geometry == nil {
+		b = append(b, "null"...)
+	} else if b, err = o.appendGeometry(b, f.Geometry, depth); err != nil {
+		return nil, err
+	}
+
+	b = appendMember(b, "properties", depth, false)
+	if f.Properties == nil {
+		b = append(b, "null"...)
+	} else {
+		p, err := marshalValue(f.Properties, strings.Repeat("  ", depth))
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, p...)
+	}
+
+	for _, m := range foreign {
+		v, err := marshalValue(m.value, strings.Repeat("  ", depth))
+		if err != nil {
+			return nil, err
+		}
+		b = appendMember(b, m.key, depth, false)
+		b = append(b, v...)
+	}
+
+	return closeObject(b, depth), nil
+}
+
+// appendGeometry appends a Geometry whose "{" is at depth
+func (o fmtOptions) appendGeometry(b []byte, g *geojson.Geometry, depth int) ([]byte, error) {
+	depth++
+
+	var obj geojson.Object
+	var coordinates func(b []byte) ([]byte, error)
+	switch {
+	case g.Point != nil:
+		obj = g.Point.Object
+		coordinates = func(b []byte) ([]byte, error) {
+			return o.appendPosition(b, g.Point.Coordinates)
+		}
+	case g.MultiPoint != nil:
+		obj = g.MultiPoint.Object
+		coordinates = func(b []byte) ([]byte, error) {
+			return o.appendPositions(b, g.MultiPoint.Coordinates, depth)
+		}
+	case g.LineString != nil:
+		obj = g.LineString.Object
+		coordinates = func(b []byte) ([]byte, error) {
+			return o.appendPositions(b, g.LineString.Coordinates, depth)
+		}
+	case g.MultiLineString != nil:
+		obj = g.MultiLineString.Object
+		coordinates = func(b []byte) ([]byte, error) {
+			return o.appendRings(b, g.MultiLineString.Coordinates, depth)
+		}
+	case g.Polygon != nil:
+		obj = g.Polygon.Object
+		coordinates = func(b []byte) ([]byte, error) {
+			return o.appendRings(b, g.Polygon.Coordinates, depth)
+		}
+	case g.MultiPolygon != nil:
+		obj = g.MultiPolygon.Object
+		coordinates = func(b []byte) ([]byte, error) {
+			ps := g.MultiPolygon.Coordinates
+			return o.appendArray(b, len(ps), depth, func(b []byte, i, depth int) ([]byte, error) {
+				return o.appendRings(b, ps[i], depth)
+			})
+		}
+	case g.GeometryCollection != nil:
+		obj = g.GeometryCollection.Object
+	default:
+		return nil, geojson.ErrNoGeometry
+	}
+
+	// members of the Geometry itself take precedence over the ones of the
+	// specific type
+	if g.BoundingBox != nil {
+		obj.BoundingBox = g.BoundingBox
+	}
+	if g.CRS != nil {
+		obj.CRS = g.CRS
+	}
+
+	b, err := o.appendObject(b, g.Value().GeoJSONType(), nil, &obj, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	if coordinates != nil {
+		b = appendMember(b, "coordinates", depth, false)
+		if b, err = coordinates(b); err != nil {
+			return nil, err
+		}
+		return closeObject(b, depth), nil
+	}
+
+	// geometries are always one per line
+	gs := g.GeometryCollection.Geometries
+	b = appendMember(b, "geometries", depth, false)
+	b = append(b, '[')
+	for i := range gs {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, '\n')
+		b = indent(b, depth+1)
+		if b, err = o.appendGeometry(b, &gs[i], depth+1); err != nil {
+			return nil, err
+		}
+	}
+	if len(gs) > 0 {
+		b = append(b, '\n')
+		b = indent(b, depth)
+	}
+	b = append(b, ']')
+
+	return closeObject(b, depth), nil
+}
+
+// appendArray appends an array of n items whose "[" is at depth. Items are on
+// their own lines unless the layout is compact.
+func (o fmtOptions) appendArray(b []byte, n, depth int, item func(b []byte, i, depth int) ([]byte, error)) ([]byte, error) {
+	b = append(b, '[')
+	var err error
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b = append(b, ',')
+			if o.compact {
+				b = append(b, ' ')
+			}
+		}
+		if !o.compact {
+			b = append(b, '\n')
+			b = indent(b, depth+1)
+		}
+		if b, err = item(b, i, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	if n > 0 && !o.compact {
+		b = append(b, '\n')
+		b = indent(b, depth)
+	}
+	return append(b, ']'), nil
+}
+
+func (o fmtOptions) appendRings(b []byte, rs []geojson.Positions, depth int) ([]byte, error) {
+	return o.appendArray(b, len(rs), depth, func(b []byte, i, depth int) ([]byte, error) {
+		return o.appendPositions(b, rs[i], depth)
+	})
+}
+
+func (o fmtOptions) appendPositions(b []byte, ps geojson.Positions, depth int) ([]byte, error) {
+	return o.appendArray(b, len(ps), depth, func(b []byte, i, depth int) ([]byte, error) {
+		return o.appendPosition(b, ps[i])
+	})
+}
+
+// appendPosition appends a position on a single line
+func (o fmtOptions) appendPosition(b []byte, p geojson.Position) ([]byte, error) {
+	b = append(b, '[')
+	for i, v := range p {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		var err error
+		if b, err = o.appendNumber(b, v); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, ']'), nil
+}
+
+// appendNumber appends a coordinate rounded to the precision with trailing
+// zeros removed
+func (o fmtOptions) appendNumber(b []byte, v float64) ([]byte, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, errNonFinite
+	}
+	if o.precision < 0 {
+		return strconv.AppendFloat(b, v, 'f', -1, 64), nil
+	}
+
+	s := strconv.FormatFloat(v, 'f', o.precision, 64)
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return append(b, s...), nil
+}
+
+// marshalValue marshals a property value indented with prefix, leaving HTML
+// characters unescaped
+func marshalValue(v interface{}, prefix string) ([]byte, error) {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	e.SetIndent(prefix, "  ")
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmt(t *testing.T) {
+	// Success writing the canonical layout
+	expected := `{
+  "type": "Feature",
+  "id": "a",
+  "bbox": [1, 2, 3, 4],
+  "geometry": {
+    "type": "LineString",
+    "coordinates": [
+      [1.5, 2],
+      [3, 4]
+    ]
+  },
+  "properties": {
+    "a": "<b>",
+    "z": 1
+  }
+}
+`
+	input := `{"properties":{"z":1,"a":"<b>"},"geometry":{"coordinates":[[1.5,2.0],[3,4]],"type":"LineString"},"bbox":[1,2,3,4],"id":"a","type":"Feature"}`
+	stdout, stderr, status := runCommand(input, "fmt")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success formatting the output again without changes
+	if again, stderr, status := runCommand(stdout, "fmt"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if again != stdout {
+		t.Errorf("expected %q but got %q", stdout, again)
+	}
+
+	// Success with compact arrays and rounded coordinates
+	expected = `{
+  "type": "Polygon",
+  "coordinates": [[[0, 0], [1.12, -1], [0, 0]]]
+}
+`
+	stdout, stderr, status = runCommand(`{"type":"Polygon","coordinates":[[[0,-0.001],[1.1234,-1],[0,0]]]}`, "fmt", "-compact", "-precision", "2")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success keeping the collection members before the features
+	stdout, stderr, status = runCommand(`{"features":[],"bbox":[0,0,1,1],"type":"FeatureCollection"}`, "fmt")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if expected := "{\n  \"type\": \"FeatureCollection\",\n  \"bbox\": [0, 0, 1, 1],\n  \"features\": []\n}\n"; stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Fail listing standard input
+	if _, _, status := runCommand(input, "fmt", "-l"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
+
+func TestFmtFiles(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a.geojson")
+	if err := os.WriteFile(name, []byte(testCollection), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Success listing a file that isn't formatted
+	if stdout, stderr, status := runCommand("", "fmt", "-l", name); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != name+"\n" {
+		t.Errorf("expected %q but got %q", name+"\n", stdout)
+	}
+
+	// Success rewriting the file in place
+	if _, stderr, status := runCommand("", "fmt", "-w", name); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "{\n  \"type\": \"FeatureCollection\",\n  \"features\": [\n") {
+		t.Errorf("expected a formatted collection but got %q", b)
+	}
+	if fi, err := os.Stat(name); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 but got %v, %v", fi, err)
+	}
+
+	// Success not listing a formatted file
+	if stdout, stderr, status := runCommand("", "fmt", "-l", name); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != "" {
+		t.Errorf("expected no output but got %q", stdout)
+	}
+
+	// Fail on a missing file
+	if _, _, status := runCommand("", "fmt", filepath.Join(dir, "missing.geojson")); status != 1 {
+		t.Errorf("expected status 1 but got %v", status)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// reader produces the objects of an input one at a time
+type reader interface {
+	// Read returns the next object or io.EOF once the input is exhausted. The
+	// features of a collection are returned one at a time as Features, a lone
+	// Geometry or Feature is returned as is.
+	Read() (*geojson.GeoJSON, error)
+	// Collection returns the FeatureCollection the objects belong to, without
+	// its Features, or nil for a lone object. It's only valid after the first
+	// call to Read. Members that follow the features in the input are filled
+	// in once Read has returned io.EOF.
+	Collection() *geojson.FeatureCollection
+}
+
+// writer consumes the objects produced by a reader
+type writer interface {
+	// Write outputs an object. Writers that can't stream buffer the objects
+	// until Close.
+	Write(g *geojson.GeoJSON) error
+	// Close finishes the output. It doesn't close the underlying io.Writer.
+	Close() error
+}
+
+// foreignReader is implemented by readers that keep the foreign members of
+// features, the members not defined by the specification
+type foreignReader interface {
+	// Foreign returns the foreign members of the object last returned by Read
+	Foreign() []member
+}
+
+// foreignWriter is implemented by writers able to write foreign members
+type foreignWriter interface {
+	// WriteForeign is Write with the foreign members of the object
+	WriteForeign(g *geojson.GeoJSON, foreign []member) error
+}
+
+// foreignOf returns the foreign members of the object last read from r, nil
+// if r doesn't keep them
+func foreignOf(r reader) []member {
+	if fr, ok := r.(foreignReader); ok {
+		return fr.Foreign()
+	}
+	return nil
+}
+
+// format is an input and output file format
+type format struct {
+	// name is the name used by the -from and -to flags
+	name string
+	// exts are the file extensions that select the format
+	exts []string
+	// newReader returns a reader of the format
+	newReader func(r io.Reader) reader
+	// newWriter returns a writer of the format. c is the collection being
+	// written or nil when writing a lone object.
+	newWriter func(w io.Writer, c *geojson.FeatureCollection) writer
+}
+
+// formats are all the supported formats, the first being the default
+var formats = []*format{
+	{name: "geojson", exts: []string{".geojson", ".json"}, newReader: newGeoJSONReader, newWriter: newGeoJSONWriter},
+	{name: "ndjson", exts: []string{".ndjson", ".geojsonl", ".geojsons", ".jsonl"}, newReader: newNDJSONReader, newWriter: newNDJSONWriter},
+	{name: "csv", exts: []string{".csv"}, newReader: newCSVReader, newWriter: newCSVWriter},
+	{name: "wkt", exts: []string{".wkt"}, newReader: newWKTReader, newWriter: newWKTWriter},
+	{name: "wkb", exts: []string{".wkb"}, newReader: newWKBReader, newWriter: newWKBWriter},
+	{name: "topojson", exts: []string{".topojson"}, newReader: newTopoJSONReader, newWriter: newTopoJSONWriter},
+}
+
+// formatNames returns the names of all formats for flag documentation
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// lookupFormat returns the named format or, if name is empty, the format
+// matching the extension of path. The default format is used when neither
+// selects one.
+func lookupFormat(name, path string) (*format, error) {
+	if name != "" {
+		for _, f := range formats {
+			if f.name == strings.ToLower(name) {
+				return f, nil
+			}
+		}
+		return nil, usageError(fmt.Sprintf("unknown format %q, must be one of %s", name, formatNames()))
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range formats {
+		for _, e := range f.exts {
+			if e == ext {
+				return f, nil
+			}
+		}
+	}
+	return formats[0], nil
+}
+
+// asFeature returns the Feature of an object, wrapping a lone Geometry in a
+// Feature without properties
+func asFeature(g *geojson.GeoJSON) *geojson.Feature {
+	if g.Feature != nil {
+		return g.Feature
+	}
+	return &geojson.Feature{
+		Object:   geojson.Object{Type: "Feature"},
+		Geometry: g.Geometry,
+	}
+}
+
+// asGeometry returns the Geometry of a lone Geometry or Feature
+func asGeometry(g *geojson.GeoJSON) *geojson.Geometry {
+	if g.Feature != nil {
+		return g.Feature.Geometry
+	}
+	return g.Geometry
+}
+
+// featureObject returns f as an object like a reader would
+func featureObject(f *geojson.Feature) *geojson.GeoJSON {
+	f.Type = "Feature"
+	return &geojson.GeoJSON{Object: f.Object, Feature: f}
+}
+
+// geometryObject returns g as an object like a reader would
+func geometryObject(g *geojson.Geometry) *geojson.GeoJSON {
+	return &geojson.GeoJSON{Object: g.Object, Geometry: g}
+}
+
+// transcode copies every object of r to a writer of format to. The writer is
+// created after the first Read so it knows whether the input is a collection.
+func transcode(r reader, to *format, w io.Writer) error {
+	g, err := r.Read()
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	out := to.newWriter(w, r.Collection())
+	fw, _ := out.(foreignWriter)
+	for err == nil {
+		if fw != nil {
+			err = fw.WriteForeign(g, foreignOf(r))
+		} else {
+			err = out.Write(g)
+		}
+		if err != nil {
+			return err
+		}
+		g, err = r.Read()
+	}
+	if err != io.EOF {
+		return err
+	}
+
+	return out.Close()
+}
+
+// lookahead implements reader for record based formats. The input is a lone
+// Geometry when it holds exactly one record and a collection otherwise.
+type lookahead struct {
+	// next returns the next record or io.EOF
+	next func() (*geojson.Geometry, error)
+
+	started bool
+	done    bool
+	pending []*geojson.Geometry
+	coll    *geojson.FeatureCollection
+}
+
+func (l *lookahead) Read() (*geojson.GeoJSON, error) {
+	if l.done {
+		return nil, io.EOF
+	}
+
+	if !l.started {
+		l.started = true
+		for i := 0; i < 2; i++ {
+			g, err := l.next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			l.pending = append(l.pending, g)
+		}
+
+		if len(l.pending) == 1 {
+			l.done = true
+			return geometryObject(l.pending[0]), nil
+		}
+		l.coll = &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}}
+		if len(l.pending) == 0 {
+			l.done = true
+			return nil, io.EOF
+		}
+	}
+
+	var g *geojson.Geometry
+	if len(l.pending) > 0 {
+		g, l.pending = l.pending[0], l.pending[1:]
+	} else {
+		var err error
+		if g, err = l.next(); err != nil {
+			l.done = err == io.EOF
+			return nil, err
+		}
+	}
+
+	return featureObject(&geojson.Feature{Geometry: g}), nil
+}
+
+func (l *lookahead) Collection() *geojson.FeatureCollection {
+	return l.coll
+}
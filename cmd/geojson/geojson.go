@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// errMultipleObjects happens when more than one object is written to a writer
+// of a lone object
+var errMultipleObjects = errors.New("cannot write multiple objects without a collection")
+
+// geojsonReader streams the features of a FeatureCollection without holding
+// the whole collection in memory. Any other object is read in one go.
+type geojsonReader struct {
+	d *json.Decoder
+
+	started bool
+	done    bool
+	lone    *geojson.GeoJSON
+	coll    *geojson.FeatureCollection
+	foreign []member
+}
+
+func newGeoJSONReader(r io.Reader) reader {
+	return &geojsonReader{d: json.NewDecoder(r)}
+}
+
+// member is an object member read before knowing the object type
+type member struct {
+	key   string
+	value json.RawMessage
+}
+
+func (r *geojsonReader) Read() (*geojson.GeoJSON, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	if !r.started {
+		r.started = true
+		if err := r.begin(); err != nil {
+			return nil, err
+		}
+		if r.lone != nil {
+			r.done = true
+			return r.lone, nil
+		}
+	}
+
+	if r.d.More() {
+		var raw json.RawMessage
+		if err := r.d.Decode(&raw); err != nil {
+			return nil, err
+		}
+		f := new(geojson.Feature)
+		if err := json.Unmarshal(raw, f); err != nil {
+			return nil, err
+		}
+		foreign, err := readForeign(raw)
+		if err != nil {
+			return nil, err
+		}
+		r.foreign = foreign
+		return featureObject(f), nil
+	}
+
+	// end of the features array, read any trailing members
+	if err := expectDelim(r.d, ']'); err != nil {
+		return nil, err
+	}
+	members, err := readMembers(r.d)
+	if err != nil {
+		return nil, err
+	}
+	if err := setMembers(&r.coll.Object, members); err != nil {
+		return nil, err
+	}
+	if err := expectDelim(r.d, '}'); err != nil {
+		return nil, err
+	}
+
+	r.done = true
+	return nil, io.EOF
+}
+
+// begin reads the members of the top level object up to the features of a
+// FeatureCollection or, for any other object, the whole object
+func (r *geojsonReader) begin() error {
+	if err := expectDelim(r.d, '{'); err != nil {
+		return err
+	}
+
+	var members []member
+	typ := ""
+	for r.d.More() {
+		key, err := readKey(r.d)
+		if err != nil {
+			return err
+		}
+
+		if key == "features" && (typ == "" || typ == "FeatureCollection") {
+			r.coll = &geojson.FeatureCollection{}
+			if err := setMembers(&r.coll.Object, members); err != nil {
+				return err
+			}
+			r.coll.Type = "FeatureCollection"
+			return expectDelim(r.d, '[')
+		}
+
+		var v json.RawMessage
+		if err := r.d.Decode(&v); err != nil {
+			return err
+		}
+		if key == "type" {
+			// a non-string type is reported when unmarshalling below
+			_ = json.Unmarshal(v, &typ)
+		}
+		members = append(members, member{key: key, value: v})
+	}
+	if err := expectDelim(r.d, '}'); err != nil {
+		return err
+	}
+
+	// Not a streamable FeatureCollection so put the object back together
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(m.key)
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(m.value)
+	}
+	b.WriteByte('}')
+
+	r.lone = new(geojson.GeoJSON)
+	if err := json.Unmarshal(b.Bytes(), r.lone); err != nil {
+		return err
+	}
+
+	// A FeatureCollection without features is still a collection
+	if r.lone.FeatureCollection != nil {
+		r.coll = r.lone.FeatureCollection
+		r.lone = nil
+		r.done = true
+	}
+	if r.lone != nil && r.lone.Feature != nil {
+		r.foreign = foreignMembers(members)
+	}
+	return nil
+}
+
+func (r *geojsonReader) Collection() *geojson.FeatureCollection {
+	return r.coll
+}
+
+func (r *geojsonReader) Foreign() []member {
+	return r.foreign
+}
+
+// featureMembers are the members of a Feature defined by the specification
+var featureMembers = []string{"type", "id", "bbox", "crs", "geometry", "properties"}
+
+// foreignMembers returns the members that aren't featureMembers
+func foreignMembers(members []member) []member {
+	var foreign []member
+	for _, m := range members {
+		if !contains(featureMembers, m.key) {
+			foreign = append(foreign, m)
+		}
+	}
+	return foreign
+}
+
+// readForeign returns the foreign members of an encoded Feature
+func readForeign(raw json.RawMessage) ([]member, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	if err := expectDelim(d, '{'); err != nil {
+		return nil, err
+	}
+	members, err := readMembers(d)
+	if err != nil {
+		return nil, err
+	}
+	return foreignMembers(members), nil
+}
+
+// appendForeign appends the foreign members to an encoded object
+func appendForeign(b []byte, foreign []member) []byte {
+	if len(foreign) == 0 {
+		return b
+	}
+
+	b = b[:len(b)-1]
+	for _, m := range foreign {
+		b = append(b, ',')
+		k, _ := json.Marshal(m.key)
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, m.value...)
+	}
+	return append(b, '}')
+}
+
+// readMembers reads the remaining members of an object
+func readMembers(d *json.Decoder) ([]member, error) {
+	var members []member
+	for d.More() {
+		key, err := readKey(d)
+		if err != nil {
+			return nil, err
+		}
+		var v json.RawMessage
+		if err := d.Decode(&v); err != nil {
+			return nil, err
+		}
+		members = append(members, member{key: key, value: v})
+	}
+	return members, nil
+}
+
+// setMembers fills in the common object members found in members
+func setMembers(o *geojson.Object, members []member) error {
+	for _, m := range members {
+		var err error
+		switch m.key {
+		case "type":
+			err = json.Unmarshal(m.value, &o.Type)
+		case "bbox":
+			err = json.Unmarshal(m.value, &o.BoundingBox)
+		case "crs":
+			err = json.Unmarshal(m.value, &o.CRS)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readKey(d *json.Decoder) (string, error) {
+	t, err := d.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := t.(string)
+	if !ok {
+		return "", geojson.ErrInvalidGeoJSON
+	}
+	return key, nil
+}
+
+func expectDelim(d *json.Decoder, delim json.Delim) error {
+	t, err := d.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	if t != delim {
+		return geojson.ErrInvalidGeoJSON
+	}
+	return nil
+}
+
+// geojsonWriter writes a lone object as is or streams a FeatureCollection
+// with one Feature per line
+type geojsonWriter struct {
+	w    *bufio.Writer
+	coll *geojson.FeatureCollection
+
+	n int
+	// wroteBBox and wroteCRS are set when the header had those members
+	wroteBBox, wroteCRS bool
+}
+
+func newGeoJSONWriter(w io.Writer, c *geojson.FeatureCollection) writer {
+	return &geojsonWriter{w: bufio.NewWriter(w), coll: c}
+}
+
+func (w *geojsonWriter) Write(g *geojson.GeoJSON) error {
+	return w.WriteForeign(g, nil)
+}
+
+func (w *geojsonWriter) WriteForeign(g *geojson.GeoJSON, foreign []member) error {
+	if w.coll == nil {
+		if w.n > 0 {
+			return errMultipleObjects
+		}
+		w.n++
+		return w.encodeForeign(g, foreign)
+	}
+
+	if w.n == 0 {
+		if err := w.header(); err != nil {
+			return err
+		}
+	} else {
+		w.w.WriteString(",\n")
+	}
+	w.n++
+
+	return w.encodeForeign(asFeature(g), foreign)
+}
+
+// header writes the start of the FeatureCollection up to its features
+func (w *geojsonWriter) header() error {
+	w.w.WriteString(`{"type":"FeatureCollection"`)
+	if w.coll.BoundingBox != nil {
+		w.w.WriteString(`,"bbox":`)
+		if err := w.encode(w.coll.BoundingBox); err != nil {
+			return err
+		}
+		w.wroteBBox = true
+	}
+	if w.coll.CRS != nil {
+		w.w.WriteString(`,"crs":`)
+		if err := w.encode(w.coll.CRS); err != nil {
+			return err
+		}
+		w.wroteCRS = true
+	}
+	_, err := w.w.WriteString(`,"features":[` + "\n")
+	return err
+}
+
+func (w *geojsonWriter) encode(v interface{}) error {
+	return w.encodeForeign(v, nil)
+}
+
+func (w *geojsonWriter) encodeForeign(v interface{}, foreign []member) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(appendForeign(b, foreign))
+	return err
+}
+
+func (w *geojsonWriter) Close() error {
+	if w.coll == nil {
+		if w.n == 0 {
+			w.w.WriteString("null")
+		}
+		w.w.WriteByte('\n')
+		return w.w.Flush()
+	}
+
+	if w.n == 0 {
+		if err := w.header(); err != nil {
+			return err
+		}
+	} else {
+		w.w.WriteByte('\n')
+	}
+	w.w.WriteByte(']')
+
+	// members only known once the input was exhausted
+	if w.coll.BoundingBox != nil && !w.wroteBBox {
+		w.w.WriteString(`,"bbox":`)
+		if err := w.encode(w.coll.BoundingBox); err != nil {
+			return err
+		}
+	}
+	if w.coll.CRS != nil && !w.wroteCRS {
+		w.w.WriteString(`,"crs":`)
+		if err := w.encode(w.coll.CRS); err != nil {
+			return err
+		}
+	}
+	w.w.WriteString("}\n")
+
+	return w.w.Flush()
+}
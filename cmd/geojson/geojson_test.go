@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGeoJSONReader(t *testing.T) {
+	// Success streaming features with members around them
+	r := newGeoJSONReader(strings.NewReader(`{
+		"type": "FeatureCollection",
+		"crs": {"type": "name", "properties": {"name": "urn:ogc:def:crs:OGC:1.3:CRS84"}},
+		"features": [
+			{"type": "Feature", "geometry": null, "properties": {"a": 1}},
+			{"type": "Feature", "geometry": null, "properties": {"a": 2}}
+		],
+		"bbox": [0, 0, 1, 1]
+	}`))
+
+	n := 0
+	for {
+		g, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Errorf("expected nil but got '%v'", err)
+			return
+		}
+		n++
+		if g.Feature == nil || g.Feature.Properties["a"] != float64(n) {
+			t.Errorf("expected Feature %v but got %#v", n, g)
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected 2 features but got %v", n)
+	}
+
+	if c := r.Collection(); c == nil {
+		t.Error("expected a collection but got nil")
+	} else if c.CRS == nil || c.CRS.Name == nil {
+		t.Errorf("expected a named CRS but got %#v", c.CRS)
+	} else if c.BoundingBox == nil || len(*c.BoundingBox) != 4 {
+		t.Errorf("expected a bbox but got %#v", c.BoundingBox)
+	}
+
+	// Success reading a lone Feature whose type comes last
+	r = newGeoJSONReader(strings.NewReader(`{"geometry": {"coordinates": [1, 2], "type": "Point"}, "properties": null, "type": "Feature"}`))
+	if g, err := r.Read(); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Feature == nil || g.Feature.Geometry.Point == nil {
+		t.Errorf("expected a Feature but got %#v", g)
+	} else if r.Collection() != nil {
+		t.Errorf("expected nil but got %#v", r.Collection())
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expected '%v' but got '%v'", io.EOF, err)
+	}
+
+	// Fail on a truncated collection
+	r = newGeoJSONReader(strings.NewReader(`{"type": "FeatureCollection", "features": [{"type": "Feature"`))
+	if _, err := r.Read(); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestForeignMembers(t *testing.T) {
+	// Success keeping foreign members through GeoJSON and NDJSON
+	input := `{"type":"FeatureCollection","features":[{"type":"Feature","title":"a","geometry":null,"properties":{},"extra":{"b":[1,2]}}]}`
+	expected := `{"type":"Feature","geometry":null,"properties":{},"title":"a","extra":{"b":[1,2]}}` + "\n"
+	stdout, stderr, status := runCommand(input, "convert", "-to", "ndjson")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	expected = "{\"type\":\"FeatureCollection\",\"features\":[\n" + strings.TrimSuffix(expected, "\n") + "\n]}\n"
+	if stdout, stderr, status := runCommand(stdout, "convert", "-from", "ndjson"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success keeping the foreign members of a lone Feature
+	input = `{"type":"Feature","geometry":null,"properties":null,"title":"a"}`
+	if stdout, stderr, status := runCommand(input, "convert"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != input+"\n" {
+		t.Errorf("expected %q but got %q", input+"\n", stdout)
+	}
+}
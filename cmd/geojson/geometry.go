@@ -0,0 +1,226 @@
+package main
+
+import (
+	"math"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// boundingBox returns a pointer to bb, or nil when bb is nil
+func boundingBox(bb geojson.BoundingBox) *geojson.BoundingBox {
+	if bb == nil {
+		return nil
+	}
+	return &bb
+}
+
+// box is an axis aligned rectangle
+type box struct {
+	minX, minY, maxX, maxY float64
+}
+
+func (b box) contains(p geojson.Position) bool {
+	return len(p) >= 2 && p[0] >= b.minX && p[0] <= b.maxX && p[1] >= b.minY && p[1] <= b.maxY
+}
+
+// within reports whether every position of g is inside the box. It's false for
+// a nil or empty geometry.
+func (b box) within(g *geojson.Geometry) bool {
+	n := 0
+	for p := range geojson.AllPositions(geojson.GeoJSON{Geometry: g}) {
+		if len(*p) == 0 {
+			continue
+		}
+		if !b.contains(*p) {
+			return false
+		}
+		n++
+	}
+	return n > 0
+}
+
+// intersects reports whether g and the box have at least one point in common
+func (b box) intersects(g *geojson.Geometry) bool {
+	switch {
+	case g == nil:
+		return false
+	case g.Point != nil:
+		return b.contains(g.Point.Coordinates)
+	case g.MultiPoint != nil:
+		for _, p := range g.MultiPoint.Coordinates {
+			if b.contains(p) {
+				return true
+			}
+		}
+	case g.LineString != nil:
+		return b.crosses(g.LineString.Coordinates)
+	case g.MultiLineString != nil:
+		for _, l := range g.MultiLineString.Coordinates {
+			if b.crosses(l) {
+				return true
+			}
+		}
+	case g.Polygon != nil:
+		return b.overlaps(g.Polygon.Coordinates)
+	case g.MultiPolygon != nil:
+		for _, p := range g.MultiPolygon.Coordinates {
+			if b.overlaps(p) {
+				return true
+			}
+		}
+	case g.GeometryCollection != nil:
+		for i := range g.GeometryCollection.Geometries {
+			if b.intersects(&g.GeometryCollection.Geometries[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// crosses reports whether any segment of the line touches the box
+func (b box) crosses(l geojson.Positions) bool {
+	if len(l) == 1 {
+		return b.contains(l[0])
+	}
+	for i := 1; i < len(l); i++ {
+		if b.clips(l[i-1], l[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether the polygon of rings and the box intersect: either
+// a ring touches the box or the box is inside the polygon
+func (b box) overlaps(rings []geojson.Positions) bool {
+	for _, r := range rings {
+		if b.crosses(r) {
+			return true
+		}
+	}
+	return (&geojson.Polygon{Coordinates: rings}).ContainsPosition(geojson.Position{b.minX, b.minY})
+}
+
+// clips reports whether the segment from p to q touches the box
+func (b box) clips(p, q geojson.Position) bool {
+	_, _, ok := b.clip(p, q)
+	return ok
+}
+
+// clip returns the fractions of the segment from p to q where it enters and
+// leaves the box using the Liang-Barsky algorithm. ok is false when the
+// segment doesn't touch the box.
+func (b box) clip(p, q geojson.Position) (t0, t1 float64, ok bool) {
+	if len(p) < 2 || len(q) < 2 {
+		return 0, 0, false
+	}
+
+	t0, t1 = 0, 1
+	dx, dy := q[0]-p[0], q[1]-p[1]
+	edges := [4][2]float64{
+		{-dx, p[0] - b.minX},
+		{dx, b.maxX - p[0]},
+		{-dy, p[1] - b.minY},
+		{dy, b.maxY - p[1]},
+	}
+	for _, e := range edges {
+		d, n := e[0], e[1]
+		switch {
+		case d == 0:
+			if n < 0 {
+				return 0, 0, false
+			}
+		case d < 0:
+			t0 = max(t0, n/d)
+		default:
+			t1 = min(t1, n/d)
+		}
+	}
+	return t0, t1, t0 <= t1
+}
+
+// geometryEqual reports whether a and b are the same type with the same
+// structure and every coordinate differs by at most tolerance
+func geometryEqual(a, b *geojson.Geometry, tolerance float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if va, vb := a.Value(), b.Value(); va == nil || vb == nil {
+		return va == vb
+	} else if va.GeoJSONType() != vb.GeoJSONType() {
+		return false
+	}
+
+	switch {
+	case a.Point != nil:
+		return positionEqual(a.Point.Coordinates, b.Point.Coordinates, tolerance)
+	case a.MultiPoint != nil:
+		return positionsEqual(a.MultiPoint.Coordinates, b.MultiPoint.Coordinates, tolerance)
+	case a.LineString != nil:
+		return positionsEqual(a.LineString.Coordinates, b.LineString.Coordinates, tolerance)
+	case a.MultiLineString != nil:
+		return ringsEqual(a.MultiLineString.Coordinates, b.MultiLineString.Coordinates, tolerance)
+	case a.Polygon != nil:
+		return ringsEqual(a.Polygon.Coordinates, b.Polygon.Coordinates, tolerance)
+	case a.MultiPolygon != nil:
+		pa, pb := a.MultiPolygon.Coordinates, b.MultiPolygon.Coordinates
+		if len(pa) != len(pb) {
+			return false
+		}
+		for i := range pa {
+			if !ringsEqual(pa[i], pb[i], tolerance) {
+				return false
+			}
+		}
+		return true
+	case a.GeometryCollection != nil:
+		ga, gb := a.GeometryCollection.Geometries, b.GeometryCollection.Geometries
+		if len(ga) != len(gb) {
+			return false
+		}
+		for i := range ga {
+			if !geometryEqual(&ga[i], &gb[i], tolerance) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+func ringsEqual(a, b []geojson.Positions, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !positionsEqual(a[i], b[i], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func positionsEqual(a, b geojson.Positions, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !positionEqual(a[i], b[i], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func positionEqual(a, b geojson.Position, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"io"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// geoprocess reads input, wraps its reader with wrap and writes the result to
+// output. The output format defaults to the input format unless the output
+// extension selects another.
+func geoprocess(e *env, from, input, output string, wrap func(r reader) reader) error {
+	inFormat, err := lookupFormat(from, input)
+	if err != nil {
+		return err
+	}
+	outFormat := inFormat
+	if output != "" {
+		if outFormat, err = lookupFormat("", output); err != nil {
+			return err
+		}
+	}
+
+	in, err := openInput(e, input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := createOutput(e, output)
+	if err != nil {
+		return err
+	}
+
+	if err := transcode(wrap(inFormat.newReader(in)), outFormat, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// geometryReader replaces the geometry of every object of r with the result
+// of fn, keeping the ID, properties and foreign members. Objects whose
+// geometry fn returns nil for are dropped. Bounding boxes present in the input
+// are recomputed.
+type geometryReader struct {
+	r  reader
+	fn func(g *geojson.Geometry) (*geojson.Geometry, error)
+	// dropNull drops the features without a geometry rather than keeping them
+	dropNull bool
+	// crs replaces the CRS of the objects when setCRS is set, nil removing it
+	crs    *geojson.CRS
+	setCRS bool
+
+	coll   *geojson.FeatureCollection
+	bounds geojson.BoundingBox
+}
+
+func (r *geometryReader) Read() (*geojson.GeoJSON, error) {
+	for {
+		g, err := r.r.Read()
+		if err == io.EOF {
+			r.finish()
+			return nil, err
+		} else if err != nil {
+			return nil, err
+		}
+
+		geometry := asGeometry(g)
+		if geometry == nil && r.dropNull {
+			continue
+		}
+		var bb geojson.BoundingBox
+		if geometry != nil {
+			if geometry, err = r.fn(geometry); err != nil {
+				return nil, err
+			}
+			if geometry == nil {
+				continue
+			}
+			if v := geometry.Value(); v != nil {
+				bb = v.Bounds()
+			}
+			if geometry.BoundingBox != nil {
+				geometry.BoundingBox = boundingBox(bb)
+			}
+			r.bounds = r.bounds.Union(bb)
+		}
+
+		if g.Feature == nil {
+			if r.setCRS {
+				geometry.CRS = r.crs
+			}
+			return geometryObject(geometry), nil
+		}
+
+		f := g.Feature
+		f.Geometry = geometry
+		if f.BoundingBox != nil {
+			f.BoundingBox = boundingBox(bb)
+		}
+		// the CRS of a collection applies to its features
+		if r.setCRS && (f.CRS != nil || r.r.Collection() == nil) {
+			f.CRS = r.crs
+		}
+		return featureObject(f), nil
+	}
+}
+
+func (r *geometryReader) Collection() *geojson.FeatureCollection {
+	c := r.r.Collection()
+	if c == nil {
+		return nil
+	}
+
+	if r.coll == nil {
+		coll := *c
+		r.coll = &coll
+		// known once every geometry is read
+		r.coll.BoundingBox = nil
+		if r.setCRS {
+			r.coll.CRS = r.crs
+		}
+	}
+	return r.coll
+}
+
+// finish fills in the collection members that follow the features
+func (r *geometryReader) finish() {
+	coll := r.Collection()
+	if coll == nil {
+		return
+	}
+
+	c := r.r.Collection()
+	if c.BoundingBox != nil {
+		coll.BoundingBox = boundingBox(r.bounds)
+	}
+	if !r.setCRS {
+		coll.CRS = c.CRS
+	}
+}
+
+func (r *geometryReader) Foreign() []member {
+	return foreignOf(r.r)
+}
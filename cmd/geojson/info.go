@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// Limits on what info keeps in memory for each property
+const (
+	// maxDistinct is the number of distinct values counted per property
+	maxDistinct = 1000
+	// maxSamples is the number of distinct values shown per property
+	maxSamples = 3
+)
+
+var infoCommand = &command{
+	name:    "info",
+	usage:   "info [-from format] [-json] [input]",
+	summary: "print statistics about a dataset",
+	run:     runInfo,
+}
+
+// datasetInfo are the statistics info reports
+type datasetInfo struct {
+	// Type is the type of the top level object
+	Type string `json:"type"`
+	// Features is the number of features, 0 for a lone Geometry
+	Features int `json:"features"`
+	// Geometries is the number of geometries of each type, "null" counting
+	// features without one
+	Geometries map[string]int `json:"geometries"`
+	// Vertices are the position counts of the objects
+	Vertices vertexInfo `json:"vertices"`
+	// BoundingBox is computed from the positions of every geometry
+	BoundingBox *geojson.BoundingBox `json:"bbox,omitempty"`
+	// CRS is the name or link of the CRS of the top level object
+	CRS string `json:"crs,omitempty"`
+	// Properties is the inferred schema of the feature properties in order
+	// of appearance
+	Properties []*propertyInfo `json:"properties"`
+
+	// objects counts the objects read and first is the first of them
+	objects    int
+	first      *geojson.GeoJSON
+	properties map[string]*propertyInfo
+}
+
+// vertexInfo summarises the number of positions per object
+type vertexInfo struct {
+	Total int     `json:"total"`
+	Min   int     `json:"min"`
+	Max   int     `json:"max"`
+	Mean  float64 `json:"mean"`
+}
+
+// propertyInfo is the inferred schema of a single property
+type propertyInfo struct {
+	// Name is the property key
+	Name string `json:"name"`
+	// Types are the JSON types seen for the property excluding null. Whole
+	// numbers are reported as integer unless other numbers are seen.
+	Types []string `json:"types"`
+	// Present is the number of features with the property, null or not
+	Present int `json:"present"`
+	// Nulls is the number of features with the property null
+	Nulls int `json:"nulls"`
+	// Missing is the number of features without the property
+	Missing int `json:"missing"`
+	// NullRate is Nulls and Missing as a fraction of all features
+	NullRate float64 `json:"nullRate"`
+	// Distinct is the number of distinct non-null values, capped at
+	// maxDistinct in which case DistinctCapped is set
+	Distinct       int  `json:"distinct"`
+	DistinctCapped bool `json:"distinctCapped,omitempty"`
+	// Samples are the first few distinct values
+	Samples []interface{} `json:"samples"`
+
+	types    map[string]bool
+	distinct map[string]bool
+}
+
+func runInfo(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("too many arguments")
+	}
+
+	input := ""
+	if len(args) == 1 {
+		input = args[0]
+	}
+
+	f, err := lookupFormat(*from, input)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(e, input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := collectInfo(f.newReader(in))
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.stdout, "%s\n", b)
+		return err
+	}
+	return info.print(e.stdout)
+}
+
+// collectInfo reads every object of r and gathers its statistics
+func collectInfo(r reader) (*datasetInfo, error) {
+	info := &datasetInfo{
+		Geometries: make(map[string]int),
+		Properties: []*propertyInfo{},
+		properties: make(map[string]*propertyInfo),
+	}
+	var b geojson.BoundingBox
+
+	for {
+		g, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if info.first == nil {
+			info.first = g
+		}
+
+		geometry := asGeometry(g)
+		if v := geometry.Value(); v != nil {
+			info.Geometries[v.GeoJSONType()]++
+			b = b.Union(v.Bounds())
+		} else {
+			info.Geometries["null"]++
+		}
+
+		n := 0
+		for p := range geojson.AllPositions(geojson.GeoJSON{Geometry: geometry}) {
+			if len(*p) > 0 {
+				n++
+			}
+		}
+		info.addVertices(n)
+
+		if g.Feature != nil {
+			info.Features++
+			info.addProperties(g.Feature.Properties)
+		}
+	}
+
+	if o := info.object(r); o != nil {
+		info.Type = o.Type
+		if o.CRS != nil {
+			info.CRS = crsName(o.CRS)
+		}
+	}
+	info.BoundingBox = boundingBox(b)
+	info.finish()
+
+	return info, nil
+}
+
+// object returns the common members of the top level object, nil for an empty
+// input
+func (info *datasetInfo) object(r reader) *geojson.Object {
+	switch {
+	case r.Collection() != nil:
+		o := r.Collection().Object
+		o.Type = "FeatureCollection"
+		return &o
+	case info.first == nil:
+		return nil
+	case info.first.Feature != nil:
+		o := info.first.Feature.Object
+		o.Type = "Feature"
+		return &o
+	}
+	o := info.first.Geometry.Object
+	o.Type = info.first.Geometry.Value().GeoJSONType()
+	return &o
+}
+
+func (info *datasetInfo) addVertices(n int) {
+	v := &info.Vertices
+	if info.objects == 0 {
+		v.Min = n
+	}
+	info.objects++
+	v.Total += n
+	v.Min = min(v.Min, n)
+	v.Max = max(v.Max, n)
+}
+
+func (info *datasetInfo) addProperties(props geojson.Properties) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := info.properties[k]
+		if p == nil {
+			p = &propertyInfo{
+				Name:     k,
+				Samples:  []interface{}{},
+				types:    make(map[string]bool),
+				distinct: make(map[string]bool),
+			}
+			info.properties[k] = p
+			info.Properties = append(info.Properties, p)
+		}
+		p.add(props[k])
+	}
+}
+
+func (p *propertyInfo) add(v interface{}) {
+	p.Present++
+	if v == nil {
+		p.Nulls++
+		return
+	}
+	p.types[jsonType(v)] = true
+
+	if len(p.distinct) >= maxDistinct {
+		p.DistinctCapped = true
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if k := string(b); !p.distinct[k] {
+		p.distinct[k] = true
+		if len(p.Samples) < maxSamples {
+			p.Samples = append(p.Samples, v)
+		}
+	}
+}
+
+// finish computes the values that depend on every feature having been seen
+func (info *datasetInfo) finish() {
+	if info.objects > 0 {
+		info.Vertices.Mean = float64(info.Vertices.Total) / float64(info.objects)
+	}
+
+	for _, p := range info.Properties {
+		p.Missing = info.Features - p.Present
+		if info.Features > 0 {
+			p.NullRate = float64(p.Nulls+p.Missing) / float64(info.Features)
+		}
+		p.Distinct = len(p.distinct)
+
+		if p.types["integer"] && p.types["number"] {
+			delete(p.types, "integer")
+		}
+		p.Types = make([]string, 0, len(p.types))
+		for t := range p.types {
+			p.Types = append(p.Types, t)
+		}
+		sort.Strings(p.Types)
+	}
+}
+
+// jsonType returns the JSON type name of a decoded value
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func crsName(c *geojson.CRS) string {
+	switch {
+	case c.Name != nil:
+		return c.Name.Name
+	case c.Link != nil:
+		return c.Link.Href
+	}
+	return ""
+}
+
+func (info *datasetInfo) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "type:\t%s\n", info.Type)
+	fmt.Fprintf(tw, "features:\t%d\n", info.Features)
+	if info.CRS != "" {
+		fmt.Fprintf(tw, "crs:\t%s\n", info.CRS)
+	}
+	if info.BoundingBox != nil {
+		fmt.Fprintf(tw, "bbox:\t%s\n", formatNumbers(*info.BoundingBox))
+	}
+	v := info.Vertices
+	fmt.Fprintf(tw, "vertices:\t%d (min %d, max %d, mean %.2f)\n", v.Total, v.Min, v.Max, v.Mean)
+	tw.Flush()
+
+	fmt.Fprintln(w, "geometries:")
+	types := make([]string, 0, len(info.Geometries))
+	for t := range info.Geometries {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Fprintf(tw, "  %s\t%d\n", t, info.Geometries[t])
+	}
+	tw.Flush()
+
+	if len(info.Properties) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "properties:")
+	fmt.Fprintln(tw, "  NAME\tTYPE\tNULLS\tMISSING\tDISTINCT\tSAMPLES")
+	for _, p := range info.Properties {
+		distinct := strconv.Itoa(p.Distinct)
+		if p.DistinctCapped {
+			distinct += "+"
+		}
+
+		samples := make([]string, 0, len(p.Samples))
+		for _, s := range p.Samples {
+			b, _ := json.Marshal(s)
+			samples = append(samples, string(b))
+		}
+
+		types := strings.Join(p.Types, "|")
+		if types == "" {
+			types = "null"
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\t%s\t%s\n", p.Name, types, p.Nulls, p.Missing, distinct, strings.Join(samples, ", "))
+	}
+	return tw.Flush()
+}
+
+func formatNumbers(v []float64) string {
+	s := make([]string, len(v))
+	for i, f := range v {
+		s[i] = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	// Success gathering statistics as JSON
+	stdout, stderr, status := runCommand(testCollection, "info", "-json")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+		return
+	}
+
+	var info datasetInfo
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	if info.Type != "FeatureCollection" {
+		t.Errorf("expected %q but got %q", "FeatureCollection", info.Type)
+	}
+	if info.Features != 3 {
+		t.Errorf("expected 3 features but got %v", info.Features)
+	}
+	if expected := map[string]int{"Point": 1, "LineString": 1, "Polygon": 1}; !reflect.DeepEqual(info.Geometries, expected) {
+		t.Errorf("expected %v but got %v", expected, info.Geometries)
+	}
+	if expected := (vertexInfo{Total: 10, Min: 1, Max: 5, Mean: 10.0 / 3}); info.Vertices != expected {
+		t.Errorf("expected %v but got %v", expected, info.Vertices)
+	}
+	if info.BoundingBox == nil || !reflect.DeepEqual([]float64(*info.BoundingBox), []float64{100, 0, 105, 1}) {
+		t.Errorf("expected bbox [100 0 105 1] but got %v", info.BoundingBox)
+	}
+
+	if len(info.Properties) != 2 {
+		t.Errorf("expected 2 properties but got %v", len(info.Properties))
+		return
+	}
+	p := info.Properties[1]
+	if p.Name != "prop1" {
+		t.Errorf("expected %q but got %q", "prop1", p.Name)
+	}
+	if expected := []string{"integer", "object"}; !reflect.DeepEqual(p.Types, expected) {
+		t.Errorf("expected %v but got %v", expected, p.Types)
+	}
+	if p.Nulls != 0 || p.Missing != 1 || p.Distinct != 2 || len(p.Samples) != 2 {
+		t.Errorf("expected 1 missing & 2 distinct values but got %#v", p)
+	}
+
+	// Success counting null properties apart from missing ones
+	stdout, stderr, status = runCommand(`{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":null,"properties":{"tags":null}},
+{"type":"Feature","geometry":null,"properties":{"tags":"a"}},
+{"type":"Feature","geometry":null,"properties":{}}
+]}`, "info", "-json")
+	info = datasetInfo{}
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if len(info.Properties) != 1 {
+		t.Errorf("expected 1 property but got %v", len(info.Properties))
+	} else if p := info.Properties[0]; p.Present != 2 || p.Nulls != 1 || p.Missing != 1 || p.NullRate != 2.0/3 {
+		t.Errorf("expected 2 present, 1 null & 1 missing but got %#v", p)
+	}
+
+	// Success printing a lone geometry
+	stdout, stderr, status = runCommand("POINT Z (1 2 3)", "info", "-from", "wkt")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if !strings.Contains(stdout, "type:      Point\n") || !strings.Contains(stdout, "bbox:      [1, 2, 3, 1, 2, 3]\n") {
+		t.Errorf("expected Point statistics but got %q", stdout)
+	}
+}
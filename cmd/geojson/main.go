@@ -0,0 +1,221 @@
+// Command geojson converts, inspects and processes GeoJSON files.
+//
+// Usage:
+//
+//	geojson <command> [arguments]
+//
+// Run "geojson help" for the list of commands and "geojson help <command>"
+// for the arguments of a command. Inputs are read from standard input when no
+// file, or "-", is given and outputs are written to standard output unless -o
+// is given.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is a geojson subcommand
+type command struct {
+	// name is the name used to invoke the command
+	name string
+	// usage is the one line synopsis of the arguments
+	usage string
+	// summary is the short description shown by help
+	summary string
+	// run executes the command with its arguments
+	run func(c *command, e *env, args []string) error
+}
+
+// env holds the standard streams a command runs with
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// usageError is returned by commands for invalid arguments. The command usage
+// is printed along with the error.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// flagError is returned by parseFlags for invalid flags, which the FlagSet
+// has already printed along with the command usage
+type flagError struct {
+	error
+}
+
+// commands is the list of all subcommands in the order help lists them
+var commands = []*command{
+	convertCommand,
+	infoCommand,
+	fmtCommand,
+	filterCommand,
+	diffCommand,
+	simplifyCommand,
+	clipCommand,
+	reprojectCommand,
+	mergeCommand,
+	splitCommand,
+	serveCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run executes the command named by the first argument and returns the exit
+// status
+func run(args []string, e *env) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" || args[0] == "-help" {
+		if len(args) > 1 {
+			if c := lookup(args[1]); c != nil {
+				c.printUsage(e)
+				return 0
+			}
+		}
+		usage(e.stdout)
+		return 0
+	}
+
+	c := lookup(args[0])
+	if c == nil {
+		fmt.Fprintf(e.stderr, "geojson: unknown command %q\n", args[0])
+		usage(e.stderr)
+		return 2
+	}
+
+	err := c.run(c, e, args[1:])
+	var ue usageError
+	var fe flagError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &fe):
+		return 2
+	case errors.As(err, &ue):
+		fmt.Fprintf(e.stderr, "geojson %s: %v\n", c.name, err)
+		c.printUsage(e)
+		return 2
+	}
+
+	fmt.Fprintf(e.stderr, "geojson %s: %v\n", c.name, err)
+	return 1
+}
+
+func lookup(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: geojson <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "geojson help <command>" for more information about a command.`)
+}
+
+// flags returns a new FlagSet for the command which prints the command usage
+func (c *command) flags(e *env) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: geojson %s\n\n%s\n", c.usage, capitalize(c.summary))
+		if hasFlags(fs) {
+			fmt.Fprintln(e.stderr)
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// printUsage prints the command usage along with its flags. The flags are only
+// defined by run so it's asked for help.
+func (c *command) printUsage(e *env) {
+	c.run(c, e, []string{"-h"})
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	n := 0
+	fs.VisitAll(func(*flag.Flag) { n++ })
+	return n > 0
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+// parseFlags parses args allowing flags to be interspersed with positional
+// arguments and returns the positional arguments. Everything after "--" is
+// positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		} else if err != nil {
+			return nil, flagError{err}
+		}
+
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// openInput opens the named file or returns standard input for "" or "-"
+func openInput(e *env, name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(e.stdin), nil
+	}
+	return os.Open(name)
+}
+
+// createOutput creates the named file or returns standard output for "" or
+// "-"
+func createOutput(e *env, name string) (io.WriteCloser, error) {
+	if name == "" || name == "-" {
+		return nopWriteCloser{e.stdout}, nil
+	}
+	return os.Create(name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
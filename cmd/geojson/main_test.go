@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runCommand runs the geojson command with stdin as its input and returns
+// what it wrote to stdout & stderr along with the exit status
+func runCommand(stdin string, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, &env{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+	})
+	return stdout.String(), stderr.String(), status
+}
+
+func TestRun(t *testing.T) {
+	// Success listing the commands
+	if stdout, _, status := runCommand("", "help"); status != 0 {
+		t.Errorf("expected status 0 but got %v", status)
+	} else if !strings.Contains(stdout, "convert") {
+		t.Errorf("expected the command list but got %q", stdout)
+	}
+
+	// Success showing a command's flags
+	if _, stderr, status := runCommand("", "help", "convert"); status != 0 {
+		t.Errorf("expected status 0 but got %v", status)
+	} else if !strings.Contains(stderr, "-from") {
+		t.Errorf("expected the convert flags but got %q", stderr)
+	}
+
+	// Fail without a command
+	if _, _, status := runCommand(""); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+
+	// Fail with an unknown flag, printing the error and usage once
+	if _, stderr, status := runCommand("", "convert", "-nope"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	} else if strings.Count(stderr, "-nope") != 1 || strings.Count(stderr, "usage:") != 1 {
+		t.Errorf("expected the error and usage once but got %q", stderr)
+	}
+
+	// Fail with an unknown command
+	if _, stderr, status := runCommand("", "frobnicate"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	} else if !strings.Contains(stderr, `unknown command "frobnicate"`) {
+		t.Errorf("expected an unknown command error but got %q", stderr)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	key := fs.String("key", "", "")
+
+	// Success with interspersed flags
+	args, err := parseFlags(fs, []string{"a", "-key", "id", "b"})
+	if err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if !reflect.DeepEqual(args, []string{"a", "b"}) {
+		t.Errorf("expected %v but got %v", []string{"a", "b"}, args)
+	} else if *key != "id" {
+		t.Errorf("expected %q but got %q", "id", *key)
+	}
+
+	// Success with everything after -- positional
+	args, err = parseFlags(fs, []string{"a", "--", "-key", "b"})
+	if err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if !reflect.DeepEqual(args, []string{"a", "-key", "b"}) {
+		t.Errorf("expected %v but got %v", []string{"a", "-key", "b"}, args)
+	}
+
+	// Fail on unknown flags
+	fs.SetOutput(&bytes.Buffer{})
+	if _, err := parseFlags(fs, []string{"a", "-nope"}); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
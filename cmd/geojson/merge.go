@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var (
+	// errCRSMismatch happens when merging inputs with different CRSs
+	errCRSMismatch = errors.New("inputs have different CRSs, reproject them first")
+	// errDuplicateID happens when merging features with the same ID using the
+	// error strategy
+	errDuplicateID = errors.New("duplicate feature ID")
+)
+
+// Strategies for features with the same ID in different inputs
+const (
+	// idsKeep leaves the IDs as they are
+	idsKeep = "keep"
+	// idsError fails on the first duplicate
+	idsError = "error"
+	// idsSkip drops the features whose ID was already seen
+	idsSkip = "skip"
+	// idsPrefix prefixes every ID with the input name
+	idsPrefix = "prefix"
+	// idsRenumber replaces every ID with its position in the output
+	idsRenumber = "renumber"
+)
+
+var mergeCommand = &command{
+	name:    "merge",
+	usage:   "merge [-ids strategy] [-from format] [-to format] [-o output] input ...",
+	summary: "combine geometries, features and collections into one collection",
+	run:     runMerge,
+}
+
+// runMerge streams the features of every input, in order, into a single
+// FeatureCollection. The inputs must share the same CRS which the output then
+// has. The output has a bounding box when any input had one.
+func runMerge(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	ids := fs.String("ids", idsKeep, "`strategy` for duplicate IDs: keep, error, skip, prefix (with the input name) or renumber")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from each input extension)", formatNames()))
+	to := fs.String("to", "", fmt.Sprintf("output `format`: %s (default from the output extension)", formatNames()))
+	output := fs.String("o", "", "output `file` (default standard output)")
+
+	inputs, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return usageError("missing inputs")
+	}
+	switch *ids {
+	case idsKeep, idsError, idsSkip, idsPrefix, idsRenumber:
+	default:
+		return usageError(fmt.Sprintf("unknown -ids strategy %q", *ids))
+	}
+
+	outFormat, err := lookupFormat(*to, *output)
+	if err != nil {
+		return err
+	}
+	out, err := createOutput(e, *output)
+	if err != nil {
+		return err
+	}
+
+	r := &mergeReader{
+		e:      e,
+		from:   *from,
+		inputs: inputs,
+		ids:    *ids,
+		seen:   make(map[string]bool),
+		coll:   &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}},
+	}
+	defer r.close()
+
+	if err := transcode(r, outFormat, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// mergeReader reads the inputs one after the other as a single collection
+type mergeReader struct {
+	e      *env
+	from   string
+	inputs []string
+	ids    string
+
+	// next is the index of the next input to open
+	next int
+	name string
+	in   io.ReadCloser
+	r    reader
+	// lone is the last object read from an input without a collection
+	lone *geojson.GeoJSON
+
+	coll    *geojson.FeatureCollection
+	crs     *geojson.CRS
+	hadBBox bool
+	bounds  geojson.BoundingBox
+	seen    map[string]bool
+	// n is the number of features read
+	n int
+}
+
+func (r *mergeReader) Read() (*geojson.GeoJSON, error) {
+	for {
+		if r.r == nil {
+			if r.next == len(r.inputs) {
+				if r.hadBBox {
+					r.coll.BoundingBox = boundingBox(r.bounds)
+				}
+				r.coll.CRS = r.crs
+				return nil, io.EOF
+			}
+			if err := r.open(); err != nil {
+				return nil, err
+			}
+		}
+
+		g, err := r.r.Read()
+		if err == io.EOF {
+			if err := r.finishInput(); err != nil {
+				return nil, fmt.Errorf("%s: %w", r.name, err)
+			}
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.name, err)
+		}
+		if r.r.Collection() == nil {
+			r.lone = g
+		}
+
+		f := asFeature(g)
+		if ok, err := r.setID(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.name, err)
+		} else if !ok {
+			continue
+		}
+		if v := f.Geometry.Value(); v != nil {
+			r.bounds = r.bounds.Union(v.Bounds())
+		}
+		return featureObject(f), nil
+	}
+}
+
+func (r *mergeReader) open() error {
+	r.name = r.inputs[r.next]
+	r.next++
+	r.lone = nil
+
+	f, err := lookupFormat(r.from, r.name)
+	if err != nil {
+		return err
+	}
+	if r.in, err = openInput(r.e, r.name); err != nil {
+		return err
+	}
+	r.r = f.newReader(r.in)
+	return nil
+}
+
+// finishInput closes the current input once its members following the
+// features are known and reconciles its CRS and bounding box with the others
+func (r *mergeReader) finishInput() error {
+	var o geojson.Object
+	if c := r.r.Collection(); c != nil {
+		o = c.Object
+	} else if r.lone != nil && r.lone.Feature != nil {
+		o = r.lone.Feature.Object
+	} else if r.lone != nil && r.lone.Geometry != nil {
+		o = r.lone.Geometry.Object
+	}
+	first := r.next == 1
+
+	r.close()
+
+	r.hadBBox = r.hadBBox || o.BoundingBox != nil
+	switch {
+	case first:
+		r.crs = o.CRS
+	case crsKey(o.CRS) != crsKey(r.crs):
+		return errCRSMismatch
+	}
+	return nil
+}
+
+func (r *mergeReader) close() {
+	if r.in != nil {
+		r.in.Close()
+	}
+	r.in, r.r = nil, nil
+}
+
+// setID applies the ID strategy to f and reports whether f is kept
+func (r *mergeReader) setID(f *geojson.Feature) (bool, error) {
+	r.n++
+
+	switch r.ids {
+	case idsPrefix:
+		if f.ID != nil {
+			id, err := formatValue(f.ID)
+			if err != nil {
+				return false, err
+			}
+			base := strings.TrimSuffix(filepath.Base(r.name), filepath.Ext(r.name))
+			f.ID = base + ":" + id
+		}
+		return true, nil
+	case idsRenumber:
+		f.ID = r.n
+		return true, nil
+	case idsKeep:
+		return true, nil
+	}
+
+	if f.ID == nil {
+		return true, nil
+	}
+	b, err := json.Marshal(value(f.ID))
+	if err != nil {
+		return false, err
+	}
+	if !r.seen[string(b)] {
+		r.seen[string(b)] = true
+		return true, nil
+	}
+	if r.ids == idsError {
+		return false, fmt.Errorf("%w %s", errDuplicateID, b)
+	}
+	return false, nil
+}
+
+// Collection returns the merged collection. Its CRS and bounding box are only
+// known once every input was read.
+func (r *mergeReader) Collection() *geojson.FeatureCollection {
+	return r.coll
+}
+
+func (r *mergeReader) Foreign() []member {
+	if r.r == nil {
+		return nil
+	}
+	return foreignOf(r.r)
+}
+
+// crsKey returns a value identifying the CRS c. No CRS is the same as WGS 84.
+func crsKey(c *geojson.CRS) string {
+	name := crsWGS84
+	if c != nil {
+		name = crsName(c)
+	}
+	if canonical, err := lookupCRS(name); err == nil {
+		return canonical
+	}
+	return name
+}
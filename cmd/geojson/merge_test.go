@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	collection := write("a.geojson", testCollection)
+	point := write("b.geojson", `{"type":"Point","bbox":[1,2,1,2],"coordinates":[1,2]}`)
+	feature := write("c.ndjson", `{"type":"Feature","id":1,"geometry":null,"properties":null,"extra":true}`)
+	mercator := write("d.geojson", `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"EPSG:3857"}},"features":[]}`)
+
+	// Success merging every kind of object and prefixing the IDs
+	expected := "{\"type\":\"FeatureCollection\",\"features\":[\n" +
+		`{"type":"Feature","id":"a:1","geometry":{"type":"Point","coordinates":[102,0.5]},"properties":{"prop0":"value0"}},` + "\n" +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[102,0],[103,1],[104,0],[105,1]]},"properties":{"prop0":"value0","prop1":0}},` + "\n" +
+		`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]]]},"properties":{"prop0":"value0","prop1":{"this":"that"}}},` + "\n" +
+		`{"type":"Feature","geometry":{"type":"Point","bbox":[1,2,1,2],"coordinates":[1,2]},"properties":null},` + "\n" +
+		`{"type":"Feature","id":"c:1","geometry":null,"properties":null,"extra":true}` + "\n" +
+		"],\"bbox\":[1,0,105,2]}\n"
+	if stdout, stderr, status := runCommand("", "merge", "-ids", "prefix", collection, point, feature); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success renumbering the IDs
+	if stdout, stderr, status := runCommand("", "merge", "-ids", "renumber", "-to", "ndjson", feature, feature); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if !strings.Contains(stdout, `"id":2,`) {
+		t.Errorf("expected a second ID but got %q", stdout)
+	}
+
+	// Success skipping duplicates
+	if stdout, stderr, status := runCommand("", "merge", "-ids", "skip", "-to", "ndjson", feature, collection, feature); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if n := strings.Count(stdout, "\n"); n != 3 {
+		t.Errorf("expected 3 features but got %v", n)
+	}
+
+	// Fail on duplicate IDs
+	if _, stderr, status := runCommand("", "merge", "-ids", "error", feature, collection); status != 1 {
+		t.Errorf("expected status 1 but got %v", status)
+	} else if !strings.Contains(stderr, "duplicate feature ID 1") {
+		t.Errorf("expected a duplicate ID error but got %q", stderr)
+	}
+
+	// Fail on different CRSs
+	if _, stderr, status := runCommand("", "merge", collection, mercator); status != 1 {
+		t.Errorf("expected status 1 but got %v", status)
+	} else if !strings.Contains(stderr, errCRSMismatch.Error()) {
+		t.Errorf("expected a CRS error but got %q", stderr)
+	}
+
+	// Fail on an unknown strategy
+	if _, _, status := runCommand("", "merge", "-ids", "other", collection); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
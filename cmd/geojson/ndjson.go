@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// ndjsonReader reads newline delimited GeoJSON, one Feature per line. Lines
+// holding a Geometry are wrapped in a Feature and the features of a
+// FeatureCollection line are returned one at a time. An optional RFC 8142
+// record separator before each line is skipped.
+type ndjsonReader struct {
+	d       *json.Decoder
+	pending []geojson.Feature
+	coll    *geojson.FeatureCollection
+	foreign []member
+}
+
+func newNDJSONReader(r io.Reader) reader {
+	return &ndjsonReader{
+		d:    json.NewDecoder(&rsFilter{r: r}),
+		coll: &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}},
+	}
+}
+
+func (r *ndjsonReader) Read() (*geojson.GeoJSON, error) {
+	for len(r.pending) == 0 {
+		var raw json.RawMessage
+		if err := r.d.Decode(&raw); err != nil {
+			return nil, err
+		}
+		var g geojson.GeoJSON
+		if err := json.Unmarshal(raw, &g); err != nil {
+			return nil, err
+		}
+
+		r.foreign = nil
+		switch {
+		case g.FeatureCollection != nil:
+			r.pending = g.FeatureCollection.Features
+		case g.Feature != nil:
+			foreign, err := readForeign(raw)
+			if err != nil {
+				return nil, err
+			}
+			r.foreign = foreign
+			r.pending = []geojson.Feature{*g.Feature}
+		default:
+			r.pending = []geojson.Feature{*asFeature(&g)}
+		}
+	}
+
+	f := r.pending[0]
+	r.pending = r.pending[1:]
+	return featureObject(&f), nil
+}
+
+func (r *ndjsonReader) Collection() *geojson.FeatureCollection {
+	return r.coll
+}
+
+func (r *ndjsonReader) Foreign() []member {
+	return r.foreign
+}
+
+// rsFilter drops the ASCII record separators of GeoJSON text sequences
+type rsFilter struct {
+	r io.Reader
+}
+
+func (f *rsFilter) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	j := 0
+	for _, c := range p[:n] {
+		if c != 0x1e {
+			p[j] = c
+			j++
+		}
+	}
+	return j, err
+}
+
+// ndjsonWriter writes one Feature per line
+type ndjsonWriter struct {
+	w *bufio.Writer
+}
+
+func newNDJSONWriter(w io.Writer, c *geojson.FeatureCollection) writer {
+	return &ndjsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *ndjsonWriter) Write(g *geojson.GeoJSON) error {
+	return w.WriteForeign(g, nil)
+}
+
+func (w *ndjsonWriter) WriteForeign(g *geojson.GeoJSON, foreign []member) error {
+	b, err := json.Marshal(asFeature(g))
+	if err != nil {
+		return err
+	}
+	w.w.Write(appendForeign(b, foreign))
+	return w.w.WriteByte('\n')
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.w.Flush()
+}
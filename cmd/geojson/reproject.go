@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// errUnsupportedCRS happens when reprojecting from or to a CRS other than
+// WGS 84 and Web Mercator
+var errUnsupportedCRS = errors.New("unsupported CRS, must be EPSG:4326 or EPSG:3857")
+
+// Supported coordinate reference systems
+const (
+	crsWGS84       = "EPSG:4326"
+	crsWebMercator = "EPSG:3857"
+)
+
+const (
+	// crsNameTemplate is the name of an EPSG CRS in a crs member
+	crsNameTemplate = "urn:ogc:def:crs:EPSG::%s"
+)
+
+// crsAliases maps the names of supported CRSs, in upper case, to crsWGS84 or
+// crsWebMercator
+var crsAliases = map[string]string{
+	"EPSG:4326":                     crsWGS84,
+	"CRS84":                         crsWGS84,
+	"OGC:CRS84":                     crsWGS84,
+	"URN:OGC:DEF:CRS:OGC:1.3:CRS84": crsWGS84,
+	"URN:OGC:DEF:CRS:EPSG::4326":    crsWGS84,
+	"EPSG:3857":                     crsWebMercator,
+	"EPSG:900913":                   crsWebMercator,
+	"EPSG:3785":                     crsWebMercator,
+	"EPSG:102100":                   crsWebMercator,
+	"URN:OGC:DEF:CRS:EPSG::3857":    crsWebMercator,
+}
+
+var reprojectCommand = &command{
+	name:    "reproject",
+	usage:   "reproject -to-crs crs [-from-crs crs] [-from format] [-o output] [input]",
+	summary: "transform coordinates between EPSG:4326 and EPSG:3857",
+	run:     runReproject,
+}
+
+func runReproject(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	to := fs.String("to-crs", "", "target `crs`: EPSG:4326 or EPSG:3857")
+	source := fs.String("from-crs", "", "source `crs` (default the crs member of the collection or geometry, or EPSG:4326)")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	output := fs.String("o", "", "output `file` (default standard output)")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("too many arguments")
+	}
+	if *to == "" {
+		return usageError("missing -to-crs")
+	}
+
+	target, err := lookupCRS(*to)
+	if err != nil {
+		return err
+	}
+	if *source != "" {
+		if _, err := lookupCRS(*source); err != nil {
+			return err
+		}
+	}
+
+	input := ""
+	if len(args) == 1 {
+		input = args[0]
+	}
+
+	return geoprocess(e, *from, input, *output, func(r reader) reader {
+		p := &reprojection{source: *source, target: target, r: r}
+		g := &geometryReader{r: r, fn: p.reproject, setCRS: true}
+		// WGS 84 is the default of the specification and needs no member
+		if target != crsWGS84 {
+			g.crs = &geojson.CRS{Name: &geojson.CRSName{Name: fmt.Sprintf(crsNameTemplate, strings.TrimPrefix(target, "EPSG:"))}}
+		}
+		return g
+	})
+}
+
+// lookupCRS returns crsWGS84 or crsWebMercator for the name of a supported CRS
+func lookupCRS(name string) (string, error) {
+	if c, ok := crsAliases[strings.ToUpper(strings.TrimSpace(name))]; ok {
+		return c, nil
+	}
+	return "", fmt.Errorf("%w: %q", errUnsupportedCRS, name)
+}
+
+// reprojection transforms geometries from the source CRS to the target one
+type reprojection struct {
+	// source is the name of the source CRS, empty to use the crs member of the
+	// geometry or of the collection read by r
+	source string
+	target string
+	r      reader
+}
+
+func (p *reprojection) reproject(g *geojson.Geometry) (*geojson.Geometry, error) {
+	source := p.source
+	if source == "" {
+		source = crsWGS84
+		if c := p.r.Collection(); c != nil && c.CRS != nil && c.CRS.Name != nil {
+			source = c.CRS.Name.Name
+		}
+		if g.CRS != nil && g.CRS.Name != nil {
+			source = g.CRS.Name.Name
+		}
+	}
+	source, err := lookupCRS(source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case source == p.target:
+	case p.target == crsWebMercator:
+		geojson.Transform(geojson.GeoJSON{Geometry: g}, geojson.ToWebMercator)
+	default:
+		geojson.Transform(geojson.GeoJSON{Geometry: g}, geojson.FromWebMercator)
+	}
+	return g, nil
+}
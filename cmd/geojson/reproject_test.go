@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReproject(t *testing.T) {
+	// Success setting the crs member of the output
+	stdout, stderr, status := runCommand(testCollection, "reproject", "-to-crs", "EPSG:3857")
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if !strings.HasPrefix(stdout, `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"urn:ogc:def:crs:EPSG::3857"}},`) {
+		t.Errorf("expected a Web Mercator crs but got %q", stdout)
+	}
+
+	// Success removing it when going back using the crs member as source
+	if back, stderr, status := runCommand(stdout, "reproject", "--to-crs", "CRS84"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if strings.Contains(back, `"crs"`) || !strings.Contains(back, `"coordinates":[102,0.49999999999999434]`) {
+		t.Errorf("expected WGS 84 coordinates but got %q", back)
+	}
+
+	// Success with a source CRS overriding the crs member
+	if same, stderr, status := runCommand(testCollection, "reproject", "-from-crs", "EPSG:3857", "-to-crs", "EPSG:3857"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if !strings.Contains(same, `"coordinates":[102,0.5]`) {
+		t.Errorf("expected unchanged coordinates but got %q", same)
+	}
+
+	// Fail on the format flag used for the CRS
+	if _, _, status := runCommand(testCollection, "reproject", "-to", "EPSG:3857"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+
+	// Fail on an unsupported CRS
+	if _, err := lookupCRS("EPSG:27700"); !errors.Is(err, errUnsupportedCRS) {
+		t.Errorf("expected '%v' but got '%v'", errUnsupportedCRS, err)
+	}
+	if _, _, status := runCommand(testCollection, "reproject"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
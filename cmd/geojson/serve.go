@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/ogcapi"
+)
+
+var serveCommand = &command{
+	name:    "serve",
+	usage:   "serve [-addr address] [-from format] input ...",
+	summary: "serve the inputs as an OGC API - Features endpoint",
+	run:     runServe,
+}
+
+// runServe loads every input in memory as a collection named after the file
+// and serves them until interrupted
+func runServe(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	addr := fs.String("addr", "localhost:8080", "`address` to listen on")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from each input extension)", formatNames()))
+
+	inputs, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return usageError("missing inputs")
+	}
+
+	s := ogcapi.NewServer("geojson")
+	for _, name := range inputs {
+		if err := loadCollection(e, s, *from, name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "serving %d collections on http://%s/\n", len(inputs), l.Addr())
+	return http.Serve(l, s)
+}
+
+// loadCollection reads the named input in any format and adds it to s as a
+// collection named after the file
+func loadCollection(e *env, s *ogcapi.Server, from, name string) error {
+	id := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+
+	f, err := lookupFormat(from, name)
+	if err != nil {
+		return err
+	}
+	in, err := openInput(e, name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	fc := &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}}
+	r := f.newReader(in)
+	for {
+		g, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		fc.Features = append(fc.Features, *asFeature(g))
+	}
+
+	src := ogcapi.NewMemorySource(fc)
+	src.Title = id
+	return s.Add(id, src)
+}
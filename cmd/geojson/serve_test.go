@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/losinggeneration/geojson/ogcapi"
+)
+
+func TestLoadCollection(t *testing.T) {
+	dir := t.TempDir()
+	collection := filepath.Join(dir, "test.geojson")
+	if err := os.WriteFile(collection, []byte(testCollection), 0600); err != nil {
+		t.Fatal(err)
+	}
+	points := filepath.Join(dir, "points.wkt")
+	if err := os.WriteFile(points, []byte("POINT (1 2)\nPOINT (3 4)\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := ogcapi.NewServer("test")
+	e := &env{stdin: strings.NewReader(""), stdout: &strings.Builder{}, stderr: &strings.Builder{}}
+	for _, name := range []string{collection, points} {
+		if err := loadCollection(e, s, "", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	// Success serving every input as a collection
+	for path, expected := range map[string]int{
+		"/collections/test/items":   3,
+		"/collections/points/items": 2,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items struct {
+			NumberMatched int
+		}
+		err = json.NewDecoder(resp.Body).Decode(&items)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("expected JSON for %v but got %v", path, err)
+		} else if items.NumberMatched != expected {
+			t.Errorf("expected %v features for %v but got %v", expected, path, items.NumberMatched)
+		}
+	}
+
+	// Fail on duplicate collections
+	if err := loadCollection(e, s, "", collection); !errors.Is(err, ogcapi.ErrDuplicateCollection) {
+		t.Errorf("expected %q but got %v", ogcapi.ErrDuplicateCollection, err)
+	}
+
+	// Fail without inputs
+	if _, _, status := runCommand("", "serve"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var simplifyCommand = &command{
+	name:    "simplify",
+	usage:   "simplify -tolerance t [-from format] [-o output] [input]",
+	summary: "simplify lines and polygons with the Douglas-Peucker algorithm",
+	run:     runSimplify,
+}
+
+func runSimplify(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	tolerance := fs.Float64("tolerance", 0, "maximum distance, in coordinate units, of removed positions from the result")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	output := fs.String("o", "", "output `file` (default standard output)")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("too many arguments")
+	}
+	if !(*tolerance > 0) {
+		return usageError("-tolerance must be positive")
+	}
+
+	input := ""
+	if len(args) == 1 {
+		input = args[0]
+	}
+
+	return geoprocess(e, *from, input, *output, func(r reader) reader {
+		return &geometryReader{r: r, fn: func(g *geojson.Geometry) (*geojson.Geometry, error) {
+			simplifyGeometry(g, *tolerance)
+			return g, nil
+		}}
+	})
+}
+
+// simplifyGeometry simplifies the lines and rings of g in place. Points are
+// left as is and rings keep their original positions if simplifying would
+// leave fewer than four.
+func simplifyGeometry(g *geojson.Geometry, tolerance float64) {
+	rings := func(rs []geojson.Positions) {
+		for i, r := range rs {
+			if s := simplify(r, tolerance); len(s) >= 4 {
+				rs[i] = s
+			}
+		}
+	}
+
+	switch {
+	case g.LineString != nil:
+		g.LineString.Coordinates = simplify(g.LineString.Coordinates, tolerance)
+	case g.MultiLineString != nil:
+		for i, l := range g.MultiLineString.Coordinates {
+			g.MultiLineString.Coordinates[i] = simplify(l, tolerance)
+		}
+	case g.Polygon != nil:
+		rings(g.Polygon.Coordinates)
+	case g.MultiPolygon != nil:
+		for _, p := range g.MultiPolygon.Coordinates {
+			rings(p)
+		}
+	case g.GeometryCollection != nil:
+		for i := range g.GeometryCollection.Geometries {
+			simplifyGeometry(&g.GeometryCollection.Geometries[i], tolerance)
+		}
+	}
+}
+
+// simplify returns the positions of the line kept by the Douglas-Peucker
+// algorithm. The first and last positions are always kept.
+func simplify(line geojson.Positions, tolerance float64) geojson.Positions {
+	if len(line) <= 2 {
+		return line
+	}
+
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+
+	// ranges of positions left to simplify
+	stack := [][2]int{{0, len(line) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		index, farthest := -1, tolerance
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(line[i], line[first], line[last]); d > farthest {
+				index, farthest = i, d
+			}
+		}
+		if index >= 0 {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := make(geojson.Positions, 0, len(line))
+	for i, p := range line {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance returns the planar distance from p to the segment a b
+func segmentDistance(p, a, b geojson.Position) float64 {
+	if len(p) < 2 || len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+
+	x, y := a[0], a[1]
+	dx, dy := b[0]-x, b[1]-y
+	if dx != 0 || dy != 0 {
+		t := ((p[0]-x)*dx + (p[1]-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = b[0], b[1]
+		} else if t > 0 {
+			x, y = x+dx*t, y+dy*t
+		}
+	}
+	return math.Hypot(p[0]-x, p[1]-y)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+func TestSimplify(t *testing.T) {
+	// Success dropping the positions closer than the tolerance
+	line := geojson.Positions{{0, 0}, {1, 0.1}, {2, -0.1}, {3, 5}, {4, 6}, {5, 7}, {10, 10}}
+	expected := geojson.Positions{{0, 0}, {2, -0.1}, {3, 5}, {10, 10}}
+	if s := simplify(line, 0.5); !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %v but got %v", expected, s)
+	}
+
+	// Success keeping rings that would collapse
+	g := &geojson.Geometry{Polygon: &geojson.Polygon{Coordinates: []geojson.Positions{
+		{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}},
+	}}}
+	simplifyGeometry(g, 10)
+	if len(g.Polygon.Coordinates[0]) != 5 {
+		t.Errorf("expected 5 positions but got %v", g.Polygon.Coordinates[0])
+	}
+
+	// Success keeping the ID, properties and foreign members
+	input := `{"type":"Feature","id":"x","bbox":[0,0,4,4],"geometry":{"type":"LineString","coordinates":[[0,0],[1,0.01],[2,0]]},"properties":{"a":1},"foo":true}`
+	expected2 := `{"type":"Feature","bbox":[0,0,2,0],"id":"x","geometry":{"type":"LineString","coordinates":[[0,0],[2,0]]},"properties":{"a":1},"foo":true}` + "\n"
+	if stdout, stderr, status := runCommand(input, "simplify", "-tolerance", "0.1"); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if stdout != expected2 {
+		t.Errorf("expected %q but got %q", expected2, stdout)
+	}
+
+	// Fail without a tolerance
+	if _, _, status := runCommand(input, "simplify"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// Ways of grouping features in split
+const (
+	splitByProperty = "property"
+	splitByCount    = "count"
+	splitByTile     = "tile"
+)
+
+var splitCommand = &command{
+	name:    "split",
+	usage:   "split -by property|count|tile [-key name] [-n count] [-zoom z] [-dir directory] [-from format] [-to format] [input]",
+	summary: "write the features to one file per group",
+	run:     runSplit,
+}
+
+// runSplit streams the features of the input into a collection per group and
+// prints the name of every file written. Groups are named after the property
+// value, the input name followed by a sequence number or the z-x-y of the web
+// map tile of the center of the feature.
+func runSplit(c *command, e *env, args []string) error {
+	fs := c.flags(e)
+	by := fs.String("by", "", "group features by `property`, count or tile")
+	key := fs.String("key", "", "property `name` to group by")
+	count := fs.Int("n", 1000, "number of features per file when grouping by count")
+	zoom := fs.Int("zoom", 8, "`zoom` level of the tiles when grouping by tile")
+	dir := fs.String("dir", ".", "`directory` to write the files to")
+	from := fs.String("from", "", fmt.Sprintf("input `format`: %s (default from the input extension)", formatNames()))
+	to := fs.String("to", "", fmt.Sprintf("output `format`: %s (default the input format)", formatNames()))
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("too many arguments")
+	}
+
+	input := ""
+	if len(args) == 1 {
+		input = args[0]
+	}
+	base := "part"
+	if input != "" && input != "-" {
+		base = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	}
+
+	var group func(f *geojson.Feature, n int) string
+	switch *by {
+	case splitByProperty:
+		if *key == "" {
+			return usageError("-key is required to group by property")
+		}
+		group = func(f *geojson.Feature, n int) string {
+			v, err := formatValue(value(f.Properties[*key]))
+			if err != nil || v == "" {
+				return "null"
+			}
+			return fileName(v)
+		}
+	case splitByCount:
+		if *count <= 0 {
+			return usageError("-n must be positive")
+		}
+		group = func(f *geojson.Feature, n int) string {
+			return fmt.Sprintf("%s-%d", base, n / *count + 1)
+		}
+	case splitByTile:
+		if *zoom < 0 || *zoom > 30 {
+			return usageError("-zoom must be between 0 and 30")
+		}
+		group = func(f *geojson.Feature, n int) string {
+			v := f.Geometry.Value()
+			if v == nil {
+				return "null"
+			}
+			b := v.Bounds()
+			if b == nil {
+				return "null"
+			}
+			d := len(b) / 2
+			x, y := tile((b[0]+b[d])/2, (b[1]+b[d+1])/2, *zoom)
+			return fmt.Sprintf("%d-%d-%d", *zoom, x, y)
+		}
+	default:
+		return usageError("-by must be property, count or tile")
+	}
+
+	inFormat, err := lookupFormat(*from, input)
+	if err != nil {
+		return err
+	}
+	outFormat := inFormat
+	if *to != "" {
+		if outFormat, err = lookupFormat(*to, ""); err != nil {
+			return err
+		}
+	}
+
+	in, err := openInput(e, input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	s := &splitter{
+		e:      e,
+		dir:    *dir,
+		format: outFormat,
+		groups: make(map[string]*splitGroup),
+	}
+	if err := s.split(inFormat.newReader(in), group); err != nil {
+		s.close()
+		return err
+	}
+	return s.close()
+}
+
+// splitter writes features to a file per group
+type splitter struct {
+	e      *env
+	dir    string
+	format *format
+	groups map[string]*splitGroup
+	// coll is the collection of the input
+	coll *geojson.FeatureCollection
+}
+
+// splitGroup is an output file of split
+type splitGroup struct {
+	f      *os.File
+	w      writer
+	coll   *geojson.FeatureCollection
+	bounds geojson.BoundingBox
+}
+
+func (s *splitter) split(r reader, group func(f *geojson.Feature, n int) string) error {
+	for n := 0; ; n++ {
+		g, err := r.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if s.coll == nil {
+			s.coll = r.Collection()
+		}
+
+		f := asFeature(g)
+		gr, err := s.group(group(f, n))
+		if err != nil {
+			return err
+		}
+		if v := f.Geometry.Value(); v != nil {
+			gr.bounds = gr.bounds.Union(v.Bounds())
+		}
+
+		if fw, ok := gr.w.(foreignWriter); ok {
+			err = fw.WriteForeign(featureObject(f), foreignOf(r))
+		} else {
+			err = gr.w.Write(featureObject(f))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// group returns the group of the name, creating its file the first time
+func (s *splitter) group(name string) (*splitGroup, error) {
+	if g := s.groups[name]; g != nil {
+		return g, nil
+	}
+
+	path := filepath.Join(s.dir, name+s.format.exts[0])
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(s.e.stdout, path)
+
+	coll := &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection"}}
+	if s.coll != nil {
+		coll.CRS = s.coll.CRS
+	}
+	g := &splitGroup{f: f, coll: coll}
+	g.w = s.format.newWriter(f, coll)
+	s.groups[name] = g
+	return g, nil
+}
+
+// close finishes every file. Files get a bounding box when the input had one.
+func (s *splitter) close() error {
+	var first error
+	for _, g := range s.groups {
+		if s.coll != nil && s.coll.BoundingBox != nil {
+			g.coll.BoundingBox = boundingBox(g.bounds)
+		}
+		if err := g.w.Close(); err != nil && first == nil {
+			first = err
+		}
+		if err := g.f.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	s.groups = nil
+	return first
+}
+
+// fileName replaces the characters of s that aren't safe in file names
+func fileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// tile returns the x and y of the web map tile containing a longitude and
+// latitude at zoom z
+func tile(lon, lat float64, z int) (int, int) {
+	n := math.Exp2(float64(z))
+	lat = math.Max(-geojson.MaxWebMercatorLatitude, math.Min(geojson.MaxWebMercatorLatitude, lat)) * math.Pi / 180
+
+	x := int(math.Floor((lon + 180) / 360 * n))
+	y := int(math.Floor((1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2 * n))
+
+	clamp := func(v int) int {
+		return max(0, min(int(n)-1, v))
+	}
+	return clamp(x), clamp(y)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+
+	// Success grouping by property value
+	stdout, stderr, status := runCommand(testCollection, "split", "-by", "property", "-key", "prop1", "-dir", dir)
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	}
+	expected := []string{"null.geojson", "0.geojson", "__this___that__.geojson"}
+	for i, name := range strings.Fields(stdout) {
+		if i >= len(expected) || name != filepath.Join(dir, expected[i]) {
+			t.Errorf("expected %v but got %q", expected, stdout)
+			break
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "0.geojson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"prop1":0`) || strings.Count(string(b), `"Feature"`) != 1 {
+		t.Errorf("expected a single feature but got %q", b)
+	}
+
+	// Success grouping by count into another format
+	stdout, stderr, status = runCommand(testCollection, "split", "-by", "count", "-n", "2", "-to", "wkt", "-dir", dir)
+	if status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if expected := filepath.Join(dir, "part-1.wkt") + "\n" + filepath.Join(dir, "part-2.wkt") + "\n"; stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Success grouping by tile
+	if stdout, stderr, status := runCommand(testCollection, "split", "-by", "tile", "-zoom", "2", "-dir", dir); status != 0 {
+		t.Errorf("expected status 0 but got %v: %v", status, stderr)
+	} else if expected := filepath.Join(dir, "2-3-1.geojson") + "\n"; stdout != expected {
+		t.Errorf("expected %q but got %q", expected, stdout)
+	}
+
+	// Fail without a grouping
+	if _, _, status := runCommand(testCollection, "split"); status != 2 {
+		t.Errorf("expected status 2 but got %v", status)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/topojson"
+)
+
+// topojsonObject is the name of the object written to a Topology
+const topojsonObject = "collection"
+
+// topojsonReader reads a whole Topology and returns the features of every
+// object in name order. A Topology holding a single geometry without an ID
+// or properties is read as a lone Geometry.
+type topojsonReader struct {
+	r io.Reader
+
+	started bool
+	lone    *geojson.GeoJSON
+	pending []geojson.Feature
+	coll    *geojson.FeatureCollection
+}
+
+func newTopoJSONReader(r io.Reader) reader {
+	return &topojsonReader{r: r}
+}
+
+func (r *topojsonReader) Read() (*geojson.GeoJSON, error) {
+	if !r.started {
+		r.started = true
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+		if r.lone != nil {
+			return r.lone, nil
+		}
+	}
+
+	if len(r.pending) == 0 {
+		return nil, io.EOF
+	}
+
+	f := r.pending[0]
+	r.pending = r.pending[1:]
+	return featureObject(&f), nil
+}
+
+func (r *topojsonReader) load() error {
+	var t topojson.Topology
+	if err := json.NewDecoder(r.r).Decode(&t); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(t.Objects))
+	for name := range t.Objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 1 {
+		if o := t.Objects[names[0]]; o.Type != "GeometryCollection" && o.Type != "" && o.ID == nil && o.Properties == nil {
+			g, err := t.Geometry(o)
+			if err != nil {
+				return err
+			}
+			r.lone = geometryObject(g)
+			return nil
+		}
+	}
+
+	r.coll = &geojson.FeatureCollection{Object: geojson.Object{Type: "FeatureCollection", BoundingBox: t.BoundingBox}}
+	for _, name := range names {
+		fc, err := t.FeatureCollection(name)
+		if err != nil {
+			return err
+		}
+		r.pending = append(r.pending, fc.Features...)
+	}
+	return nil
+}
+
+func (r *topojsonReader) Collection() *geojson.FeatureCollection {
+	return r.coll
+}
+
+// topojsonWriter buffers every object and writes a Topology on Close
+type topojsonWriter struct {
+	w       io.Writer
+	coll    *geojson.FeatureCollection
+	objects []*geojson.GeoJSON
+}
+
+func newTopoJSONWriter(w io.Writer, c *geojson.FeatureCollection) writer {
+	return &topojsonWriter{w: w, coll: c}
+}
+
+func (w *topojsonWriter) Write(g *geojson.GeoJSON) error {
+	if w.coll == nil && len(w.objects) > 0 {
+		return errMultipleObjects
+	}
+	w.objects = append(w.objects, g)
+	return nil
+}
+
+func (w *topojsonWriter) Close() error {
+	t := topojson.New()
+
+	var err error
+	if w.coll == nil && len(w.objects) == 1 && w.objects[0].Geometry != nil {
+		err = t.AddGeometry(topojsonObject, w.objects[0].Geometry)
+	} else {
+		fc := &geojson.FeatureCollection{Features: make([]geojson.Feature, 0, len(w.objects))}
+		for _, g := range w.objects {
+			fc.Features = append(fc.Features, *asFeature(g))
+		}
+		err = t.AddFeatureCollection(topojsonObject, fc)
+	}
+	if err != nil {
+		return err
+	}
+
+	if w.coll != nil {
+		t.BoundingBox = w.coll.BoundingBox
+	}
+
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/wkb"
+)
+
+// newWKBReader reads back to back WKB geometries
+func newWKBReader(r io.Reader) reader {
+	d := wkb.NewDecoder(r)
+
+	return &lookahead{next: func() (*geojson.Geometry, error) {
+		g := new(geojson.Geometry)
+		if err := d.Decode(g); err != nil {
+			return nil, err
+		}
+		return g, nil
+	}}
+}
+
+// wkbWriter writes back to back WKB geometries, properties are dropped. WKB
+// has no null geometry so features without a geometry are skipped.
+type wkbWriter struct {
+	w *bufio.Writer
+	e *wkb.Encoder
+}
+
+func newWKBWriter(w io.Writer, c *geojson.FeatureCollection) writer {
+	b := bufio.NewWriter(w)
+	return &wkbWriter{w: b, e: wkb.NewEncoder(b)}
+}
+
+func (w *wkbWriter) Write(g *geojson.GeoJSON) error {
+	geometry := asGeometry(g)
+	if geometry == nil {
+		return nil
+	}
+	return w.e.Encode(geometry)
+}
+
+func (w *wkbWriter) Close() error {
+	return w.w.Flush()
+}
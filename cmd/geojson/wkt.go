@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/wkt"
+)
+
+// newWKTReader reads one WKT geometry per line, blank lines are skipped
+func newWKTReader(r io.Reader) reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<30)
+	line := 0
+
+	return &lookahead{next: func() (*geojson.Geometry, error) {
+		for s.Scan() {
+			line++
+			t := strings.TrimSpace(s.Text())
+			if t == "" {
+				continue
+			}
+
+			g := new(geojson.Geometry)
+			if err := wkt.Unmarshal([]byte(t), g); err != nil {
+				return nil, fmt.Errorf("wkt: line %d: %w", line, err)
+			}
+			return g, nil
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}}
+}
+
+// wktWriter writes one WKT geometry per line, properties are dropped. WKT
+// has no null geometry so features without a geometry are skipped.
+type wktWriter struct {
+	w *bufio.Writer
+}
+
+func newWKTWriter(w io.Writer, c *geojson.FeatureCollection) writer {
+	return &wktWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *wktWriter) Write(g *geojson.GeoJSON) error {
+	geometry := asGeometry(g)
+	if geometry == nil {
+		return nil
+	}
+
+	b, err := wkt.Marshal(geometry)
+	if err != nil {
+		return err
+	}
+	w.w.Write(b)
+	return w.w.WriteByte('\n')
+}
+
+func (w *wktWriter) Close() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,297 @@
+// Package cql2 compiles OGC Common Query Language (CQL2) filters, in the text
+// and JSON encodings, to predicates over GeoJSON features.
+//
+// The basic, advanced comparison (LIKE, BETWEEN, IN), basic spatial and
+// temporal conformance classes are supported. Properties are looked up in the
+// Properties of a Feature, except for geometry which is the Geometry of the
+// Feature and id which falls back to its ID.
+//
+// Comparisons involving a null or missing property are unknown, as are those
+// of values of different types, and a Feature only matches when the filter
+// is true.
+package cql2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// ErrInvalidFilter happens when parsing a filter that isn't valid CQL2 or uses
+// features that aren't supported
+var ErrInvalidFilter = errors.New("invalid CQL2 filter")
+
+// Predicate reports whether a Feature matches a filter
+type Predicate func(f *geojson.Feature) bool
+
+// SyntaxError is an error in a CQL2 text filter
+type SyntaxError struct {
+	// Offset is the byte offset in the filter where the error was found
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v: %s at offset %d", ErrInvalidFilter, e.Msg, e.Offset)
+}
+
+// Unwrap returns ErrInvalidFilter
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidFilter
+}
+
+// expr evaluates part of a filter for a Feature. Its result is nil, a bool,
+// float64, string, interval, *geojson.Geometry or []interface{}.
+type expr func(f *geojson.Feature) interface{}
+
+// predicate returns the Predicate of the boolean expression e
+func predicate(e expr) Predicate {
+	return func(f *geojson.Feature) bool {
+		return e(f) == true
+	}
+}
+
+func literal(v interface{}) expr {
+	return func(*geojson.Feature) interface{} {
+		return v
+	}
+}
+
+// property returns the value of the named property of a Feature
+func property(name string) expr {
+	return func(f *geojson.Feature) interface{} {
+		if v, ok := f.Properties[name]; ok {
+			return normalize(v)
+		}
+		switch name {
+		case "geometry":
+			if f.Geometry == nil {
+				return nil
+			}
+			return f.Geometry
+		case "id":
+			return normalize(f.ID)
+		}
+		return nil
+	}
+}
+
+// normalize turns the numbers of decoded JSON into float64
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
+
+// and, or and not follow three valued logic where nil is unknown
+
+func and(a, b expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		x := a(f)
+		if x == false {
+			return false
+		}
+		y := b(f)
+		switch {
+		case y == false:
+			return false
+		case x == true && y == true:
+			return true
+		}
+		return nil
+	}
+}
+
+func or(a, b expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		x := a(f)
+		if x == true {
+			return true
+		}
+		y := b(f)
+		switch {
+		case y == true:
+			return true
+		case x == false && y == false:
+			return false
+		}
+		return nil
+	}
+}
+
+func not(a expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		if b, ok := a(f).(bool); ok {
+			return !b
+		}
+		return nil
+	}
+}
+
+// compare returns the comparison of a and b with one of the operators =, <>,
+// <, <=, > and >=
+func compare(op string, a, b expr) (expr, error) {
+	var test func(c int) bool
+	switch op {
+	case "=":
+		test = func(c int) bool { return c == 0 }
+	case "<>":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return nil, fmt.Errorf("%w: unknown comparison %q", ErrInvalidFilter, op)
+	}
+	ordered := op != "=" && op != "<>"
+
+	return func(f *geojson.Feature) interface{} {
+		c, ok := compareValues(a(f), b(f), ordered)
+		if !ok {
+			return nil
+		}
+		return test(c)
+	}, nil
+}
+
+// compareValues returns -1, 0 or 1 as x is less than, equal to or greater
+// than y, and false when they can't be compared. Booleans can only be
+// compared for equality.
+func compareValues(x, y interface{}, ordered bool) (int, bool) {
+	switch x := x.(type) {
+	case float64:
+		if y, ok := y.(float64); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			return strings.Compare(x, y), true
+		}
+		if y, ok := y.(interval); ok {
+			if x, ok := parseInstant(x); ok {
+				return x.compare(y)
+			}
+		}
+	case bool:
+		if y, ok := y.(bool); ok && !ordered {
+			if x == y {
+				return 0, true
+			}
+			return 1, true
+		}
+	case interval:
+		switch y := y.(type) {
+		case interval:
+			return x.compare(y)
+		case string:
+			if y, ok := parseInstant(y); ok {
+				return x.compare(y)
+			}
+		}
+	}
+	return 0, false
+}
+
+// like matches a string against a pattern where % is any sequence of
+// characters, _ any single character and \ escapes the next character,
+// failing when the pattern ends with an escape
+func like(a expr, pattern string) (expr, error) {
+	var b strings.Builder
+	b.WriteString(`(?s)\A`)
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("%w: like pattern %q ends with an escape", ErrInvalidFilter, pattern)
+	}
+	b.WriteString(`\z`)
+	re := regexp.MustCompile(b.String())
+
+	return func(f *geojson.Feature) interface{} {
+		s, ok := a(f).(string)
+		if !ok {
+			return nil
+		}
+		return re.MatchString(s)
+	}, nil
+}
+
+func between(a, low, high expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		v := a(f)
+		l, ok := compareValues(v, low(f), true)
+		if !ok {
+			return nil
+		}
+		h, ok := compareValues(v, high(f), true)
+		if !ok {
+			return nil
+		}
+		return l >= 0 && h <= 0
+	}
+}
+
+// in reports whether a equals any of the values. It's unknown when none is
+// equal and some couldn't be compared.
+func in(a expr, values []expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		v := a(f)
+		var result interface{} = false
+		for _, e := range values {
+			c, ok := compareValues(v, e(f), false)
+			switch {
+			case !ok:
+				result = nil
+			case c == 0:
+				return true
+			}
+		}
+		return result
+	}
+}
+
+func isNull(a expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		return a(f) == nil
+	}
+}
@@ -0,0 +1,257 @@
+package cql2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// ParseJSON compiles a filter in the CQL2-JSON encoding, such as
+//
+//	{"op": "and", "args": [
+//		{"op": "=", "args": [{"property": "class"}, "road"]},
+//		{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [0, 50, 2, 52]}]}
+//	]}
+func ParseJSON(b []byte) (Predicate, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	e, err := jsonBoolean(v)
+	if err != nil {
+		return nil, err
+	}
+	return predicate(e), nil
+}
+
+// jsonBoolean compiles a boolean expression, an operation or a boolean
+// literal
+func jsonBoolean(v interface{}) (expr, error) {
+	switch v := v.(type) {
+	case bool:
+		return literal(v), nil
+	case map[string]interface{}:
+		if _, ok := v["op"]; ok {
+			return jsonOp(v)
+		}
+	}
+	return nil, fmt.Errorf("%w: expected a boolean expression", ErrInvalidFilter)
+}
+
+// jsonOp compiles an object with op and args members
+func jsonOp(v map[string]interface{}) (expr, error) {
+	op, ok := v["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: op must be a string", ErrInvalidFilter)
+	}
+	args, ok := v["args"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s args must be an array", ErrInvalidFilter, op)
+	}
+	arity := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%w: %s takes %d arguments", ErrInvalidFilter, op, n)
+		}
+		return nil
+	}
+	scalars := func() ([]expr, error) {
+		es := make([]expr, len(args))
+		for i, a := range args {
+			e, err := jsonScalar(a)
+			if err != nil {
+				return nil, err
+			}
+			es[i] = e
+		}
+		return es, nil
+	}
+
+	name := strings.ToLower(op)
+	switch name {
+	case "and", "or":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%w: %s takes at least 2 arguments", ErrInvalidFilter, op)
+		}
+		e, err := jsonBoolean(args[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range args[1:] {
+			r, err := jsonBoolean(a)
+			if err != nil {
+				return nil, err
+			}
+			if name == "and" {
+				e = and(e, r)
+			} else {
+				e = or(e, r)
+			}
+		}
+		return e, nil
+	case "not":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		e, err := jsonBoolean(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return not(e), nil
+	case "=", "<>", "<", "<=", ">", ">=":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		es, err := scalars()
+		if err != nil {
+			return nil, err
+		}
+		return compare(name, es[0], es[1])
+	case "like":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		pattern, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: like pattern must be a string", ErrInvalidFilter)
+		}
+		e, err := jsonScalar(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return like(e, pattern)
+	case "between":
+		if err := arity(3); err != nil {
+			return nil, err
+		}
+		es, err := scalars()
+		if err != nil {
+			return nil, err
+		}
+		return between(es[0], es[1], es[2]), nil
+	case "in":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		list, ok := args[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: in takes an array of values", ErrInvalidFilter)
+		}
+		e, err := jsonScalar(args[0])
+		if err != nil {
+			return nil, err
+		}
+		values := make([]expr, len(list))
+		for i, l := range list {
+			if values[i], err = jsonScalar(l); err != nil {
+				return nil, err
+			}
+		}
+		return in(e, values), nil
+	case "isnull":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		e, err := jsonScalar(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return isNull(e), nil
+	}
+
+	upper := strings.ToUpper(op)
+	_, isSpatial := spatialOperators[upper]
+	_, isTemporal := temporalOperators[upper]
+	if !isSpatial && !isTemporal {
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidFilter, op)
+	}
+	if err := arity(2); err != nil {
+		return nil, err
+	}
+	es, err := scalars()
+	if err != nil {
+		return nil, err
+	}
+	if isSpatial {
+		return spatial(upper, es[0], es[1])
+	}
+	return temporal(upper, es[0], es[1])
+}
+
+// jsonScalar compiles a literal, a property or a temporal or geometry
+// object
+func jsonScalar(v interface{}) (expr, error) {
+	switch v := v.(type) {
+	case nil, string, bool:
+		return literal(v), nil
+	case json.Number:
+		return literal(normalize(v)), nil
+	case map[string]interface{}:
+		return jsonObject(v)
+	}
+	return nil, fmt.Errorf("%w: unexpected %v", ErrInvalidFilter, v)
+}
+
+func jsonObject(v map[string]interface{}) (expr, error) {
+	if _, ok := v["op"]; ok {
+		return jsonOp(v)
+	}
+	if name, ok := v["property"].(string); ok {
+		return property(name), nil
+	}
+	for _, k := range []string{"date", "timestamp"} {
+		if s, ok := v[k].(string); ok {
+			i, err := instant(s)
+			if err != nil {
+				return nil, err
+			}
+			return literal(i), nil
+		}
+	}
+	if bounds, ok := v["interval"].([]interface{}); ok {
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%w: interval must have a start and an end", ErrInvalidFilter)
+		}
+		start, err := jsonScalar(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := jsonScalar(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		return makeInterval(start, end), nil
+	}
+	if bbox, ok := v["bbox"].([]interface{}); ok {
+		nums := make([]float64, len(bbox))
+		for i, n := range bbox {
+			f, ok := normalize(n).(float64)
+			if !ok {
+				return nil, fmt.Errorf("%w: bbox must be numbers", ErrInvalidFilter)
+			}
+			nums[i] = f
+		}
+		g, err := bboxGeometry(nums)
+		if err != nil {
+			return nil, err
+		}
+		return literal(g), nil
+	}
+	if _, ok := v["type"]; ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		var g geojson.Geometry
+		if err := json.Unmarshal(b, &g); err != nil {
+			return nil, fmt.Errorf("%w: invalid geometry: %v", ErrInvalidFilter, err)
+		}
+		return literal(&g), nil
+	}
+	return nil, fmt.Errorf("%w: unknown object", ErrInvalidFilter)
+}
@@ -0,0 +1,57 @@
+package cql2
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	features := testFeatures
+
+	// Success matching the features of every kind of operation
+	for filter, expected := range map[string][]interface{}{
+		`{"op":"=","args":[{"property":"class"},"road"]}`:                                                                           {1.0, 2.0},
+		`{"op":"and","args":[{"op":">=","args":[{"property":"lanes"},2]},{"op":"<","args":[{"property":"lanes"},4]}]}`:              {1.0},
+		`{"op":"or","args":[{"op":"=","args":[{"property":"class"},"landuse"]},{"op":"=","args":[{"property":"lanes"},4]}]}`:        {2.0, 3.0},
+		`{"op":"not","args":[{"op":"isNull","args":[{"property":"lanes"}]}]}`:                                                       {1.0, 2.0},
+		`{"op":"like","args":[{"property":"name"},"%Way"]}`:                                                                         {2.0},
+		`{"op":"between","args":[{"property":"lanes"},3,4]}`:                                                                        {2.0},
+		`{"op":"in","args":[{"property":"class"},["landuse","water"]]}`:                                                             {3.0},
+		`{"op":"s_intersects","args":[{"property":"geometry"},{"bbox":[0,0,10,10]}]}`:                                               {1.0, 2.0},
+		`{"op":"s_within","args":[{"property":"geometry"},{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}]}`: {1.0},
+		`{"op":"t_before","args":[{"property":"built"},{"date":"2010-01-01"}]}`:                                                     {1.0},
+		`{"op":"t_during","args":[{"interval":[{"property":"built"},{"property":"closed"}]},{"interval":["2014-01-01",".."]}]}`:     {2.0},
+		`{"op":">","args":[{"property":"built"},{"timestamp":"2010-01-01T00:00:00Z"}]}`:                                             {2.0},
+		`true`: {1.0, 2.0, 3.0},
+	} {
+		p, err := ParseJSON([]byte(filter))
+		if err != nil {
+			t.Errorf("expected %v to parse but got %v", filter, err)
+			continue
+		}
+		if ids := matching(features, p); !equalIDs(ids, expected) {
+			t.Errorf("expected %v for %v but got %v", expected, filter, ids)
+		}
+	}
+
+	// Fail on invalid filters
+	for _, filter := range []string{
+		``,
+		`"class"`,
+		`{"op":"=","args":[{"property":"class"}]}`,
+		`{"op":"=","args":"class"}`,
+		`{"op":"like","args":[{"property":"name"},{"property":"class"}]}`,
+		`{"op":"like","args":[{"property":"name"},"Park\\"]}`,
+		`{"op":"in","args":[{"property":"class"},"road"]}`,
+		`{"op":"and","args":[true]}`,
+		`{"op":"s_crosses","args":[{"property":"geometry"},{"bbox":[0,0,1,1]}]}`,
+		`{"op":"=","args":[{"date":"yesterday"},1]}`,
+		`{"op":"=","args":[{"unknown":1},1]}`,
+		`{"op":"=","args":[[1],1]}`,
+		`{"op":"s_within","args":[{"property":"geometry"},{"type":"Polygon","coordinates":[[0,0]]}]}`,
+	} {
+		if _, err := ParseJSON([]byte(filter)); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected %q for %v but got %v", ErrInvalidFilter, filter, err)
+		}
+	}
+}
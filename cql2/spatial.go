@@ -0,0 +1,371 @@
+package cql2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// shape is a geometry broken down into what the spatial operators test
+type shape struct {
+	points   []geojson.Position
+	segments [][2]geojson.Position
+	polygons [][]geojson.Positions
+	// lines are the segments of lines rather than rings, and ends the
+	// boundaries of the lines that aren't closed
+	lines [][2]geojson.Position
+	ends  []geojson.Position
+}
+
+// newShape breaks down g and every geometry it's made of
+func newShape(g *geojson.Geometry) *shape {
+	s := &shape{}
+	s.add(g)
+	return s
+}
+
+func (s *shape) add(g *geojson.Geometry) {
+	switch {
+	case g == nil:
+	case g.Point != nil:
+		s.addPoint(g.Point.Coordinates)
+	case g.MultiPoint != nil:
+		for _, p := range g.MultiPoint.Coordinates {
+			s.addPoint(p)
+		}
+	case g.LineString != nil:
+		s.addOpenLine(g.LineString.Coordinates)
+	case g.MultiLineString != nil:
+		for _, l := range g.MultiLineString.Coordinates {
+			s.addOpenLine(l)
+		}
+	case g.Polygon != nil:
+		s.addPolygon(g.Polygon.Coordinates)
+	case g.MultiPolygon != nil:
+		for _, p := range g.MultiPolygon.Coordinates {
+			s.addPolygon(p)
+		}
+	case g.GeometryCollection != nil:
+		for i := range g.GeometryCollection.Geometries {
+			s.add(&g.GeometryCollection.Geometries[i])
+		}
+	}
+}
+
+func (s *shape) addPoint(p geojson.Position) {
+	if len(p) >= 2 {
+		s.points = append(s.points, p)
+	}
+}
+
+func (s *shape) addLine(l geojson.Positions) {
+	if len(l) == 1 {
+		s.addPoint(l[0])
+	}
+	for i := 1; i < len(l); i++ {
+		if len(l[i-1]) >= 2 && len(l[i]) >= 2 {
+			s.segments = append(s.segments, [2]geojson.Position{l[i-1], l[i]})
+		}
+	}
+}
+
+// addOpenLine adds a line that isn't the ring of a polygon
+func (s *shape) addOpenLine(l geojson.Positions) {
+	n := len(s.segments)
+	s.addLine(l)
+	s.lines = append(s.lines, s.segments[n:]...)
+	if first, last := l[0], l[len(l)-1]; len(l) > 1 && (first[0] != last[0] || first[1] != last[1]) {
+		s.ends = append(s.ends, first, last)
+	}
+}
+
+func (s *shape) addPolygon(rings []geojson.Positions) {
+	if len(rings) == 0 {
+		return
+	}
+	s.polygons = append(s.polygons, rings)
+	for _, r := range rings {
+		s.addLine(r)
+	}
+}
+
+// vertices returns the points and the ends of the segments of s
+func (s *shape) vertices() []geojson.Position {
+	v := append([]geojson.Position{}, s.points...)
+	for _, seg := range s.segments {
+		v = append(v, seg[0], seg[1])
+	}
+	return v
+}
+
+func (s *shape) empty() bool {
+	return len(s.points) == 0 && len(s.segments) == 0
+}
+
+// covers reports whether p is one of the points, on a segment or inside a
+// polygon of s
+func (s *shape) covers(p geojson.Position) bool {
+	for _, q := range s.points {
+		if p[0] == q[0] && p[1] == q[1] {
+			return true
+		}
+	}
+	for _, seg := range s.segments {
+		if onSegment(p, seg[0], seg[1]) {
+			return true
+		}
+	}
+	for _, rings := range s.polygons {
+		if (&geojson.Polygon{Coordinates: rings}).ContainsPosition(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersects reports whether s and t share any point
+func (s *shape) intersects(t *shape) bool {
+	for _, p := range s.vertices() {
+		if t.covers(p) {
+			return true
+		}
+	}
+	for _, p := range t.vertices() {
+		if s.covers(p) {
+			return true
+		}
+	}
+	for _, a := range s.segments {
+		for _, b := range t.segments {
+			if segmentsIntersect(a[0], a[1], b[0], b[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// interiorCovers reports whether p is in the interior of s: one of its
+// points, on a line other than at its ends or inside a polygon other than on
+// its rings
+func (s *shape) interiorCovers(p geojson.Position) bool {
+	for _, q := range s.points {
+		if p[0] == q[0] && p[1] == q[1] {
+			return true
+		}
+	}
+	for _, seg := range s.lines {
+		if onSegment(p, seg[0], seg[1]) && !s.isEnd(p) {
+			return true
+		}
+	}
+	for _, rings := range s.polygons {
+		if (&geojson.Polygon{Coordinates: rings}).ContainsPosition(p) && !onRings(p, rings) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnd reports whether p is an end of a line of s
+func (s *shape) isEnd(p geojson.Position) bool {
+	for _, e := range s.ends {
+		if p[0] == e[0] && p[1] == e[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether s is within t as DE-9IM defines it: every point of
+// s is a point of t and the interiors of s and t share a point. Segments of
+// s must not cross the boundaries of t, the rings of t must not be inside s
+// and, when t has no area, the middles of the segments of s must be on t too.
+func (s *shape) within(t *shape) bool {
+	if s.empty() || t.empty() {
+		return false
+	}
+	for _, p := range s.vertices() {
+		if !t.covers(p) {
+			return false
+		}
+	}
+	for _, a := range s.segments {
+		if len(t.polygons) == 0 {
+			m := geojson.Position{(a[0][0] + a[1][0]) / 2, (a[0][1] + a[1][1]) / 2}
+			if !t.covers(m) {
+				return false
+			}
+			continue
+		}
+		for _, b := range t.segments {
+			if segmentsCross(a[0], a[1], b[0], b[1]) {
+				return false
+			}
+		}
+	}
+
+	// a ring of t inside s, such as a hole, leaves part of s outside t
+	for _, rings := range t.polygons {
+		for _, r := range rings {
+			for _, p := range r {
+				if len(p) >= 2 && s.interiorCovers(p) {
+					return false
+				}
+			}
+		}
+	}
+
+	// the points of s inside its interior, all of which t must cover, one
+	// of them in its interior
+	inside := append([]geojson.Position{}, s.points...)
+	for _, a := range s.segments {
+		inside = append(inside, geojson.Position{(a[0][0] + a[1][0]) / 2, (a[0][1] + a[1][1]) / 2})
+	}
+	for _, rings := range s.polygons {
+		if p, ok := interiorPoint(rings); ok {
+			if !t.covers(p) {
+				return false
+			}
+			inside = append(inside, p)
+		}
+	}
+	for _, p := range inside {
+		if t.interiorCovers(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// spatialOperators are the spatial functions with their test
+var spatialOperators = map[string]func(a, b *shape) bool{
+	"S_INTERSECTS": func(a, b *shape) bool {
+		return a.intersects(b)
+	},
+	"S_DISJOINT": func(a, b *shape) bool {
+		return !a.intersects(b)
+	},
+	"S_WITHIN": func(a, b *shape) bool {
+		return a.within(b)
+	},
+	"S_CONTAINS": func(a, b *shape) bool {
+		return b.within(a)
+	},
+}
+
+// spatial returns the spatial operator op applied to the geometries of a and b
+func spatial(op string, a, b expr) (expr, error) {
+	test, ok := spatialOperators[strings.ToUpper(op)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown spatial operator %q", ErrInvalidFilter, op)
+	}
+
+	return func(f *geojson.Feature) interface{} {
+		x, ok := a(f).(*geojson.Geometry)
+		if !ok {
+			return nil
+		}
+		y, ok := b(f).(*geojson.Geometry)
+		if !ok {
+			return nil
+		}
+		return test(newShape(x), newShape(y))
+	}, nil
+}
+
+// bboxGeometry returns the Polygon of a BBOX of 4 or 6 numbers
+func bboxGeometry(v []float64) (*geojson.Geometry, error) {
+	if len(v) != 4 && len(v) != 6 {
+		return nil, fmt.Errorf("%w: BBOX must have 4 or 6 numbers", ErrInvalidFilter)
+	}
+	d := len(v) / 2
+	minX, minY, maxX, maxY := v[0], v[1], v[d], v[d+1]
+
+	o := geojson.Object{Type: "Polygon"}
+	ring := geojson.Positions{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}, {minX, minY}}
+	return &geojson.Geometry{Object: o, Polygon: &geojson.Polygon{Object: o, Coordinates: []geojson.Positions{ring}}}, nil
+}
+
+// onRings reports whether p is on one of the rings
+func onRings(p geojson.Position, rings []geojson.Positions) bool {
+	for _, r := range rings {
+		for i := 1; i < len(r); i++ {
+			if len(r[i-1]) >= 2 && len(r[i]) >= 2 && onSegment(p, r[i-1], r[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// interiorPoint returns a point inside the polygon of rings and not on them,
+// on a horizontal line between the two lowest heights of their positions
+func interiorPoint(rings []geojson.Positions) (geojson.Position, bool) {
+	var ys []float64
+	for _, r := range rings {
+		for _, p := range r {
+			if len(p) >= 2 {
+				ys = append(ys, p[1])
+			}
+		}
+	}
+	sort.Float64s(ys)
+	i := sort.Search(len(ys), func(i int) bool { return ys[i] > ys[0] })
+	if i == len(ys) {
+		return nil, false
+	}
+	y := (ys[0] + ys[i]) / 2
+
+	var xs []float64
+	for _, r := range rings {
+		for i := 1; i < len(r); i++ {
+			a, c := r[i-1], r[i]
+			if len(a) >= 2 && len(c) >= 2 && (a[1] > y) != (c[1] > y) {
+				xs = append(xs, a[0]+(y-a[1])*(c[0]-a[0])/(c[1]-a[1]))
+			}
+		}
+	}
+	if len(xs) < 2 {
+		return nil, false
+	}
+	sort.Float64s(xs)
+	return geojson.Position{(xs[0] + xs[1]) / 2, y}, true
+}
+
+// orientation returns the sign of the cross product of pq and pr
+func orientation(p, q, r geojson.Position) int {
+	v := (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// onSegment reports whether p is on the segment from a to b
+func onSegment(p, a, b geojson.Position) bool {
+	return orientation(a, b, p) == 0 &&
+		p[0] >= min(a[0], b[0]) && p[0] <= max(a[0], b[0]) &&
+		p[1] >= min(a[1], b[1]) && p[1] <= max(a[1], b[1])
+}
+
+// segmentsIntersect reports whether the segments ab and cd share any point
+func segmentsIntersect(a, b, c, d geojson.Position) bool {
+	if segmentsCross(a, b, c, d) {
+		return true
+	}
+	return onSegment(c, a, b) || onSegment(d, a, b) || onSegment(a, c, d) || onSegment(b, c, d)
+}
+
+// segmentsCross reports whether the segments ab and cd cross at a point
+// inside both of them
+func segmentsCross(a, b, c, d geojson.Position) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	return o1*o2 < 0 && o3*o4 < 0
+}
@@ -0,0 +1,77 @@
+package cql2
+
+import (
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/wkt"
+)
+
+func TestShape(t *testing.T) {
+	shape := func(s string) *shape {
+		var g geojson.Geometry
+		if err := wkt.Unmarshal([]byte(s), &g); err != nil {
+			t.Fatal(err)
+		}
+		return newShape(&g)
+	}
+	square := shape("POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))")
+
+	// Success testing shapes against a square with a hole
+	for s, expected := range map[string][2]bool{
+		// intersects, within
+		"POINT (1 1)":                                   {true, true},
+		"POINT (5 5)":                                   {false, false},
+		"POINT (10 5)":                                  {true, false},
+		"POINT (4 5)":                                   {true, false},
+		"LINESTRING (-5 5, 15 5)":                       {true, false},
+		"LINESTRING (1 1, 2 9)":                         {true, true},
+		"LINESTRING (1 5, 9 5)":                         {true, false},
+		"LINESTRING (-1 -1, -1 11)":                     {false, false},
+		"POLYGON ((1 1, 3 1, 3 3, 1 3, 1 1))":           {true, true},
+		"POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))":       {true, false},
+		"POLYGON ((4 4, 6 4, 6 6, 4 6, 4 4))":           {true, false},
+		"POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0))":           {true, true},
+		"LINESTRING (0 0, 10 0)":                        {true, false},
+		"LINESTRING (0 0, 10 0, 10 5)":                  {true, false},
+		"POLYGON ((-5 -5, 15 -5, 15 15, -5 15, -5 -5))": {true, false},
+		"MULTIPOINT ((1 1), (20 20))":                   {true, false},
+		"GEOMETRYCOLLECTION (POINT (1 1), POINT (2 2))": {true, true},
+	} {
+		a := shape(s)
+		if i := a.intersects(square); i != expected[0] {
+			t.Errorf("expected %v intersects to be %v but got %v", s, expected[0], i)
+		}
+		if w := a.within(square); w != expected[1] {
+			t.Errorf("expected %v within to be %v but got %v", s, expected[1], w)
+		}
+	}
+
+	// Success testing a point on the boundary of a square without a hole
+	full := shape("POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))")
+	if edge := shape("POINT (0 5)"); edge.within(full) || !full.within(full) {
+		t.Errorf("expected a point on the boundary not to be within but the square to be within itself")
+	}
+
+	// Success testing lines and points within lines and points
+	line := shape("LINESTRING (0 0, 10 0)")
+	for s, expected := range map[string]bool{
+		"POINT (5 0)":            true,
+		"POINT (0 0)":            false,
+		"LINESTRING (2 0, 4 0)":  true,
+		"LINESTRING (0 0, 10 0)": true,
+		"LINESTRING (5 0, 15 0)": false,
+	} {
+		if w := shape(s).within(line); w != expected {
+			t.Errorf("expected %v within the line to be %v but got %v", s, expected, w)
+		}
+	}
+	if !shape("POINT (1 1)").within(shape("MULTIPOINT ((1 1), (2 2))")) {
+		t.Errorf("expected a point to be within a multipoint containing it")
+	}
+
+	// Success containing a square inside a larger one
+	if big := shape("POLYGON ((-5 -5, 15 -5, 15 15, -5 15, -5 -5))"); !big.intersects(square) || !square.within(big) {
+		t.Errorf("expected the square to be within the larger one")
+	}
+}
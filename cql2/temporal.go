@@ -0,0 +1,134 @@
+package cql2
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// interval is a temporal value. An instant starts and ends at the same time,
+// a zero start or end is unbounded.
+type interval struct {
+	start, end time.Time
+}
+
+// parseInstant parses a timestamp in RFC 3339 or a date
+func parseInstant(s string) (interval, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return interval{t, t}, true
+		}
+	}
+	return interval{}, false
+}
+
+// instant returns the literal of a DATE or TIMESTAMP
+func instant(s string) (interval, error) {
+	if i, ok := parseInstant(s); ok {
+		return i, nil
+	}
+	return interval{}, fmt.Errorf("%w: invalid instant %q", ErrInvalidFilter, s)
+}
+
+// compare orders instants and compares intervals for equality
+func (i interval) compare(j interval) (int, bool) {
+	if i.start.Equal(i.end) && j.start.Equal(j.end) {
+		return i.start.Compare(j.start), true
+	}
+	if i.start.Equal(j.start) && i.end.Equal(j.end) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// before reports whether i ends before j starts
+func (i interval) before(j interval) bool {
+	return !i.end.IsZero() && !j.start.IsZero() && i.end.Before(j.start)
+}
+
+// temporalValue returns v as an interval. Strings are parsed as instants.
+func temporalValue(v interface{}) (interval, bool) {
+	switch v := v.(type) {
+	case interval:
+		return v, true
+	case string:
+		return parseInstant(v)
+	}
+	return interval{}, false
+}
+
+// bound returns the start or end of an INTERVAL from an instant, a string
+// or .. for an unbounded end
+func bound(v interface{}, end bool) (time.Time, bool) {
+	if s, ok := v.(string); ok && s == ".." {
+		return time.Time{}, true
+	}
+	i, ok := temporalValue(v)
+	if !ok {
+		return time.Time{}, false
+	}
+	if end {
+		return i.end, true
+	}
+	return i.start, true
+}
+
+// makeInterval returns the INTERVAL between the values of start and end
+func makeInterval(start, end expr) expr {
+	return func(f *geojson.Feature) interface{} {
+		s, ok := bound(start(f), false)
+		if !ok {
+			return nil
+		}
+		e, ok := bound(end(f), true)
+		if !ok {
+			return nil
+		}
+		return interval{s, e}
+	}
+}
+
+// temporalOperators are the temporal functions with their test
+var temporalOperators = map[string]func(a, b interval) bool{
+	"T_AFTER": func(a, b interval) bool {
+		return b.before(a)
+	},
+	"T_BEFORE": func(a, b interval) bool {
+		return a.before(b)
+	},
+	"T_DURING": func(a, b interval) bool {
+		return !a.start.IsZero() && !a.end.IsZero() &&
+			(b.start.IsZero() || a.start.After(b.start)) && (b.end.IsZero() || a.end.Before(b.end))
+	},
+	"T_EQUALS": func(a, b interval) bool {
+		return a.start.Equal(b.start) && a.end.Equal(b.end)
+	},
+	"T_INTERSECTS": func(a, b interval) bool {
+		return !a.before(b) && !b.before(a)
+	},
+	"T_DISJOINT": func(a, b interval) bool {
+		return a.before(b) || b.before(a)
+	},
+}
+
+// temporal returns the temporal operator op applied to a and b
+func temporal(op string, a, b expr) (expr, error) {
+	test, ok := temporalOperators[strings.ToUpper(op)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown temporal operator %q", ErrInvalidFilter, op)
+	}
+
+	return func(f *geojson.Feature) interface{} {
+		x, ok := temporalValue(a(f))
+		if !ok {
+			return nil
+		}
+		y, ok := temporalValue(b(f))
+		if !ok {
+			return nil
+		}
+		return test(x, y)
+	}, nil
+}
@@ -0,0 +1,484 @@
+package cql2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/wkt"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenIdent is a keyword, function or property name
+	tokenIdent
+	// tokenQuoted is a property name between double quotes
+	tokenQuoted
+	tokenNumber
+	tokenString
+	// tokenOp is a parenthesis, comma or comparison operator
+	tokenOp
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// keywords can't be used as property names without double quotes
+var keywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "BETWEEN": true,
+	"IN": true, "IS": true, "NULL": true,
+}
+
+// geometryTypes are the keywords starting a WKT geometry literal
+var geometryTypes = map[string]bool{
+	"POINT": true, "LINESTRING": true, "POLYGON": true, "MULTIPOINT": true,
+	"MULTILINESTRING": true, "MULTIPOLYGON": true, "GEOMETRYCOLLECTION": true,
+}
+
+// ParseText compiles a filter in the CQL2 text encoding, such as
+//
+//	class = 'road' AND lanes BETWEEN 2 AND 4 AND S_INTERSECTS(geometry, BBOX(0, 50, 2, 52))
+func ParseText(s string) (Predicate, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &textParser{src: s, tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return predicate(e), nil
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(s) {
+					return nil, &SyntaxError{i, "unterminated string"}
+				}
+				if s[j] == '\'' {
+					if j+1 < len(s) && s[j+1] == '\'' {
+						b.WriteByte('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+				j++
+			}
+			tokens = append(tokens, token{tokenString, b.String(), i})
+			i = j + 1
+		case c == '"':
+			j := strings.IndexByte(s[i+1:], '"')
+			if j < 0 {
+				return nil, &SyntaxError{i, "unterminated property name"}
+			}
+			tokens = append(tokens, token{tokenQuoted, s[i+1 : i+1+j], i})
+			i += j + 2
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+				j++
+				if j < len(s) && (s[j] == '+' || s[j] == '-') {
+					j++
+				}
+				for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+					j++
+				}
+			}
+			tokens = append(tokens, token{tokenNumber, s[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '.' || s[j] == ':' || s[j] >= '0' && s[j] <= '9' || unicode.IsLetter(rune(s[j])) || s[j] >= 0x80) {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, s[i:j], i})
+			i = j
+		default:
+			op := string(c)
+			if i+1 < len(s) {
+				switch two := s[i : i+2]; two {
+				case "<>", "<=", ">=":
+					op = two
+				}
+			}
+			switch op {
+			case "(", ")", ",", "=", "<>", "<", ">", "<=", ">=", "-":
+			default:
+				return nil, &SyntaxError{i, "unexpected character " + strconv.Quote(op)}
+			}
+			tokens = append(tokens, token{tokenOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{tokenEOF, "end of filter", len(s)}), nil
+}
+
+// textParser is a recursive descent parser of CQL2 text. From the lowest
+// precedence: OR, AND, NOT then predicates.
+type textParser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+func (p *textParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *textParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it's the keyword k
+func (p *textParser) keyword(k string) bool {
+	if t := p.peek(); t.kind == tokenIdent && strings.EqualFold(t.text, k) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// op consumes the next token if it's the operator o
+func (p *textParser) op(o string) bool {
+	if t := p.peek(); t.kind == tokenOp && t.text == o {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *textParser) expect(o string) error {
+	if !p.op(o) {
+		t := p.peek()
+		return p.errorf(t, "expected %q but got %q", o, t.text)
+	}
+	return nil
+}
+
+func (p *textParser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{t.offset, fmt.Sprintf(format, args...)}
+}
+
+func (p *textParser) parseOr() (expr, error) {
+	e, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		e = or(e, r)
+	}
+	return e, nil
+}
+
+func (p *textParser) parseAnd() (expr, error) {
+	e, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		e = and(e, r)
+	}
+	return e, nil
+}
+
+func (p *textParser) parseNot() (expr, error) {
+	if p.keyword("NOT") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return not(e), nil
+	}
+	return p.parsePredicate()
+}
+
+// parsePredicate parses a parenthesized expression, a spatial or temporal
+// function, a boolean literal or a comparison
+func (p *textParser) parsePredicate() (expr, error) {
+	if p.op("(") {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+
+	t := p.peek()
+	if t.kind == tokenIdent && p.tokens[p.pos+1].text == "(" {
+		name := strings.ToUpper(t.text)
+		_, isSpatial := spatialOperators[name]
+		_, isTemporal := temporalOperators[name]
+		if isSpatial || isTemporal {
+			p.pos += 2
+			a, err := p.parseScalar()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			b, err := p.parseScalar()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			if isSpatial {
+				return spatial(name, a, b)
+			}
+			return temporal(name, a, b)
+		}
+	}
+
+	left, err := p.parseScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.peek()
+	if op.kind == tokenOp {
+		switch op.text {
+		case "=", "<>", "<", "<=", ">", ">=":
+			p.pos++
+			right, err := p.parseScalar()
+			if err != nil {
+				return nil, err
+			}
+			return compare(op.text, left, right)
+		}
+	}
+
+	if p.keyword("IS") {
+		negate := p.keyword("NOT")
+		if !p.keyword("NULL") {
+			t := p.peek()
+			return nil, p.errorf(t, "expected NULL but got %q", t.text)
+		}
+		return negated(isNull(left), negate), nil
+	}
+
+	negate := p.keyword("NOT")
+	switch {
+	case p.keyword("LIKE"):
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, p.errorf(t, "expected a pattern but got %q", t.text)
+		}
+		e, err := like(left, t.text)
+		if err != nil {
+			return nil, p.errorf(t, "pattern %q ends with an escape", t.text)
+		}
+		return negated(e, negate), nil
+	case p.keyword("BETWEEN"):
+		low, err := p.parseScalar()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			t := p.peek()
+			return nil, p.errorf(t, "expected AND but got %q", t.text)
+		}
+		high, err := p.parseScalar()
+		if err != nil {
+			return nil, err
+		}
+		return negated(between(left, low, high), negate), nil
+	case p.keyword("IN"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var values []expr
+		for {
+			v, err := p.parseScalar()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			if !p.op(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return negated(in(left, values), negate), nil
+	}
+
+	if !negate && (strings.EqualFold(t.text, "TRUE") || strings.EqualFold(t.text, "FALSE")) && t.kind == tokenIdent {
+		return left, nil
+	}
+	t = p.peek()
+	return nil, p.errorf(t, "expected a comparison but got %q", t.text)
+}
+
+func negated(e expr, negate bool) expr {
+	if negate {
+		return not(e)
+	}
+	return e
+}
+
+// parseScalar parses a literal, a property or a temporal or geometry
+// constructor
+func (p *textParser) parseScalar() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return literal(t.text), nil
+	case tokenNumber:
+		return p.number(t, false)
+	case tokenQuoted:
+		return property(t.text), nil
+	case tokenOp:
+		if t.text == "-" {
+			if n := p.next(); n.kind == tokenNumber {
+				return p.number(n, true)
+			}
+		}
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	case tokenEOF:
+		return nil, p.errorf(t, "unexpected end of filter")
+	}
+
+	name := strings.ToUpper(t.text)
+	call := p.peek().text == "("
+	switch {
+	case name == "TRUE":
+		return literal(true), nil
+	case name == "FALSE":
+		return literal(false), nil
+	case (name == "DATE" || name == "TIMESTAMP") && call:
+		p.pos++
+		s := p.next()
+		if s.kind != tokenString {
+			return nil, p.errorf(s, "expected a string but got %q", s.text)
+		}
+		i, err := instant(s.text)
+		if err != nil {
+			return nil, &SyntaxError{s.offset, err.Error()}
+		}
+		return literal(i), p.expect(")")
+	case name == "INTERVAL" && call:
+		p.pos++
+		start, err := p.parseScalar()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		end, err := p.parseScalar()
+		if err != nil {
+			return nil, err
+		}
+		return makeInterval(start, end), p.expect(")")
+	case name == "BBOX" && call:
+		p.pos++
+		var v []float64
+		for {
+			negative := p.op("-")
+			n := p.next()
+			if n.kind != tokenNumber {
+				return nil, p.errorf(n, "expected a number but got %q", n.text)
+			}
+			f, _ := strconv.ParseFloat(n.text, 64)
+			if negative {
+				f = -f
+			}
+			v = append(v, f)
+			if !p.op(",") {
+				break
+			}
+		}
+		g, err := bboxGeometry(v)
+		if err != nil {
+			return nil, &SyntaxError{t.offset, err.Error()}
+		}
+		return literal(g), p.expect(")")
+	case geometryTypes[name]:
+		return p.geometry(t)
+	case keywords[name]:
+		return nil, p.errorf(t, "unexpected %s", name)
+	}
+	return property(t.text), nil
+}
+
+func (p *textParser) number(t token, negative bool) (expr, error) {
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, p.errorf(t, "invalid number %q", t.text)
+	}
+	if negative {
+		f = -f
+	}
+	return literal(f), nil
+}
+
+// geometry parses the WKT geometry literal starting with the type t
+func (p *textParser) geometry(t token) (expr, error) {
+	// skip the dimension and EMPTY up to the coordinates
+	for p.peek().kind == tokenIdent {
+		if strings.EqualFold(p.next().text, "EMPTY") {
+			return nil, p.errorf(t, "empty geometries aren't supported")
+		}
+	}
+
+	depth := 0
+	for {
+		n := p.next()
+		switch {
+		case n.kind == tokenEOF:
+			return nil, p.errorf(n, "unterminated geometry")
+		case n.text == "(" && n.kind == tokenOp:
+			depth++
+		case n.text == ")" && n.kind == tokenOp:
+			depth--
+		}
+		if depth == 0 {
+			var g geojson.Geometry
+			if err := wkt.Unmarshal([]byte(p.src[t.offset:n.offset+1]), &g); err != nil {
+				return nil, p.errorf(t, "invalid geometry: %v", err)
+			}
+			return literal(&g), nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cql2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var testFeatures = []geojson.Feature{
+	{
+		ID:         1.0,
+		Geometry:   geojson.NewGeometry(&geojson.Point{Coordinates: geojson.Position{1, 1}}),
+		Properties: geojson.Properties{"name": "Main Street", "class": "road", "lanes": 2.0, "open": true, "built": "2001-05-04T10:00:00Z"},
+	},
+	{
+		ID:         2.0,
+		Geometry:   geojson.NewGeometry(&geojson.LineString{Coordinates: geojson.Positions{{5, 5}, {15, 5}}}),
+		Properties: geojson.Properties{"name": "O'Brien Way", "class": "road", "lanes": 4.0, "open": false, "built": "2015-01-01", "closed": "2020-06-30"},
+	},
+	{
+		ID:         3.0,
+		Geometry:   geojson.NewGeometry(&geojson.Polygon{Coordinates: []geojson.Positions{{{20, 20}, {30, 20}, {30, 30}, {20, 30}, {20, 20}}}}),
+		Properties: geojson.Properties{"name": "Park", "class": "landuse", "lanes": nil},
+	},
+}
+
+// matching returns the IDs of the features matching p
+func matching(features []geojson.Feature, p Predicate) []interface{} {
+	ids := []interface{}{}
+	for i := range features {
+		if p(&features[i]) {
+			ids = append(ids, features[i].ID)
+		}
+	}
+	return ids
+}
+
+func TestParseText(t *testing.T) {
+	features := testFeatures
+
+	// Success matching the features of every kind of predicate
+	for filter, expected := range map[string][]interface{}{
+		"class = 'road'":                 {1.0, 2.0},
+		"class <> 'road'":                {3.0},
+		"lanes >= 2 AND lanes < 4":       {1.0},
+		"lanes > -1.5e0":                 {1.0, 2.0},
+		"NOT lanes = 2":                  {2.0},
+		"class = 'landuse' OR lanes = 4": {2.0, 3.0},
+		"(class = 'road' OR class = 'rail') AND open = true": {1.0},
+		"name LIKE '%Street'":                                {1.0},
+		"name LIKE 'O''Brien%'":                              {2.0},
+		"name NOT LIKE '_ark'":                               {1.0, 2.0},
+		"name LIKE '100\\%'":                                 {},
+		"lanes BETWEEN 3 AND 4":                              {2.0},
+		"lanes NOT BETWEEN 3 AND 4":                          {1.0},
+		"class IN ('landuse', 'water')":                      {3.0},
+		"lanes NOT IN (2)":                                   {2.0},
+		"lanes IS NULL":                                      {3.0},
+		"closed IS NOT NULL":                                 {2.0},
+		"id = 3":                                             {3.0},
+		`"class" = 'road' AND "lanes" = 4`:                   {2.0},
+		"TRUE":                                               {1.0, 2.0, 3.0},
+		"S_INTERSECTS(geometry, BBOX(0, 0, 10, 10))":         {1.0, 2.0},
+		"S_INTERSECTS(geometry, POINT (25 25))":              {3.0},
+		"s_within(geometry, POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0)))":                                              {1.0},
+		"S_DISJOINT(geometry, BBOX(0, 0, 10, 10))":                                                                 {3.0},
+		"S_CONTAINS(geometry, POINT (21 29))":                                                                      {3.0},
+		"S_WITHIN(POINT (25 25), geometry)":                                                                        {3.0},
+		"S_WITHIN(POINT (20 25), geometry)":                                                                        {},
+		"S_WITHIN(geometry, POLYGON ((20 20, 30 20, 30 30, 20 30, 20 20), (24 24, 26 24, 26 26, 24 26, 24 24)))":   {},
+		"S_CONTAINS(POLYGON ((20 20, 30 20, 30 30, 20 30, 20 20), (24 24, 26 24, 26 26, 24 26, 24 24)), geometry)": {},
+		"built > TIMESTAMP('2010-01-01T00:00:00Z')":                                                                {2.0},
+		"built = DATE('2015-01-01')":                                                                               {2.0},
+		"T_BEFORE(built, DATE('2010-01-01'))":                                                                      {1.0},
+		"T_AFTER(built, TIMESTAMP('2001-05-04T09:00:00Z'))":                                                        {1.0, 2.0},
+		"T_DURING(INTERVAL(built, closed), INTERVAL('2014-01-01', '..'))":                                          {2.0},
+		"T_INTERSECTS(built, INTERVAL('2001-01-01', '2001-12-31'))":                                                {1.0},
+		"T_DISJOINT(built, INTERVAL('..', '2001-12-31'))":                                                          {2.0},
+		"T_EQUALS(closed, DATE('2020-06-30'))":                                                                     {2.0},
+	} {
+		p, err := ParseText(filter)
+		if err != nil {
+			t.Errorf("expected %q to parse but got %v", filter, err)
+			continue
+		}
+		if ids := matching(features, p); !equalIDs(ids, expected) {
+			t.Errorf("expected %v for %q but got %v", expected, filter, ids)
+		}
+	}
+
+	// Fail on invalid filters with the offset of the error
+	for filter, offset := range map[string]int{
+		"":                            0,
+		"class =":                     7,
+		"class = 'road":               8,
+		"class ==":                    7,
+		"lanes BETWEEN 1 OR 2":        16,
+		"name LIKE 1":                 10,
+		"name LIKE 'Park\\'":          10,
+		"(class = 'road'":             15,
+		"class = 'road' extra":        15,
+		"lanes IS 1":                  9,
+		"lanes":                       5,
+		"and = 1":                     0,
+		"class # 1":                   6,
+		"built = DATE('yesterday')":   13,
+		"S_WITHIN(geometry, BBOX(1))": 19,
+	} {
+		_, err := ParseText(filter)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("expected a syntax error for %q but got %v", filter, err)
+		} else if se.Offset != offset {
+			t.Errorf("expected offset %v for %q but got %v", offset, filter, se.Offset)
+		} else if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected %q but got %v", ErrInvalidFilter, err)
+		}
+	}
+}
+
+func equalIDs(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
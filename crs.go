@@ -3,7 +3,6 @@ package geojson
 import (
 	"encoding/json"
 	"errors"
-	"net/url"
 )
 
 var (
@@ -64,37 +63,7 @@ func (c *CRS) setProperty() error {
 // MarshalJSON will take a CRS and properly verify the struct and conditionally
 // marshal a CRS name or link
 func (c CRS) MarshalJSON() ([]byte, error) {
-	type crs struct {
-		Type       string      `json:"type"`
-		Properties interface{} `json:"properties"`
-	}
-
-	var j crs
-	i := 0
-
-	if c.Name != nil {
-		j = crs{Type: "name", Properties: c.Name}
-		i++
-	}
-	if c.Link != nil {
-		if _, err := url.Parse(c.Link.Href); err != nil {
-			return nil, err
-		}
-		j = crs{Type: "link", Properties: c.Link}
-		i++
-	}
-
-	// No crs property means don't include it in the JSON
-	if i == 0 {
-		return nil, nil
-	}
-	// There cannot be more than one crs specified
-	if i >= 2 {
-		return nil, ErrMultipleCRSs
-	}
-
-	return json.Marshal(j)
-
+	return c.AppendJSON(nil)
 }
 
 // UnmarshalJSON will take raw CRS JSON and properly fill out the CRS with either
@@ -0,0 +1,1260 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// errSyntax is returned by the decoder for malformed JSON, which is then
+// reported the way encoding/json reports it
+var errSyntax = errors.New("invalid JSON")
+
+// ErrLimitExceeded is returned when GeoJSON goes over a limit of the
+// DecodeOptions decoding it
+var ErrLimitExceeded = errors.New("decode limit exceeded")
+
+// LimitError is ErrLimitExceeded with the limit that was exceeded
+type LimitError struct {
+	// Limit is the name of the DecodeOptions field of the limit
+	Limit string
+	// Max is the value of the limit
+	Max int
+	// Offset is the byte offset in the input where the limit was exceeded
+	Offset int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s of %d at offset %d", ErrLimitExceeded, e.Limit, e.Max, e.Offset)
+}
+
+// Unwrap returns ErrLimitExceeded
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// DecodeError is an error with a GeoJSON object, such as ErrInvalidGeometry,
+// with where the object is in the JSON.
+//
+// Offset, Line and Column are relative to the JSON given to Unmarshal or
+// UnmarshalJSON. When a Geometry or Feature is a field of another type decoded
+// by encoding/json, they are relative to the field's value, not the document.
+type DecodeError struct {
+	// Err is the error with the object
+	Err error
+	// Offset is the byte offset of the object
+	Offset int64
+	// Line and Column are the line and column of Offset, starting at 1, with
+	// Column counting characters rather than bytes
+	Line, Column int
+	// Path is the path of the object, such as features[42].geometry, which
+	// is empty for the object decoded
+	Path string
+	// Type is the type member of the object
+	Type string
+
+	// path are the segments of Path from the object up
+	path []string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%v at line %d, column %d", e.Err, e.Line, e.Column)
+	}
+	return fmt.Sprintf("%v at %s, line %d, column %d", e.Err, e.Path, e.Line, e.Column)
+}
+
+// Unwrap returns Err
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeError returns err of the object of type t at offset as a
+// DecodeError when it's an error with GeoJSON rather than its JSON
+func decodeError(err error, offset int, t string) error {
+	if errors.Is(err, ErrInvalidGeometry) || errors.Is(err, ErrInvalidGeoJSON) || errors.Is(err, ErrInvalidCRS) {
+		return &DecodeError{Err: err, Offset: int64(offset), Type: t}
+	}
+	return err
+}
+
+// locate fills in Path, Line and Column of a DecodeError in data
+func (e *DecodeError) locate(data []byte) {
+	var b strings.Builder
+	for i := len(e.path) - 1; i >= 0; i-- {
+		b.WriteString(e.path[i])
+	}
+	e.Path = strings.TrimPrefix(b.String(), ".")
+	before := data[:e.Offset]
+	e.Line = bytes.Count(before, []byte("\n")) + 1
+	e.Column = utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+}
+
+// kind is the kind of GeoJSON object expected by the decoder, which decides
+// what members are foreign
+type kind int
+
+const (
+	kindAny kind = iota
+	kindGeometry
+	kindFeature
+	kindFeatureCollection
+)
+
+// kindOf returns the kind of the GeoJSON type t or kindAny when unknown
+func kindOf(t string) kind {
+	switch t {
+	case "Point", "MultiPoint", "LineString", "MultiLineString", "Polygon", "MultiPolygon", "GeometryCollection":
+		return kindGeometry
+	case "Feature":
+		return kindFeature
+	case "FeatureCollection":
+		return kindFeatureCollection
+	}
+	return kindAny
+}
+
+// kindType returns the Go type decoding the kind k
+func kindType(k kind) reflect.Type {
+	switch k {
+	case kindGeometry:
+		return reflect.TypeFor[Geometry]()
+	case kindFeature:
+		return reflect.TypeFor[Feature]()
+	case kindFeatureCollection:
+		return reflect.TypeFor[FeatureCollection]()
+	}
+	return reflect.TypeFor[GeoJSON]()
+}
+
+// members are the members of any GeoJSON object, filled in by a single pass
+// over its JSON. Members depending on the type, such as coordinates, that
+// appear before it are decoded generically and converted once it's known.
+type members struct {
+	Object
+	// coordinates are a Position, Positions, []Positions or [][]Positions
+	// when decoded after the type or a generic JSON value otherwise
+	coordinates    interface{}
+	hasCoordinates bool
+	geometries     []Geometry
+	hasGeometries  bool
+	id             interface{}
+	geometry       *Geometry
+	// properties are Properties or, when decoded before the type, a generic
+	// JSON value
+	properties interface{}
+	features   []Feature
+	// numbers are the text of the numbers of the coordinates, which are only
+	// kept with ExactNumbers
+	numbers numberTexts
+}
+
+// DecodeOptions control how GeoJSON is decoded. The zero value decodes as
+// json.Unmarshal does.
+//
+// The limits bound the memory used to decode untrusted input, failing with a
+// *LimitError once one is exceeded. A limit of 0 is no limit.
+type DecodeOptions struct {
+	// MaxBytes limits the size of the input. Unmarshal is given the input
+	// in memory already, so only Decode bounds the memory read with it.
+	MaxBytes int
+	// MaxDepth limits the nesting of JSON objects and arrays, such as
+	// GeometryCollections inside GeometryCollections. The nesting is never
+	// deeper than 10000, as with encoding/json.
+	MaxDepth int
+	// MaxPositions limits the positions of all the geometries
+	MaxPositions int
+	// MaxRingPositions limits the positions of a single ring, LineString or
+	// MultiPoint
+	MaxRingPositions int
+	// MaxFeatures limits the features of all the FeatureCollections
+	MaxFeatures int
+	// MaxPropertyBytes limits the size of the properties of a Feature
+	MaxPropertyBytes int
+
+	// ExactNumbers keeps the text of numbers. The numbers of properties and
+	// IDs are decoded as json.Number, and geometries remember the text of
+	// their coordinates to encode them with the same digits as long as they
+	// aren't changed.
+	ExactNumbers bool
+}
+
+// defaultDecodeOptions are the options of the UnmarshalJSON methods
+var defaultDecodeOptions = &DecodeOptions{}
+
+// maxDepth is the nesting limit when MaxDepth is 0, keeping deeply nested
+// input from overflowing the stack
+const maxDepth = 10000
+
+// Decode reads r to the end and decodes it into v as Unmarshal does, reading
+// no more than MaxBytes and one byte over it
+func (o DecodeOptions) Decode(r io.Reader, v interface{}) error {
+	if o.MaxBytes > 0 {
+		r = io.LimitReader(r, int64(o.MaxBytes)+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return o.Unmarshal(data, v)
+}
+
+// Unmarshal decodes data into v, a pointer to a Geometry, Feature,
+// FeatureCollection or GeoJSON
+func (o DecodeOptions) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *Geometry:
+		return v.unmarshal(data, &o)
+	case *Feature:
+		return v.unmarshal(data, &o)
+	case *FeatureCollection:
+		return v.unmarshal(data, &o)
+	case *GeoJSON:
+		return v.unmarshal(data, &o)
+	}
+	return fmt.Errorf("%w: got %T", ErrUnsupportedType, v)
+}
+
+// unmarshal decodes the GeoJSON object data of the kind k, calling set with
+// its members
+func unmarshal(data []byte, k kind, o *DecodeOptions, set func(m *members) error) error {
+	if o.MaxBytes > 0 && len(data) > o.MaxBytes {
+		return &LimitError{Limit: "MaxBytes", Max: o.MaxBytes, Offset: int64(o.MaxBytes)}
+	}
+	d := decoder{data: data, o: o}
+	if o.ExactNumbers {
+		d.exact, d.text = true, string(data)
+	}
+	if c := d.peek(); c != '{' && json.Valid(data) {
+		return &json.UnmarshalTypeError{Value: d.valueName(), Type: kindType(k)}
+	}
+	start := d.off
+	var m members
+	err := d.members(k, &m)
+	if err == nil {
+		d.ws()
+		if d.off < len(d.data) {
+			err = errSyntax
+		}
+	}
+	if errors.Is(err, errSyntax) {
+		// encoding/json has the detailed message and offset
+		if jerr := json.Unmarshal(data, new(interface{})); jerr != nil {
+			return jerr
+		}
+		return fmt.Errorf("%w at offset %d", errSyntax, d.off)
+	}
+	if err == nil {
+		err = decodeError(set(&m), start, m.Type)
+	}
+	if e, ok := err.(*DecodeError); ok {
+		e.locate(data)
+	}
+	return err
+}
+
+// decoder reads GeoJSON from JSON in a single pass
+type decoder struct {
+	data []byte
+	off  int
+	o    *DecodeOptions
+	// structName is the name of the Go type of the object being decoded,
+	// for type errors
+	structName string
+	// depth, positionCount and featureCount count what's limited by o
+	depth         int
+	positionCount int
+	featureCount  int
+	// exact keeps the text of numbers, sliced from text, the data as a
+	// string
+	exact bool
+	text  string
+	// numbers are the numbers of the coordinates of the geometry being
+	// decoded with exact
+	numbers numberTexts
+}
+
+var (
+	float64Type    = reflect.TypeFor[float64]()
+	stringType     = reflect.TypeFor[string]()
+	propertiesType = reflect.TypeFor[Properties]()
+)
+
+// typeError returns the error of a JSON value that doesn't fit the Go type t
+// of a member
+func (d *decoder) typeError(value string, t reflect.Type, field string) error {
+	return &json.UnmarshalTypeError{Value: value, Type: t, Offset: int64(d.off), Struct: d.structName, Field: field}
+}
+
+// limit returns a LimitError for the limit named name of max when n is over
+// it
+func (d *decoder) limit(name string, n, max int) error {
+	if max > 0 && n > max {
+		return &LimitError{Limit: name, Max: max, Offset: int64(d.off)}
+	}
+	return nil
+}
+
+// enter goes into an object or array, checking MaxDepth. The caller goes out
+// again by decrementing depth.
+func (d *decoder) enter() error {
+	d.depth++
+	if d.o.MaxDepth <= 0 {
+		return d.limit("MaxDepth", d.depth, maxDepth)
+	}
+	return d.limit("MaxDepth", d.depth, d.o.MaxDepth)
+}
+
+// ws skips whitespace
+func (d *decoder) ws() {
+	for d.off < len(d.data) {
+		switch d.data[d.off] {
+		case ' ', '\t', '\n', '\r':
+			d.off++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next byte after whitespace or 0 at the end
+func (d *decoder) peek() byte {
+	d.ws()
+	if d.off < len(d.data) {
+		return d.data[d.off]
+	}
+	return 0
+}
+
+// valueName returns the name encoding/json gives the kind of the next value
+// in its errors
+func (d *decoder) valueName() string {
+	switch d.peek() {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// literal consumes the literal s
+func (d *decoder) literal(s string) error {
+	if !bytes.HasPrefix(d.data[d.off:], []byte(s)) {
+		return errSyntax
+	}
+	d.off += len(s)
+	return nil
+}
+
+// null consumes a null and reports whether there was one
+func (d *decoder) null() (bool, error) {
+	if d.peek() != 'n' {
+		return false, nil
+	}
+	return true, d.literal("null")
+}
+
+// object calls fn with the key of every member of an object, fn consuming
+// the value
+func (d *decoder) object(fn func(key []byte) error) error {
+	if d.peek() != '{' {
+		return errSyntax
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer func() { d.depth-- }()
+	d.off++
+	if d.peek() == '}' {
+		d.off++
+		return nil
+	}
+	for {
+		if d.peek() != '"' {
+			return errSyntax
+		}
+		key, err := d.rawString()
+		if err != nil {
+			return err
+		}
+		if d.peek() != ':' {
+			return errSyntax
+		}
+		d.off++
+		if err := fn(key); err != nil {
+			return err
+		}
+		switch d.peek() {
+		case ',':
+			d.off++
+		case '}':
+			d.off++
+			return nil
+		default:
+			return errSyntax
+		}
+	}
+}
+
+// array calls fn for every item of an array, fn consuming the item
+func (d *decoder) array(fn func() error) error {
+	if d.peek() != '[' {
+		return errSyntax
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer func() { d.depth-- }()
+	d.off++
+	if d.peek() == ']' {
+		d.off++
+		return nil
+	}
+	for {
+		if err := fn(); err != nil {
+			return err
+		}
+		switch d.peek() {
+		case ',':
+			d.off++
+		case ']':
+			d.off++
+			return nil
+		default:
+			return errSyntax
+		}
+	}
+}
+
+// rawString consumes a string returning its contents, decoded only when it
+// has escapes or invalid UTF-8
+func (d *decoder) rawString() ([]byte, error) {
+	start := d.off
+	d.off++
+	plain := true
+	for d.off < len(d.data) {
+		c := d.data[d.off]
+		switch {
+		case c == '"':
+			d.off++
+			s := d.data[start+1 : d.off-1]
+			if plain {
+				return s, nil
+			}
+			var v string
+			if err := json.Unmarshal(d.data[start:d.off], &v); err != nil {
+				return nil, errSyntax
+			}
+			return []byte(v), nil
+		case c == '\\':
+			plain = false
+			d.off += 2
+		case c < 0x20:
+			return nil, errSyntax
+		case c >= utf8.RuneSelf:
+			plain = false
+			d.off++
+		default:
+			d.off++
+		}
+	}
+	return nil, errSyntax
+}
+
+// string consumes a string or null, which is an empty string
+func (d *decoder) string(field string) (string, error) {
+	switch d.peek() {
+	case '"':
+		s, err := d.rawString()
+		return string(s), err
+	case 'n':
+		return "", d.literal("null")
+	}
+	return "", d.typeError(d.valueName(), stringType, field)
+}
+
+// number consumes a number or null, which is 0
+func (d *decoder) number(field string) (float64, error) {
+	switch c := d.peek(); {
+	case c == 'n':
+		return 0, d.literal("null")
+	case c != '-' && (c < '0' || c > '9'):
+		if c == '{' || c == '[' || c == '"' || c == 't' || c == 'f' {
+			return 0, d.typeError(d.valueName(), float64Type, field)
+		}
+		return 0, errSyntax
+	}
+
+	start := d.off
+	if err := d.skipNumber(); err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(string(d.data[start:d.off]), 64)
+	if err != nil {
+		return 0, &json.UnmarshalTypeError{Value: "number " + string(d.data[start:d.off]), Type: float64Type, Offset: int64(start), Struct: d.structName, Field: field}
+	}
+	return f, nil
+}
+
+// skipNumber consumes a number as JSON defines it
+func (d *decoder) skipNumber() error {
+	digits := func() int {
+		n := 0
+		for d.off < len(d.data) && d.data[d.off] >= '0' && d.data[d.off] <= '9' {
+			d.off++
+			n++
+		}
+		return n
+	}
+	at := func(c byte) bool {
+		return d.off < len(d.data) && d.data[d.off] == c
+	}
+
+	if at('-') {
+		d.off++
+	}
+	if at('0') {
+		d.off++
+	} else if digits() == 0 {
+		return errSyntax
+	}
+	if at('.') {
+		d.off++
+		if digits() == 0 {
+			return errSyntax
+		}
+	}
+	if at('e') || at('E') {
+		d.off++
+		if at('+') || at('-') {
+			d.off++
+		}
+		if digits() == 0 {
+			return errSyntax
+		}
+	}
+	return nil
+}
+
+// value consumes any value the way encoding/json decodes it into an
+// interface{}
+func (d *decoder) value() (interface{}, error) {
+	switch c := d.peek(); c {
+	case '{':
+		m := make(map[string]interface{})
+		err := d.object(func(key []byte) error {
+			v, err := d.value()
+			m[string(key)] = v
+			return err
+		})
+		return m, err
+	case '[':
+		a := []interface{}{}
+		err := d.array(func() error {
+			v, err := d.value()
+			a = append(a, v)
+			return err
+		})
+		return a, err
+	case '"':
+		s, err := d.rawString()
+		return string(s), err
+	case 't':
+		return true, d.literal("true")
+	case 'f':
+		return false, d.literal("false")
+	case 'n':
+		return nil, d.literal("null")
+	}
+	if d.exact {
+		start := d.off
+		if _, err := d.number(""); err != nil {
+			return nil, err
+		}
+		return json.Number(d.text[start:d.off]), nil
+	}
+	return d.number("")
+}
+
+// skip consumes any value without keeping it
+func (d *decoder) skip() error {
+	switch d.peek() {
+	case '{':
+		return d.object(func([]byte) error { return d.skip() })
+	case '[':
+		return d.array(d.skip)
+	case '"':
+		_, err := d.rawString()
+		return err
+	case 't':
+		return d.literal("true")
+	case 'f':
+		return d.literal("false")
+	case 'n':
+		return d.literal("null")
+	}
+	return d.skipNumber()
+}
+
+// memberNames are the names of the members decoded, which are matched
+// without regard to case as encoding/json does
+var memberNames = []string{"type", "bbox", "crs", "coordinates", "geometries", "id", "geometry", "properties", "features"}
+
+// memberName returns the name of the member of key or an empty string for a
+// foreign member
+func memberName(key []byte) string {
+	for _, name := range memberNames {
+		if string(key) == name {
+			return name
+		}
+	}
+	for _, name := range memberNames {
+		if bytes.EqualFold(key, []byte(name)) {
+			return name
+		}
+	}
+	return ""
+}
+
+// members consumes an object of the kind k into m, skipping the members
+// that are foreign to the kind
+func (d *decoder) members(k kind, m *members) error {
+	if d.exact {
+		numbers := d.numbers
+		defer func() { d.numbers = numbers }()
+		m.numbers = numberTexts{}
+		d.numbers = m.numbers
+	}
+
+	structName := d.structName
+	defer func() { d.structName = structName }()
+	d.structName = kindType(k).Name()
+
+	// later are the offsets of members that are foreign to some types,
+	// decoded once the type is known
+	var later []memberOffset
+	err := d.object(func(key []byte) error {
+		name := memberName(key)
+		if m.Type == "" && (name == "geometry" || name == "geometries" || name == "features") && (k == kindAny || k == kindGeometry) {
+			later = append(later, memberOffset{name, d.off})
+			return d.skip()
+		}
+		return d.member(k, name, m)
+	})
+	if err != nil || len(later) == 0 || m.Type == "" {
+		return err
+	}
+
+	end := d.off
+	d.depth++
+	for _, l := range later {
+		d.off = l.off
+		if err := d.member(k, l.name, m); err != nil {
+			return err
+		}
+	}
+	d.depth--
+	d.off = end
+	return nil
+}
+
+// memberOffset is the offset of the value of the member name
+type memberOffset struct {
+	name string
+	off  int
+}
+
+// member consumes the value of the member name of an object of the kind k
+// into m, skipping it when it's foreign to the kind
+func (d *decoder) member(k kind, name string, m *members) error {
+	if k == kindAny && m.Type != "" {
+		k = kindOf(m.Type)
+		d.structName = kindType(k).Name()
+	}
+
+	switch {
+	case name == "type":
+		var err error
+		m.Type, err = d.string("type")
+		return err
+	case name == "bbox":
+		return d.bbox(&m.Object)
+	case name == "crs":
+		return at(d.crs(&m.Object), ".crs")
+	case name == "coordinates" && k == kindGeometry && m.Type != "":
+		var err error
+		m.coordinates, err = d.coordinates(m.Type)
+		m.hasCoordinates = true
+		return err
+	case name == "coordinates" && (k == kindGeometry || k == kindAny):
+		c, err := d.value()
+		m.coordinates, m.hasCoordinates = c, true
+		if err != nil {
+			return err
+		}
+		return d.limitCoordinates(c)
+	case name == "geometries" && m.Type == "GeometryCollection":
+		var err error
+		m.geometries, err = d.geometries()
+		m.hasGeometries = true
+		return at(err, ".geometries")
+	case name == "id" && (k == kindFeature || k == kindAny):
+		var err error
+		m.id, err = d.value()
+		return err
+	case name == "geometry" && (k == kindFeature || k == kindAny):
+		var err error
+		m.geometry, err = d.geometry()
+		return at(err, ".geometry")
+	case name == "properties" && k == kindFeature:
+		var err error
+		m.properties, err = d.properties()
+		return err
+	case name == "properties" && k == kindAny:
+		if err := d.limitProperties(); err != nil {
+			return err
+		}
+		var err error
+		m.properties, err = d.value()
+		return err
+	case name == "features" && (k == kindFeatureCollection || k == kindAny):
+		var err error
+		m.features, err = d.features()
+		return at(err, ".features")
+	}
+	return d.skip()
+}
+
+// bbox consumes the bbox member of o
+func (d *decoder) bbox(o *Object) error {
+	if null, err := d.null(); null || err != nil {
+		o.BoundingBox = nil
+		return err
+	}
+	if d.peek() != '[' {
+		return d.typeError(d.valueName(), reflect.TypeFor[BoundingBox](), "bbox")
+	}
+	b := BoundingBox{}
+	err := d.array(func() error {
+		f, err := d.number("bbox")
+		b = append(b, f)
+		return err
+	})
+	o.BoundingBox = &b
+	return err
+}
+
+// crs consumes the crs member of o
+func (d *decoder) crs(o *Object) error {
+	if null, err := d.null(); null || err != nil {
+		o.CRS = nil
+		return err
+	}
+	if d.peek() != '{' {
+		return d.typeError(d.valueName(), reflect.TypeFor[CRS](), "crs")
+	}
+	start := d.off
+
+	c := &CRS{}
+	var name CRSName
+	var link CRSLink
+	hasProperties := false
+	err := d.object(func(key []byte) error {
+		switch {
+		case bytes.EqualFold(key, []byte("type")):
+			var err error
+			c.Type, err = d.string("crs.type")
+			return err
+		case bytes.EqualFold(key, []byte("properties")):
+			start := d.off
+			hasProperties = true
+			if null, err := d.null(); null || err != nil {
+				c.Properties = json.RawMessage("null")
+				return err
+			}
+			err := d.object(func(key []byte) error {
+				var err error
+				switch {
+				case bytes.EqualFold(key, []byte("name")):
+					name.Name, err = d.string("crs.properties.name")
+				case bytes.EqualFold(key, []byte("href")):
+					link.Href, err = d.string("crs.properties.href")
+				case bytes.EqualFold(key, []byte("type")):
+					link.Type, err = d.string("crs.properties.type")
+				default:
+					err = d.skip()
+				}
+				return err
+			})
+			d.ws()
+			c.Properties = append(json.RawMessage(nil), bytes.TrimSpace(d.data[start:d.off])...)
+			return err
+		}
+		return d.skip()
+	})
+	if err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case "name":
+		c.Name = &name
+	case "link":
+		c.Link = &link
+	default:
+		return decodeError(ErrInvalidCRS, start, c.Type)
+	}
+	if !hasProperties {
+		return decodeError(fmt.Errorf("%w: %s without properties", ErrInvalidCRS, c.Type), start, c.Type)
+	}
+	o.CRS = c
+	return nil
+}
+
+// geometry consumes a geometry or null
+func (d *decoder) geometry() (*Geometry, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	g := &Geometry{}
+	return g, d.decodeGeometry(g)
+}
+
+// decodeGeometry consumes a geometry into g
+func (d *decoder) decodeGeometry(g *Geometry) error {
+	d.ws()
+	start := d.off
+	if d.peek() != '{' {
+		if d.peek() == 'n' {
+			// as encoding/json would call UnmarshalJSON with null
+			if err := d.literal("null"); err != nil {
+				return err
+			}
+			return decodeError(ErrInvalidGeometry, start, "")
+		}
+		return d.typeError(d.valueName(), reflect.TypeFor[Geometry](), "geometry")
+	}
+	var m members
+	if err := d.members(kindGeometry, &m); err != nil {
+		return err
+	}
+	return decodeError(g.setGeometry(&m), start, m.Type)
+}
+
+// geometries consumes the geometries of a GeometryCollection
+func (d *decoder) geometries() ([]Geometry, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '[' {
+		return nil, d.typeError(d.valueName(), reflect.TypeFor[[]Geometry](), "geometries")
+	}
+	gs := []Geometry{}
+	err := d.array(func() error {
+		gs = append(gs, Geometry{})
+		return at(d.decodeGeometry(&gs[len(gs)-1]), index(len(gs)-1))
+	})
+	return gs, err
+}
+
+// feature consumes a Feature into f, null leaving it unchanged
+func (d *decoder) feature(f *Feature) error {
+	if null, err := d.null(); null || err != nil {
+		return err
+	}
+	if d.peek() != '{' {
+		return d.typeError(d.valueName(), reflect.TypeFor[Feature](), "features")
+	}
+	var m members
+	if err := d.members(kindFeature, &m); err != nil {
+		return err
+	}
+	return f.set(&m)
+}
+
+// features consumes the features of a FeatureCollection
+func (d *decoder) features() ([]Feature, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '[' {
+		return nil, d.typeError(d.valueName(), reflect.TypeFor[[]Feature](), "features")
+	}
+	fs := []Feature{}
+	err := d.array(func() error {
+		d.featureCount++
+		if err := d.limit("MaxFeatures", d.featureCount, d.o.MaxFeatures); err != nil {
+			return err
+		}
+		fs = append(fs, Feature{})
+		return at(d.feature(&fs[len(fs)-1]), index(len(fs)-1))
+	})
+	return fs, err
+}
+
+// properties consumes the properties of a Feature
+func (d *decoder) properties() (Properties, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '{' {
+		return nil, d.typeError(d.valueName(), propertiesType, "properties")
+	}
+	if err := d.limitProperties(); err != nil {
+		return nil, err
+	}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	return Properties(v.(map[string]interface{})), nil
+}
+
+// depth returns the nesting of the coordinates of a geometry type
+func depth(t string) int {
+	switch t {
+	case "Point":
+		return 1
+	case "MultiPoint", "LineString":
+		return 2
+	case "MultiLineString", "Polygon":
+		return 3
+	case "MultiPolygon":
+		return 4
+	}
+	return 0
+}
+
+// coordinates consumes the coordinates of the geometry type t, skipping them
+// for types without coordinates
+func (d *decoder) coordinates(t string) (interface{}, error) {
+	switch depth(t) {
+	case 1:
+		return d.position()
+	case 2:
+		return d.positions()
+	case 3:
+		return d.rings()
+	case 4:
+		return d.polygons()
+	}
+	return nil, d.skip()
+}
+
+func (d *decoder) position() (Position, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '[' {
+		return nil, d.typeError(d.valueName(), reflect.TypeFor[Position](), "coordinates")
+	}
+	d.positionCount++
+	if err := d.limit("MaxPositions", d.positionCount, d.o.MaxPositions); err != nil {
+		return nil, err
+	}
+	// most positions have 2 or 3 dimensions
+	p := make(Position, 0, 3)
+	var texts []string
+	err := d.array(func() error {
+		d.ws()
+		start := d.off
+		f, err := d.number("coordinates")
+		if d.numbers != nil {
+			texts = append(texts, d.text[start:d.off])
+		}
+		p = append(p, f)
+		return err
+	})
+	if len(texts) > 0 {
+		d.numbers[&p[0]] = texts
+	}
+	return p, err
+}
+
+func (d *decoder) positions() (Positions, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '[' {
+		return nil, d.typeError(d.valueName(), reflect.TypeFor[Positions](), "coordinates")
+	}
+	ps := Positions{}
+	err := d.array(func() error {
+		if err := d.limit("MaxRingPositions", len(ps)+1, d.o.MaxRingPositions); err != nil {
+			return err
+		}
+		p, err := d.position()
+		ps = append(ps, p)
+		return err
+	})
+	return ps, err
+}
+
+func (d *decoder) rings() ([]Positions, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '[' {
+		return nil, d.typeError(d.valueName(), reflect.TypeFor[[]Positions](), "coordinates")
+	}
+	rings := []Positions{}
+	err := d.array(func() error {
+		ps, err := d.positions()
+		rings = append(rings, ps)
+		return err
+	})
+	return rings, err
+}
+
+func (d *decoder) polygons() ([][]Positions, error) {
+	if null, err := d.null(); null || err != nil {
+		return nil, err
+	}
+	if d.peek() != '[' {
+		return nil, d.typeError(d.valueName(), reflect.TypeFor[[][]Positions](), "coordinates")
+	}
+	polygons := [][]Positions{}
+	err := d.array(func() error {
+		rings, err := d.rings()
+		polygons = append(polygons, rings)
+		return err
+	})
+	return polygons, err
+}
+
+// limitProperties checks MaxPropertyBytes for the properties that follow,
+// before any of them are decoded
+func (d *decoder) limitProperties() error {
+	if d.o.MaxPropertyBytes <= 0 {
+		return nil
+	}
+	start := d.off
+	if err := d.skip(); err != nil {
+		return err
+	}
+	if d.off-start > d.o.MaxPropertyBytes {
+		d.off = start
+		return &LimitError{Limit: "MaxPropertyBytes", Max: d.o.MaxPropertyBytes, Offset: int64(start)}
+	}
+	d.off = start
+	return nil
+}
+
+// limitCoordinates checks MaxPositions and MaxRingPositions for coordinates
+// decoded generically before the type of their geometry was known
+func (d *decoder) limitCoordinates(v interface{}) error {
+	if isPosition(v) {
+		d.positionCount++
+		return d.limit("MaxPositions", d.positionCount, d.o.MaxPositions)
+	}
+	a, _ := v.([]interface{})
+	positions := 0
+	for _, e := range a {
+		if isPosition(e) {
+			positions++
+		}
+		if err := d.limitCoordinates(e); err != nil {
+			return err
+		}
+	}
+	return d.limit("MaxRingPositions", positions, d.o.MaxRingPositions)
+}
+
+// isPosition reports whether generic coordinates v are a position, an array
+// of numbers
+func isPosition(v interface{}) bool {
+	a, ok := v.([]interface{})
+	if !ok || len(a) == 0 {
+		return false
+	}
+	_, nested := a[0].([]interface{})
+	return !nested
+}
+
+// convert converts generic JSON coordinates to the Go type of coordinates
+// nested n deep, adding the text of json.Numbers to numbers when it isn't nil
+func convert(v interface{}, n int, numbers numberTexts) (interface{}, error) {
+	if v == nil {
+		return reflect.Zero(coordinatesType(n)).Interface(), nil
+	}
+	a, ok := v.([]interface{})
+	if !ok {
+		return nil, &json.UnmarshalTypeError{Value: jsonTypeName(v), Type: coordinatesType(n), Struct: "Geometry", Field: "coordinates"}
+	}
+
+	if n == 1 {
+		p := make(Position, len(a))
+		var texts []string
+		if numbers != nil {
+			texts = make([]string, len(a))
+		}
+		for i, e := range a {
+			switch e := e.(type) {
+			case nil:
+			case float64:
+				p[i] = e
+			case json.Number:
+				f, err := strconv.ParseFloat(string(e), 64)
+				if err != nil {
+					return nil, &json.UnmarshalTypeError{Value: "number " + string(e), Type: float64Type, Struct: "Geometry", Field: "coordinates"}
+				}
+				p[i] = f
+				if texts != nil {
+					texts[i] = string(e)
+				}
+			default:
+				return nil, &json.UnmarshalTypeError{Value: jsonTypeName(e), Type: float64Type, Struct: "Geometry", Field: "coordinates"}
+			}
+		}
+		if len(texts) > 0 {
+			numbers[&p[0]] = texts
+		}
+		return p, nil
+	}
+
+	items := reflect.MakeSlice(coordinatesType(n), len(a), len(a))
+	for i, e := range a {
+		c, err := convert(e, n-1, numbers)
+		if err != nil {
+			return nil, err
+		}
+		items.Index(i).Set(reflect.ValueOf(c))
+	}
+	return items.Interface(), nil
+}
+
+// coordinatesType returns the Go type of coordinates nested n deep
+func coordinatesType(n int) reflect.Type {
+	switch n {
+	case 1:
+		return reflect.TypeFor[Position]()
+	case 2:
+		return reflect.TypeFor[Positions]()
+	case 3:
+		return reflect.TypeFor[[]Positions]()
+	}
+	return reflect.TypeFor[[][]Positions]()
+}
+
+// jsonTypeName returns the name encoding/json gives the kind of a generic
+// JSON value in its errors
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	}
+	return "number"
+}
+
+// setGeometry fills in g from the members of a geometry object
+func (g *Geometry) setGeometry(m *members) error {
+	g.Object = m.Object
+	g.numbers = nil
+
+	n := depth(m.Type)
+	if n == 0 && m.Type != "GeometryCollection" {
+		return ErrInvalidGeometry
+	}
+	if n == 0 {
+		if !m.hasGeometries {
+			return fmt.Errorf("%w: GeometryCollection without geometries", ErrInvalidGeometry)
+		}
+		g.GeometryCollection = &GeometryCollection{Object: g.Object, Geometries: m.geometries}
+		return nil
+	}
+	if !m.hasCoordinates {
+		return fmt.Errorf("%w: %s without coordinates", ErrInvalidGeometry, m.Type)
+	}
+
+	c := m.coordinates
+	if reflect.TypeOf(c) != coordinatesType(n) {
+		var err error
+		if c, err = convert(c, n, m.numbers); err != nil {
+			return err
+		}
+	}
+
+	g.numbers = m.numbers
+
+	switch m.Type {
+	case "Point":
+		g.Point = &Point{Object: g.Object, Coordinates: c.(Position)}
+	case "MultiPoint":
+		g.MultiPoint = &MultiPoint{Object: g.Object, Coordinates: c.(Positions)}
+	case "LineString":
+		g.LineString = &LineString{Object: g.Object, Coordinates: c.(Positions)}
+	case "MultiLineString":
+		g.MultiLineString = &MultiLineString{Object: g.Object, Coordinates: c.([]Positions)}
+	case "Polygon":
+		g.Polygon = &Polygon{Object: g.Object, Coordinates: c.([]Positions)}
+	case "MultiPolygon":
+		g.MultiPolygon = &MultiPolygon{Object: g.Object, Coordinates: c.([][]Positions)}
+	}
+	return nil
+}
+
+// set fills in f from the members of a feature object
+func (f *Feature) set(m *members) error {
+	f.Object = m.Object
+	f.ID = m.id
+	f.Geometry = m.geometry
+	switch p := m.properties.(type) {
+	case nil:
+		f.Properties = nil
+	case Properties:
+		f.Properties = p
+	case map[string]interface{}:
+		f.Properties = Properties(p)
+	default:
+		return &json.UnmarshalTypeError{Value: jsonTypeName(p), Type: propertiesType, Struct: "Feature", Field: "properties"}
+	}
+	return nil
+}
+
+// UnmarshalJSON will unmarshal a Feature in a single pass over its JSON
+func (f *Feature) UnmarshalJSON(b []byte) error {
+	return f.unmarshal(b, defaultDecodeOptions)
+}
+
+func (f *Feature) unmarshal(b []byte, o *DecodeOptions) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return nil
+	}
+	return unmarshal(b, kindFeature, o, f.set)
+}
+
+// UnmarshalJSON will unmarshal a FeatureCollection in a single pass over its
+// JSON
+func (f *FeatureCollection) UnmarshalJSON(b []byte) error {
+	return f.unmarshal(b, defaultDecodeOptions)
+}
+
+func (f *FeatureCollection) unmarshal(b []byte, o *DecodeOptions) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return nil
+	}
+	return unmarshal(b, kindFeatureCollection, o, func(m *members) error {
+		f.Object = m.Object
+		f.Features = m.features
+		return nil
+	})
+}
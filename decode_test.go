@@ -0,0 +1,421 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTypeAnywhere(t *testing.T) {
+	// Success with the type after the coordinates
+	var g Geometry
+	if err := json.Unmarshal([]byte(`{"coordinates": [[1, 2], [3, 4]], "bbox": [1, 2, 3, 4], "type": "LineString"}`), &g); err != nil {
+		t.Fatal(err)
+	}
+	expected := &LineString{Object: Object{Type: "LineString", BoundingBox: &BoundingBox{1, 2, 3, 4}}, Coordinates: Positions{{1, 2}, {3, 4}}}
+	if !reflect.DeepEqual(g.LineString, expected) {
+		t.Errorf("expected %v but got %v", expected, g.LineString)
+	}
+
+	// Success with the type of a Feature last
+	var j GeoJSON
+	if err := json.Unmarshal([]byte(`{
+		"properties": {"name": "a", "tags": [1, "b"]},
+		"geometry": {"coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]]], "type": "MultiPolygon"},
+		"id": 7,
+		"type": "Feature"
+	}`), &j); err != nil {
+		t.Fatal(err)
+	}
+	if j.Feature == nil || j.Type != "Feature" {
+		t.Fatalf("expected a Feature but got %+v", j)
+	}
+	if j.Feature.ID != 7.0 {
+		t.Errorf("expected %v but got %v", 7.0, j.Feature.ID)
+	}
+	if p := (Properties{"name": "a", "tags": []interface{}{1.0, "b"}}); !reflect.DeepEqual(j.Feature.Properties, p) {
+		t.Errorf("expected %v but got %v", p, j.Feature.Properties)
+	}
+	if c := [][]Positions{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}; j.Feature.Geometry.MultiPolygon == nil || !reflect.DeepEqual(j.Feature.Geometry.MultiPolygon.Coordinates, c) {
+		t.Errorf("expected %v but got %+v", c, j.Feature.Geometry)
+	}
+
+	// Success with the type of a GeometryCollection in GeoJSON last
+	j = GeoJSON{}
+	if err := json.Unmarshal([]byte(`{"geometries": [{"coordinates": [1, 2], "type": "Point"}], "type": "GeometryCollection"}`), &j); err != nil {
+		t.Fatal(err)
+	}
+	if j.Geometry == nil || j.Geometry.GeometryCollection == nil || j.Geometry.GeometryCollection.Geometries[0].Point == nil {
+		t.Errorf("expected a GeometryCollection of a Point but got %+v", j.Geometry)
+	}
+}
+
+func TestDecodeMembers(t *testing.T) {
+	// Success skipping foreign members, even those of other objects
+	var f Feature
+	if err := json.Unmarshal([]byte(`{
+		"type": "Feature",
+		"coordinates": "not a geometry",
+		"features": {},
+		"title": {"nested": [true, null, "x\"y"]},
+		"geometry": {"type": "Point", "coordinates": [1, 2], "properties": 3, "id": []},
+		"properties": null
+	}`), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Geometry == nil || f.Geometry.Point == nil || f.Properties != nil {
+		t.Errorf("expected a Point without properties but got %+v", f)
+	}
+
+	// Success matching members regardless of case as encoding/json does
+	f = Feature{}
+	if err := json.Unmarshal([]byte(`{"Type": "Feature", "ID": "a", "Properties": {"k\u00e9y": "v\n"}}`), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Type != "Feature" || f.ID != "a" || f.Properties["kéy"] != "v\n" {
+		t.Errorf("expected a Feature of ID %q but got %+v", "a", f)
+	}
+
+	// Success with null members
+	var fc FeatureCollection
+	if err := json.Unmarshal([]byte(`{"type": "FeatureCollection", "bbox": null, "crs": null, "features": [{"type": "Feature", "geometry": null}]}`), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].Geometry != nil || fc.BoundingBox != nil || fc.CRS != nil {
+		t.Errorf("expected a Feature without a geometry but got %+v", fc)
+	}
+
+	// Success with a CRS
+	var g Geometry
+	if err := json.Unmarshal([]byte(`{"type": "Point", "coordinates": [1, 2], "crs": {"type": "name", "properties": {"name": "urn:ogc:def:crs:OGC:1.3:CRS84"}}}`), &g); err != nil {
+		t.Fatal(err)
+	}
+	if g.CRS == nil || g.CRS.Name == nil || g.CRS.Name.Name != "urn:ogc:def:crs:OGC:1.3:CRS84" || g.Point.CRS != g.CRS {
+		t.Errorf("expected a named CRS but got %+v", g.CRS)
+	}
+
+	// Fail on a geometry of null
+	if err := json.Unmarshal([]byte(`{"type": "GeometryCollection", "geometries": [null]}`), &g); !errors.Is(err, ErrInvalidGeometry) {
+		t.Errorf("expected %q but got %v", ErrInvalidGeometry, err)
+	}
+
+	// Fail on geometries without coordinates or of an unknown type
+	for _, s := range []string{`{"type": "Point"}`, `{"type": "GeometryCollection"}`, `{"type": "Circle", "coordinates": [1, 2]}`} {
+		if err := json.Unmarshal([]byte(s), &g); !errors.Is(err, ErrInvalidGeometry) {
+			t.Errorf("expected %q for %s but got %v", ErrInvalidGeometry, s, err)
+		}
+	}
+
+	// Success skipping foreign members named as members of other types
+	for _, s := range []string{
+		`{"geometry": 5, "type": "Point", "coordinates": [1, 2]}`,
+		`{"geometries": 5, "type": "Feature", "geometry": null, "properties": {}}`,
+		`{"features": 5, "geometries": [{"type": "Point", "coordinates": [1, 2]}], "type": "GeometryCollection"}`,
+	} {
+		var g GeoJSON
+		if err := json.Unmarshal([]byte(s), &g); err != nil {
+			t.Errorf("unexpected error for %s: %v", s, err)
+		}
+	}
+	var p Geometry
+	if err := json.Unmarshal([]byte(`{"geometries": 5, "type": "Point", "coordinates": [1, 2]}`), &p); err != nil || p.Point == nil {
+		t.Errorf("expected a Point but got %v", err)
+	}
+
+	// Fail on an unknown CRS
+	if err := json.Unmarshal([]byte(`{"type": "Point", "coordinates": [1, 2], "crs": {"type": "epsg"}}`), &g); !errors.Is(err, ErrInvalidCRS) {
+		t.Errorf("expected %q but got %v", ErrInvalidCRS, err)
+	}
+
+	// Fail on members of the wrong type as encoding/json does
+	var typeErr *json.UnmarshalTypeError
+	for _, s := range []string{`{"type": "Point", "coordinates": ["1", 2]}`, `{"coordinates": [[1, 2]], "type": "Point"}`, `{"type": 1}`, `[]`} {
+		if err := json.Unmarshal([]byte(s), &g); !errors.As(err, &typeErr) {
+			t.Errorf("expected a type error for %s but got %v", s, err)
+		}
+	}
+
+	// Fail with the Go struct field of the member
+	for s, expected := range map[string]string{
+		`{"type": "Point", "coordinates": "1, 2"}`:      "Geometry.coordinates",
+		`{"coordinates": "1, 2", "type": "Point"}`:      "Geometry.coordinates",
+		`{"type": "Feature", "properties": 5}`:          "Feature.properties",
+		`{"properties": 5, "type": "Feature"}`:          "Feature.properties",
+		`{"type": "FeatureCollection", "features": {}}`: "FeatureCollection.features",
+	} {
+		var g GeoJSON
+		if err := json.Unmarshal([]byte(s), &g); !errors.As(err, &typeErr) || !strings.Contains(err.Error(), "Go struct field "+expected+" ") {
+			t.Errorf("expected a type error of %s for %s but got %v", expected, s, err)
+		}
+	}
+}
+
+func TestDecodeSyntaxError(t *testing.T) {
+	// Fail with the errors of encoding/json on malformed JSON
+	for _, s := range []string{
+		`{"type": "Point", "coordinates": [1, 2]`,
+		`{"type": "Point", "coordinates": [1, 2],}`,
+		`{"type": "Point", "coordinates": [1, 2]} x`,
+		`{"type": "Point", "coordinates": [1., 2]}`,
+		`{"type": "Point" "coordinates": [1, 2]}`,
+		`{"type": "Feature", "properties": {"a": tru}}`,
+		`{"type": "Point", "coordinates": [1, 2], "x": "\q"}`,
+	} {
+		var expected interface{}
+		jerr := json.Unmarshal([]byte(s), &expected)
+		var g GeoJSON
+		if err := g.UnmarshalJSON([]byte(s)); err == nil || err.Error() != jerr.Error() {
+			t.Errorf("expected %q for %s but got %v", jerr, s, err)
+		}
+	}
+}
+
+func TestDecodeExactNumbers(t *testing.T) {
+	// Success re-encoding the same digits
+	for _, s := range []string{
+		`{"type":"Feature","id":123456789012345678901234,"geometry":{"type":"Point","coordinates":[0.10,1e2,-0.0]},"properties":{"area":1.50,"code":9007199254740993}}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"LineString","bbox":[1,2,3,4.5],"coordinates":[[1.0,2.000],[3E1,4.5e-1]]}]},"properties":null}]}`,
+		`{"type":"Polygon","coordinates":[[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,0.0]]]}`,
+	} {
+		var g GeoJSON
+		if err := (DecodeOptions{ExactNumbers: true}).Unmarshal([]byte(s), &g); err != nil {
+			t.Errorf("unexpected error for %s: %v", s, err)
+			continue
+		}
+		b, err := json.Marshal(g)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", s, err)
+		} else if string(b) != s {
+			t.Errorf("expected %q but got %q", s, b)
+		}
+	}
+
+	// Success formatting changed coordinates as usual
+	var g Geometry
+	if err := (DecodeOptions{ExactNumbers: true}).Unmarshal([]byte(`{"type":"Point","coordinates":[1.0,2.0]}`), &g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Point.Coordinates[1] = 3
+	if b, _ := json.Marshal(g); string(b) != `{"type":"Point","coordinates":[1.0,3]}` {
+		t.Errorf("expected %q but got %q", `{"type":"Point","coordinates":[1.0,3]}`, b)
+	}
+
+	// Success keeping the text of positions after one is inserted
+	g = Geometry{}
+	if err := (DecodeOptions{ExactNumbers: true}).Unmarshal([]byte(`{"type":"LineString","coordinates":[[1.0,2.0],[3.00,4.0],[5.0,6.50]]}`), &g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ps := g.LineString.Coordinates
+	g.LineString.Coordinates = append(Positions{ps[0], {2, 3}}, ps[1:]...)
+	if b, _ := json.Marshal(g); string(b) != `{"type":"LineString","coordinates":[[1.0,2.0],[2,3],[3.00,4.0],[5.0,6.50]]}` {
+		t.Errorf("expected %q but got %q", `{"type":"LineString","coordinates":[[1.0,2.0],[2,3],[3.00,4.0],[5.0,6.50]]}`, b)
+	}
+
+	// Success decoding float64 numbers without the option
+	var f Feature
+	if err := json.Unmarshal([]byte(`{"type":"Feature","id":1.0,"geometry":{"type":"Point","coordinates":[1.0,2.0]},"properties":{"a":1.0}}`), &f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := json.Marshal(f); string(b) != `{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[1,2]},"properties":{"a":1}}` {
+		t.Errorf("expected numbers formatted as float64 but got %q", b)
+	}
+
+	// Fail on types that aren't GeoJSON
+	var p Point
+	if err := (DecodeOptions{}).Unmarshal([]byte(`{}`), &p); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected %q but got %q", ErrUnsupportedType, err)
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	nested := strings.Repeat(`{"type":"GeometryCollection","geometries":[`, 10) + strings.Repeat(`]}`, 10)
+	fc := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2],[3,4],[5,6]]},"properties":{"name":"a"}},` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"b"}}]}`
+	generic := `{"coordinates":[[[1,2],[3,4],[5,6],[1,2]]],"type":"Polygon"}`
+
+	// Success within the limits
+	o := DecodeOptions{MaxBytes: len(fc), MaxDepth: 6, MaxPositions: 4, MaxRingPositions: 3, MaxFeatures: 2, MaxPropertyBytes: 12}
+	var f FeatureCollection
+	if err := o.Unmarshal([]byte(fc), &f); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var g Geometry
+	if err := (DecodeOptions{MaxDepth: 20}).Unmarshal([]byte(nested), &g); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Fail with a LimitError
+	for _, test := range []struct {
+		o     DecodeOptions
+		s     string
+		limit string
+	}{
+		{DecodeOptions{MaxBytes: 10}, fc, "MaxBytes"},
+		{DecodeOptions{MaxDepth: 5}, fc, "MaxDepth"},
+		{DecodeOptions{MaxDepth: 19}, nested, "MaxDepth"},
+		{DecodeOptions{MaxPositions: 3}, fc, "MaxPositions"},
+		{DecodeOptions{MaxPositions: 3}, generic, "MaxPositions"},
+		{DecodeOptions{MaxRingPositions: 2}, fc, "MaxRingPositions"},
+		{DecodeOptions{MaxRingPositions: 3}, generic, "MaxRingPositions"},
+		{DecodeOptions{MaxFeatures: 1}, fc, "MaxFeatures"},
+		{DecodeOptions{MaxPropertyBytes: 11}, fc, "MaxPropertyBytes"},
+		{DecodeOptions{MaxPropertyBytes: 11}, `{"properties":{"name":"a"},"type":"Feature","geometry":null}`, "MaxPropertyBytes"},
+	} {
+		var g GeoJSON
+		err := test.o.Unmarshal([]byte(test.s), &g)
+		var lerr *LimitError
+		if !errors.As(err, &lerr) || lerr.Limit != test.limit {
+			t.Errorf("expected %q but got %v", test.limit, err)
+		}
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("expected %q but got %v", ErrLimitExceeded, err)
+		}
+	}
+
+	// Fail on nesting deeper than encoding/json allows without MaxDepth
+	deep := `{"type":"Feature","geometry":null,"properties":{"a":` + strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000) + `}}`
+	var lerr *LimitError
+	if err := (DecodeOptions{}).Unmarshal([]byte(deep), &f); !errors.As(err, &lerr) || lerr.Max != maxDepth {
+		t.Errorf("expected MaxDepth of %d but got %v", maxDepth, err)
+	}
+
+	// Fail reading past MaxBytes
+	r := &countingReader{r: strings.NewReader(fc)}
+	if err := (DecodeOptions{MaxBytes: 10}).Decode(r, &f); !errors.As(err, &lerr) || lerr.Limit != "MaxBytes" {
+		t.Errorf("expected %q but got %v", "MaxBytes", err)
+	}
+	if r.n > 11 {
+		t.Errorf("expected at most 11 bytes read but got %d", r.n)
+	}
+
+	// Success decoding from a reader
+	if err := (DecodeOptions{MaxBytes: len(fc)}).Decode(strings.NewReader(fc), &f); err != nil || len(f.Features) != 2 {
+		t.Errorf("expected 2 features but got %d, %v", len(f.Features), err)
+	}
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestDecodeError(t *testing.T) {
+	// Fail with where the invalid object is
+	for _, test := range []struct {
+		s      string
+		err    error
+		path   string
+		typ    string
+		offset int64
+		line   int
+		column int
+	}{
+		{`{"type": "Pointy", "coordinates": [1, 2]}`, ErrInvalidGeoJSON, "", "Pointy", 0, 1, 1},
+		{"{\"type\": \"FeatureCollection\", \"features\": [\n  {\"type\": \"Feature\", \"geometry\": null, \"properties\": null},\n  {\"type\": \"Feature\", \"geometry\": {\"type\": \"Polygon\"}, \"properties\": null}\n]}", ErrInvalidGeometry, "features[1].geometry", "Polygon", 139, 3, 35},
+		{"{\"type\": \"Feature\", \"geometry\": {\"type\": \"Polygone\"}, \"properties\": null}", ErrInvalidGeometry, "geometry", "Polygone", 32, 1, 33},
+		{`{"type": "GeometryCollection", "geometries": [{"type": "Point", "coordinates": [1, 2]}, {"type": "Pointy"}]}`, ErrInvalidGeometry, "geometries[1]", "Pointy", 88, 1, 89},
+		{`{"type": "GeometryCollection", "name": "ü", "geometries": [{"type": "Pointy"}]}`, ErrInvalidGeometry, "geometries[0]", "Pointy", 60, 1, 60},
+		{`{"type": "GeometryCollection", "geometries": [null]}`, ErrInvalidGeometry, "geometries[0]", "", 46, 1, 47},
+		{`{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2], "crs": {"type": "epsg"}}, "properties": null}`, ErrInvalidCRS, "geometry.crs", "epsg", 80, 1, 81},
+	} {
+		var g GeoJSON
+		err := json.Unmarshal([]byte(test.s), &g)
+		if !errors.Is(err, test.err) {
+			t.Errorf("expected %q but got %v", test.err, err)
+		}
+		var derr *DecodeError
+		if !errors.As(err, &derr) {
+			t.Errorf("expected a DecodeError for %s but got %v", test.s, err)
+			continue
+		}
+		if derr.Path != test.path || derr.Type != test.typ {
+			t.Errorf("expected %q of type %q but got %q of type %q", test.path, test.typ, derr.Path, derr.Type)
+		}
+		if derr.Offset != test.offset || derr.Line != test.line || derr.Column != test.column {
+			t.Errorf("expected offset %d at %d:%d but got offset %d at %d:%d", test.offset, test.line, test.column, derr.Offset, derr.Line, derr.Column)
+		}
+	}
+
+	// Success leaving errors of the JSON as encoding/json returns them
+	var g Geometry
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal([]byte(`{"type": "Point", "coordinates": "1, 2"}`), &g); !errors.As(err, &typeErr) {
+		t.Errorf("expected a type error but got %v", err)
+	}
+}
+
+// benchmarkCollection returns a FeatureCollection of n features with
+// polygons, points in geometry collections and a few properties
+func benchmarkCollection(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"type":"FeatureCollection","features":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		x, y := float64(i%360)-180, float64(i%180)-90
+		if i%2 == 0 {
+			fmt.Fprintf(&b, `{"type":"Feature","id":%d,"geometry":{"type":"Polygon","coordinates":[[`, i)
+			for j := 0; j < 32; j++ {
+				fmt.Fprintf(&b, "[%.6f,%.6f],", x+float64(j)*0.001, y+float64(j%7)*0.001)
+			}
+			fmt.Fprintf(&b, "[%.6f,%.6f]]]}", x, y)
+		} else {
+			fmt.Fprintf(&b, `{"type":"Feature","id":"f%d","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[%.6f,%.6f]},{"type":"LineString","coordinates":[[%.6f,%.6f],[%.6f,%.6f]]}]}`, i, x, y, x, y, y, x)
+		}
+		fmt.Fprintf(&b, `,"properties":{"name":"Feature %d","class":"road","lanes":%d,"oneway":%t,"tags":["a","b"]}}`, i, i%4, i%3 == 0)
+	}
+	b.WriteString(`]}`)
+	return []byte(b.String())
+}
+
+func BenchmarkUnmarshalFeatureCollection(b *testing.B) {
+	data := benchmarkCollection(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var fc FeatureCollection
+		if err := json.Unmarshal(data, &fc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalGeoJSON(b *testing.B) {
+	data := benchmarkCollection(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var g GeoJSON
+		if err := json.Unmarshal(data, &g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalGeometry(b *testing.B) {
+	data := []byte(`{"type":"GeometryCollection","geometries":[{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]]]}]},{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}]}`)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var g Geometry
+		if err := json.Unmarshal(data, &g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,795 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNonFinite happens when encoding a NaN or infinite number, which JSON
+	// can't represent
+	ErrNonFinite = errors.New("number must be finite")
+	// ErrUnsupportedType happens when encoding a value with EncodeOptions
+	// that isn't one of the GeoJSON types
+	ErrUnsupportedType = errors.New("value isn't a GeoJSON type")
+)
+
+// EncodeOptions control how the numbers of coordinates and bounding boxes are
+// written. The zero value writes the same JSON as json.Marshal.
+type EncodeOptions struct {
+	// Precision is the maximum number of decimal places of each axis, such
+	// as []int{6, 6, 2} for 6 places of longitude and latitude and 2 of
+	// altitude. The last applies to any further axes. A negative precision,
+	// or none, keeps as many places as needed to be exact.
+	Precision []int
+	// TrimZeros removes the trailing zeros Precision leaves, writing
+	// 10.000000 as 10
+	TrimZeros bool
+}
+
+// defaultOptions are the options of the AppendJSON methods
+var defaultOptions = &EncodeOptions{}
+
+// encoder appends JSON with the options it embeds
+type encoder struct {
+	*EncodeOptions
+	// numbers are the text of the coordinates of the geometry being
+	// appended, which are used while they hold the same number
+	numbers numberTexts
+}
+
+func (o *EncodeOptions) encoder() *encoder {
+	return &encoder{EncodeOptions: o}
+}
+
+// Marshal returns the JSON of v, one of the GeoJSON types or a pointer to one
+func (o EncodeOptions) Marshal(v interface{}) ([]byte, error) {
+	return o.AppendJSON(nil, v)
+}
+
+// AppendJSON appends the JSON of v, one of the GeoJSON types or a pointer to
+// one, to dst
+func (o EncodeOptions) AppendJSON(dst []byte, v interface{}) ([]byte, error) {
+	e := o.encoder()
+	switch v := v.(type) {
+	case Position:
+		return e.appendPosition(dst, v)
+	case Positions:
+		return e.appendPositions(dst, v)
+	case BoundingBox:
+		return e.appendBoundingBox(dst, v)
+	case *BoundingBox:
+		return e.appendBoundingBox(dst, *v)
+	case GeometryType:
+		return e.appendGeometryType(dst, v)
+	case Point:
+		return e.appendGeometryType(dst, &v)
+	case MultiPoint:
+		return e.appendGeometryType(dst, &v)
+	case LineString:
+		return e.appendGeometryType(dst, &v)
+	case MultiLineString:
+		return e.appendGeometryType(dst, &v)
+	case Polygon:
+		return e.appendGeometryType(dst, &v)
+	case MultiPolygon:
+		return e.appendGeometryType(dst, &v)
+	case GeometryCollection:
+		return e.appendGeometryType(dst, &v)
+	case Geometry:
+		return e.appendGeometry(dst, &v)
+	case *Geometry:
+		return e.appendGeometry(dst, v)
+	case Feature:
+		return e.appendFeature(dst, &v)
+	case *Feature:
+		return e.appendFeature(dst, v)
+	case FeatureCollection:
+		return e.appendFeatureCollection(dst, &v)
+	case *FeatureCollection:
+		return e.appendFeatureCollection(dst, v)
+	case RawFeature:
+		return e.appendRawFeature(dst, &v)
+	case *RawFeature:
+		return e.appendRawFeature(dst, v)
+	case GeoJSON:
+		return e.appendGeoJSON(dst, &v)
+	case *GeoJSON:
+		return e.appendGeoJSON(dst, v)
+	}
+	return nil, fmt.Errorf("%w: got %T", ErrUnsupportedType, v)
+}
+
+// The AppendJSON methods append the same JSON as MarshalJSON to a buffer
+// without going through reflection, which the MarshalJSON methods use in turn.
+
+// AppendJSON appends the JSON of the position to dst
+func (p Position) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendPosition(dst, p)
+}
+
+// AppendJSON appends the JSON of the positions to dst
+func (ps Positions) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendPositions(dst, ps)
+}
+
+// AppendJSON appends the JSON of the bounding box to dst, failing as
+// MarshalJSON does when it has an odd number of values
+func (b BoundingBox) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendBoundingBox(dst, b)
+}
+
+// AppendJSON appends the JSON of the point to dst
+func (p Point) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &p)
+}
+
+// AppendJSON appends the JSON of the points to dst
+func (m MultiPoint) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &m)
+}
+
+// AppendJSON appends the JSON of the line to dst
+func (l LineString) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &l)
+}
+
+// AppendJSON appends the JSON of the lines to dst
+func (m MultiLineString) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &m)
+}
+
+// AppendJSON appends the JSON of the polygon to dst
+func (p Polygon) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &p)
+}
+
+// AppendJSON appends the JSON of the polygons to dst
+func (m MultiPolygon) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &m)
+}
+
+// AppendJSON appends the JSON of the collection to dst
+func (c GeometryCollection) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometryType(dst, &c)
+}
+
+// AppendJSON appends the JSON of the geometry that's filled in to dst, with
+// the type of that geometry and the Object of g
+func (g Geometry) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeometry(dst, &g)
+}
+
+// AppendJSON appends the JSON of the properties to dst, with the keys sorted
+// as encoding/json sorts them
+func (p Properties) AppendJSON(dst []byte) ([]byte, error) {
+	return appendMap(dst, p)
+}
+
+// AppendJSON appends the JSON of the Feature, with its Type, to dst
+func (f Feature) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendFeature(dst, &f)
+}
+
+// AppendJSON appends the JSON of the FeatureCollection, with its Type, to dst
+func (f FeatureCollection) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendFeatureCollection(dst, &f)
+}
+
+// AppendJSON appends the JSON of the RawFeature, with its Type, to dst
+func (f RawFeature) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendRawFeature(dst, &f)
+}
+
+// AppendJSON appends the JSON of the Geometry, Feature or FeatureCollection
+// that's filled in to dst, or null when none is
+func (g GeoJSON) AppendJSON(dst []byte) ([]byte, error) {
+	return defaultOptions.encoder().appendGeoJSON(dst, &g)
+}
+
+// AppendJSON appends the JSON of the CRS to dst, leaving dst as it is when
+// neither Name nor Link is set
+func (c CRS) AppendJSON(dst []byte) ([]byte, error) {
+	switch {
+	case c.Name != nil && c.Link != nil:
+		return nil, ErrMultipleCRSs
+	case c.Name != nil:
+		dst = append(dst, `{"type":"name","properties":{"name":`...)
+		dst = appendString(dst, c.Name.Name)
+		return append(dst, "}}"...), nil
+	case c.Link != nil:
+		if _, err := url.Parse(c.Link.Href); err != nil {
+			return nil, err
+		}
+		dst = append(dst, `{"type":"link","properties":{"href":`...)
+		dst = appendString(dst, c.Link.Href)
+		if c.Link.Type != "" {
+			dst = append(dst, `,"type":`...)
+			dst = appendString(dst, c.Link.Type)
+		}
+		return append(dst, "}}"...), nil
+	}
+	return dst, nil
+}
+
+// precision returns the number of decimal places of the axis or -1 for as
+// many as needed
+func (e *encoder) precision(axis int) int {
+	if n := len(e.Precision); n > 0 {
+		return e.Precision[min(axis, n-1)]
+	}
+	return -1
+}
+
+// appendCoordinate appends the number f of the axis of a position or
+// bounding box to dst, as text when it's the decoded text of f
+func (e *encoder) appendCoordinate(dst []byte, f float64, axis int, text string) ([]byte, error) {
+	p := e.precision(axis)
+	if text != "" {
+		if exact, err := strconv.ParseFloat(text, 64); p < 0 && err == nil && exact == f {
+			return append(dst, text...), nil
+		}
+	}
+	if p < 0 {
+		return appendFloat(dst, f, 64)
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, &nonFiniteError{value: f}
+	}
+
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'f', p, 64)
+	if e.TrimZeros && p > 0 {
+		dst = bytes.TrimRight(dst, "0")
+		dst = bytes.TrimSuffix(dst, []byte("."))
+	}
+	// a number rounded to zero has no sign
+	if dst[start] == '-' && !bytes.ContainsAny(dst[start:], "123456789") {
+		dst = append(dst[:start], dst[start+1:]...)
+	}
+	return dst, nil
+}
+
+func (e *encoder) appendPosition(dst []byte, p Position) ([]byte, error) {
+	if p == nil {
+		return append(dst, "null"...), nil
+	}
+	var texts []string
+	if len(p) > 0 {
+		texts = e.numbers[&p[0]]
+	}
+	dst = append(dst, '[')
+	for i, f := range p {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var text string
+		if i < len(texts) {
+			text = texts[i]
+		}
+		var err error
+		if dst, err = e.appendCoordinate(dst, f, i, text); err != nil {
+			return nil, at(err, index(i))
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+func (e *encoder) appendPositions(dst []byte, ps Positions) ([]byte, error) {
+	if ps == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i, p := range ps {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = e.appendPosition(dst, p); err != nil {
+			return nil, at(err, index(i))
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendRings appends the JSON of the lines or rings of a polygon to dst
+func (e *encoder) appendRings(dst []byte, rings []Positions) ([]byte, error) {
+	if rings == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i, ps := range rings {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = e.appendPositions(dst, ps); err != nil {
+			return nil, at(err, index(i))
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendPolygons appends the JSON of the polygons of a MultiPolygon to dst
+func (e *encoder) appendPolygons(dst []byte, polygons [][]Positions) ([]byte, error) {
+	if polygons == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i, rings := range polygons {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = e.appendRings(dst, rings); err != nil {
+			return nil, at(err, index(i))
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendBoundingBox appends the minimums and maximums of a bounding box with
+// the precision of their axes
+func (e *encoder) appendBoundingBox(dst []byte, b BoundingBox) ([]byte, error) {
+	if len(b)%2 != 0 {
+		return nil, ErrOddBoundingBox
+	}
+	if b == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i, f := range b {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = e.appendCoordinate(dst, f, i%(len(b)/2), ""); err != nil {
+			return nil, at(err, index(i))
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendObject appends the opening brace and the members of obj to dst
+func (e *encoder) appendObject(dst []byte, obj *Object) ([]byte, error) {
+	dst = append(dst, `{"type":`...)
+	dst = appendString(dst, obj.Type)
+
+	var err error
+	if obj.BoundingBox != nil {
+		dst = append(dst, `,"bbox":`...)
+		if dst, err = e.appendBoundingBox(dst, *obj.BoundingBox); err != nil {
+			return nil, at(err, ".bbox")
+		}
+	}
+	if obj.CRS != nil {
+		dst = append(dst, `,"crs":`...)
+		n := len(dst)
+		if dst, err = obj.CRS.AppendJSON(dst); err != nil {
+			return nil, err
+		}
+		if len(dst) == n {
+			return nil, fmt.Errorf("%w: neither a name nor a link", ErrInvalidCRS)
+		}
+	}
+	return dst, nil
+}
+
+// appendGeometryType appends a geometry with its own Object to dst
+func (e *encoder) appendGeometryType(dst []byte, t GeometryType) ([]byte, error) {
+	var obj *Object
+	switch t := t.(type) {
+	case *Point:
+		obj = &t.Object
+	case *MultiPoint:
+		obj = &t.Object
+	case *LineString:
+		obj = &t.Object
+	case *MultiLineString:
+		obj = &t.Object
+	case *Polygon:
+		obj = &t.Object
+	case *MultiPolygon:
+		obj = &t.Object
+	case *GeometryCollection:
+		obj = &t.Object
+	}
+	return e.appendTyped(dst, obj, t, nil)
+}
+
+// appendTyped appends the geometry t with the members of obj to dst, and
+// the text of numbers for its coordinates where they still hold
+func (e *encoder) appendTyped(dst []byte, obj *Object, t GeometryType, numbers numberTexts) ([]byte, error) {
+	dst, err := e.appendObject(dst, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := t.(*GeometryCollection); ok {
+		dst = append(dst, `,"geometries":`...)
+		if dst, err = e.appendGeometries(dst, c.Geometries); err != nil {
+			return nil, at(err, ".geometries")
+		}
+		return append(dst, '}'), nil
+	}
+
+	dst = append(dst, `,"coordinates":`...)
+	e.numbers = numbers
+	switch t := t.(type) {
+	case *Point:
+		dst, err = e.appendPosition(dst, t.Coordinates)
+	case *MultiPoint:
+		dst, err = e.appendPositions(dst, t.Coordinates)
+	case *LineString:
+		dst, err = e.appendPositions(dst, t.Coordinates)
+	case *MultiLineString:
+		dst, err = e.appendRings(dst, t.Coordinates)
+	case *Polygon:
+		dst, err = e.appendRings(dst, t.Coordinates)
+	case *MultiPolygon:
+		dst, err = e.appendPolygons(dst, t.Coordinates)
+	}
+	e.numbers = nil
+	if err != nil {
+		return nil, at(err, ".coordinates")
+	}
+	return append(dst, '}'), nil
+}
+
+func (e *encoder) appendGeometries(dst []byte, gs []Geometry) ([]byte, error) {
+	if gs == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i := range gs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = e.appendGeometry(dst, &gs[i]); err != nil {
+			return nil, at(err, index(i))
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendGeometry appends the geometry that's filled in with the type of that
+// geometry and the Object of g
+func (e *encoder) appendGeometry(dst []byte, g *Geometry) ([]byte, error) {
+	n := 0
+	for _, set := range []bool{g.Point != nil, g.MultiPoint != nil, g.LineString != nil, g.MultiLineString != nil, g.Polygon != nil, g.MultiPolygon != nil, g.GeometryCollection != nil} {
+		if set {
+			n++
+		}
+	}
+	// Exactly one geometry must be specified
+	if n == 0 {
+		return nil, ErrNoGeometry
+	} else if n >= 2 {
+		return nil, ErrMultipleGeometries
+	}
+
+	t := g.Value()
+	obj := g.Object
+	obj.Type = t.GeoJSONType()
+	return e.appendTyped(dst, &obj, t, g.numbers)
+}
+
+func appendMap(dst []byte, m map[string]interface{}) ([]byte, error) {
+	if m == nil {
+		return append(dst, "null"...), nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	dst = append(dst, '{')
+	for i, k := range keys {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendString(dst, k)
+		dst = append(dst, ':')
+		var err error
+		if dst, err = appendValue(dst, m[k]); err != nil {
+			return nil, at(err, member(k))
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+func (e *encoder) appendFeature(dst []byte, f *Feature) ([]byte, error) {
+	obj := f.Object
+	obj.Type = "Feature"
+	dst, err := e.appendObject(dst, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if dst, err = e.appendFeatureMembers(dst, f.ID, f.Geometry); err != nil {
+		return nil, err
+	}
+	dst = append(dst, `,"properties":`...)
+	if dst, err = appendMap(dst, f.Properties); err != nil {
+		return nil, at(err, ".properties")
+	}
+	return append(dst, '}'), nil
+}
+
+// appendFeatureMembers appends the id and geometry members of a feature
+func (e *encoder) appendFeatureMembers(dst []byte, id interface{}, g *Geometry) ([]byte, error) {
+	var err error
+	if id != nil {
+		dst = append(dst, `,"id":`...)
+		if dst, err = appendValue(dst, id); err != nil {
+			return nil, at(err, ".id")
+		}
+	}
+	dst = append(dst, `,"geometry":`...)
+	if g == nil {
+		return append(dst, "null"...), nil
+	}
+	if dst, err = e.appendGeometry(dst, g); err != nil {
+		return nil, at(err, ".geometry")
+	}
+	return dst, nil
+}
+
+func (e *encoder) appendFeatureCollection(dst []byte, f *FeatureCollection) ([]byte, error) {
+	obj := f.Object
+	obj.Type = "FeatureCollection"
+	dst, err := e.appendObject(dst, &obj)
+	if err != nil {
+		return nil, err
+	}
+	dst = append(dst, `,"features":`...)
+	if f.Features == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, '[')
+		for i := range f.Features {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = e.appendFeature(dst, &f.Features[i]); err != nil {
+				return nil, at(at(err, index(i)), ".features")
+			}
+		}
+		dst = append(dst, ']')
+	}
+	return append(dst, '}'), nil
+}
+
+func (e *encoder) appendRawFeature(dst []byte, f *RawFeature) ([]byte, error) {
+	obj := f.Object
+	obj.Type = "Feature"
+	dst, err := e.appendObject(dst, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if dst, err = e.appendFeatureMembers(dst, f.ID, f.Geometry); err != nil {
+		return nil, err
+	}
+	dst = append(dst, `,"properties":`...)
+	if len(f.Properties) == 0 {
+		dst = append(dst, "null"...)
+	} else {
+		// encoding/json compacts and validates raw JSON
+		b, err := json.Marshal(f.Properties)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, b...)
+	}
+	return append(dst, '}'), nil
+}
+
+func (e *encoder) appendGeoJSON(dst []byte, g *GeoJSON) ([]byte, error) {
+	if g.Geometry != nil {
+		return e.appendGeometry(dst, g.Geometry)
+	}
+	if g.Feature != nil {
+		return e.appendFeature(dst, g.Feature)
+	}
+	if g.FeatureCollection != nil {
+		return e.appendFeatureCollection(dst, g.FeatureCollection)
+	}
+	return append(dst, "null"...), nil
+}
+
+// nonFiniteError is ErrNonFinite with the path of the number, which is built
+// up as the error is returned through the members and arrays holding it
+type nonFiniteError struct {
+	// path are the segments of the path from the number up
+	path  []string
+	value float64
+}
+
+func (e *nonFiniteError) Error() string {
+	var b strings.Builder
+	for i := len(e.path) - 1; i >= 0; i-- {
+		b.WriteString(e.path[i])
+	}
+	return fmt.Sprintf("%v: %s is %v", ErrNonFinite, strings.TrimPrefix(b.String(), "."), e.value)
+}
+
+func (e *nonFiniteError) Unwrap() error { return ErrNonFinite }
+
+// at adds the segment of the member or index holding the number of a
+// nonFiniteError or the object of a DecodeError to its path
+func at(err error, segment string) error {
+	switch e := err.(type) {
+	case *nonFiniteError:
+		e.path = append(e.path, segment)
+	case *DecodeError:
+		e.path = append(e.path, segment)
+	}
+	return err
+}
+
+func index(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// member returns the path segment of the member key, quoted as property
+// paths quote keys
+func member(key string) string {
+	return pathSegment{key: key, index: -1}.String()
+}
+
+// appendValue appends the JSON of a property or ID to dst, falling back to
+// encoding/json for types other than those of decoded JSON and numbers
+func appendValue(dst []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case string:
+		return appendString(dst, v), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case float64:
+		return appendFloat(dst, v, 64)
+	case float32:
+		return appendFloat(dst, float64(v), 32)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case int8:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), nil
+	case uint:
+		return strconv.AppendUint(dst, uint64(v), 10), nil
+	case uint8:
+		return strconv.AppendUint(dst, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(dst, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(dst, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(dst, v, 10), nil
+	case json.Number:
+		if v == "" {
+			return append(dst, '0'), nil
+		}
+		d := decoder{data: []byte(v)}
+		if d.skipNumber() == nil && d.off == len(d.data) {
+			return append(dst, v...), nil
+		}
+	case map[string]interface{}:
+		return appendMap(dst, v)
+	case Properties:
+		return appendMap(dst, v)
+	case []interface{}:
+		if v == nil {
+			return append(dst, "null"...), nil
+		}
+		dst = append(dst, '[')
+		for i, e := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			var err error
+			if dst, err = appendValue(dst, e); err != nil {
+				return nil, at(err, index(i))
+			}
+		}
+		return append(dst, ']'), nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, b...), nil
+}
+
+// appendFloat appends f as encoding/json formats a float of the bit size to
+// dst: without an exponent unless it's tiny or huge
+func appendFloat(dst []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, &nonFiniteError{value: f}
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst, nil
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends s quoted as encoding/json quotes it to dst, escaping
+// HTML and replacing invalid UTF-8
+func appendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '\\', '"':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,216 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAppendJSONValues(t *testing.T) {
+	// Success formatting floats as encoding/json does
+	for _, f := range []float64{0, 1, -1, 0.1, 1e-6, 9.99e-7, -1e-7, 1e20, 1e21, 123456789.125, math.MaxFloat64, math.SmallestNonzeroFloat64, 1.5e-300} {
+		expected, _ := json.Marshal(f)
+		if b, err := appendFloat(nil, f, 64); err != nil || string(b) != string(expected) {
+			t.Errorf("expected %q but got %q, %v", expected, b, err)
+		}
+		if math.Abs(f) > math.MaxFloat32 {
+			continue
+		}
+		expected, _ = json.Marshal(float32(f))
+		if b, err := appendFloat(nil, float64(float32(f)), 32); err != nil || string(b) != string(expected) {
+			t.Errorf("expected %q but got %q, %v", expected, b, err)
+		}
+	}
+
+	// Success quoting strings as encoding/json does
+	for _, s := range []string{"", "plain", "<a href=\"x\">&</a>", "\x00\x1f\b\f\n\r\t\\", "caf\u00e9 \u2028\u2029", "bad \xff\xfe utf-8", "\x7f"} {
+		expected, _ := json.Marshal(s)
+		if b := appendString(nil, s); string(b) != string(expected) {
+			t.Errorf("expected %q but got %q", expected, b)
+		}
+	}
+
+	// Success with properties as encoding/json marshals a map
+	p := map[string]interface{}{
+		"z":      []interface{}{nil, true, "<", 1.5, float32(0.1), int8(-1), uint64(math.MaxUint64)},
+		"a":      map[string]interface{}{"b": json.Number("12345678901234567890"), "c": json.Number("")},
+		"time":   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		"nested": Properties{"x": nil},
+	}
+	expected, _ := json.Marshal(p)
+	if b, err := Properties(p).AppendJSON([]byte("prefix ")); err != nil || string(b) != "prefix "+string(expected) {
+		t.Errorf("expected %q but got %q, %v", "prefix "+string(expected), b, err)
+	}
+
+	// Fail on values JSON can't represent
+	if _, err := appendValue(nil, math.Inf(1)); err == nil {
+		t.Error("expected an error but got nil")
+	}
+	if _, err := appendValue(nil, json.Number("1x")); err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	bbox := BoundingBox{-180, -90, 180, 90}
+	crs := &CRS{Link: &CRSLink{Href: "data.crs"}}
+
+	// Success appending every type
+	for _, test := range []struct {
+		v        interface{ AppendJSON([]byte) ([]byte, error) }
+		expected string
+	}{
+		{Position{1, 2.5}, `[1,2.5]`},
+		{Positions{{1, 2}, nil}, `[[1,2],null]`},
+		{bbox, `[-180,-90,180,90]`},
+		{CRS{Name: &CRSName{Name: "urn:ogc:def:crs:OGC:1.3:CRS84"}}, `{"type":"name","properties":{"name":"urn:ogc:def:crs:OGC:1.3:CRS84"}}`},
+		{Point{Object: Object{Type: "Point", CRS: crs}, Coordinates: Position{1, 2}}, `{"type":"Point","crs":{"type":"link","properties":{"href":"data.crs"}},"coordinates":[1,2]}`},
+		{MultiPoint{Object: Object{Type: "MultiPoint"}}, `{"type":"MultiPoint","coordinates":null}`},
+		{LineString{Object: Object{Type: "LineString"}, Coordinates: Positions{{1, 2}, {3, 4}}}, `{"type":"LineString","coordinates":[[1,2],[3,4]]}`},
+		{MultiLineString{Object: Object{Type: "MultiLineString"}, Coordinates: []Positions{{{1, 2}, {3, 4}}}}, `{"type":"MultiLineString","coordinates":[[[1,2],[3,4]]]}`},
+		{Polygon{Object: Object{Type: "Polygon", BoundingBox: &bbox}, Coordinates: []Positions{{{0, 0}, {1, 0}, {0, 0}}}}, `{"type":"Polygon","bbox":[-180,-90,180,90],"coordinates":[[[0,0],[1,0],[0,0]]]}`},
+		{MultiPolygon{Object: Object{Type: "MultiPolygon"}, Coordinates: [][]Positions{{{{0, 0}, {1, 0}, {0, 0}}}}}, `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,0]]]]}`},
+		{GeometryCollection{Object: Object{Type: "GeometryCollection"}, Geometries: []Geometry{{Point: &Point{Coordinates: Position{1, 2}}}}}, `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]}`},
+		{Geometry{Object: Object{BoundingBox: &bbox}, Point: &Point{Coordinates: Position{1e-7, 1e21}}}, `{"type":"Point","bbox":[-180,-90,180,90],"coordinates":[1e-7,1e+21]}`},
+		{Feature{ID: 1, Properties: Properties{"b": "<", "a": 1}}, `{"type":"Feature","id":1,"geometry":null,"properties":{"a":1,"b":"\u003c"}}`},
+		{FeatureCollection{Features: []Feature{{}}}, `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null}]}`},
+		{FeatureCollection{}, `{"type":"FeatureCollection","features":null}`},
+		{RawFeature{ID: json.Number("9007199254740993"), Properties: json.RawMessage(` { "a" : [1, 2] } `)}, `{"type":"Feature","id":9007199254740993,"geometry":null,"properties":{"a":[1,2]}}`},
+		{GeoJSON{}, `null`},
+		{GeoJSON{Feature: &Feature{}}, `{"type":"Feature","geometry":null,"properties":null}`},
+	} {
+		if b, err := test.v.AppendJSON(nil); err != nil || string(b) != test.expected {
+			t.Errorf("expected %q but got %q, %v", test.expected, b, err)
+		}
+		if b, err := json.Marshal(test.v); err != nil || string(b) != test.expected {
+			t.Errorf("expected %q but got %q, %v", test.expected, b, err)
+		}
+	}
+
+	// Fail on invalid members
+	if _, err := (BoundingBox{1, 2, 3}).AppendJSON(nil); err != ErrOddBoundingBox {
+		t.Errorf("expected '%v' but got '%v'", ErrOddBoundingBox, err)
+	}
+	if _, err := (Geometry{}).AppendJSON(nil); err != ErrNoGeometry {
+		t.Errorf("expected '%v' but got '%v'", ErrNoGeometry, err)
+	}
+	if _, err := (Feature{Object: Object{CRS: &CRS{}}}).AppendJSON(nil); !errors.Is(err, ErrInvalidCRS) {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidCRS, err)
+	}
+	if _, err := (Point{Coordinates: Position{math.NaN()}}).AppendJSON(nil); err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
+
+func BenchmarkMarshalFeatureCollection(b *testing.B) {
+	var fc FeatureCollection
+	if err := json.Unmarshal(benchmarkCollection(1000), &fc); err != nil {
+		b.Fatal(err)
+	}
+	data, err := json.Marshal(fc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(fc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalGeometry(b *testing.B) {
+	var g Geometry
+	if err := json.Unmarshal([]byte(`{"type":"MultiPolygon","coordinates":[[[[102.0,2.0],[103.0,2.0],[103.0,3.0],[102.0,3.0],[102.0,2.0]]],[[[100.0,0.0],[101.0,0.0],[101.0,1.0],[100.0,1.0],[100.0,0.0]],[[100.2,0.2],[100.8,0.2],[100.8,0.8],[100.2,0.8],[100.2,0.2]]]]}`), &g); err != nil {
+		b.Fatal(err)
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendJSONFeatureCollection(b *testing.B) {
+	var fc FeatureCollection
+	if err := json.Unmarshal(benchmarkCollection(1000), &fc); err != nil {
+		b.Fatal(err)
+	}
+	data, err := fc.AppendJSON(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if data, err = fc.AppendJSON(data[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEncodeOptions(t *testing.T) {
+	bbox := BoundingBox{1.23456, 2.5, 100.125, 3.0000001, 4, 200.5}
+	f := Feature{
+		Object:   Object{BoundingBox: &bbox},
+		Geometry: &Geometry{Point: &Point{Coordinates: Position{10.000000000000002, -0.0000001, 100.126}}},
+	}
+
+	// Success with the same JSON as json.Marshal without options
+	expected, _ := json.Marshal(f)
+	if b, err := (EncodeOptions{}).Marshal(&f); err != nil || string(b) != string(expected) {
+		t.Errorf("expected %q but got %q, %v", expected, b, err)
+	}
+
+	// Success rounding each axis
+	o := EncodeOptions{Precision: []int{6, 6, 2}}
+	expected = []byte(`{"type":"Feature","bbox":[1.234560,2.500000,100.12,3.000000,4.000000,200.50],"geometry":{"type":"Point","coordinates":[10.000000,0.000000,100.13]},"properties":null}`)
+	if b, err := o.Marshal(f); err != nil || string(b) != string(expected) {
+		t.Errorf("expected %q but got %q, %v", expected, b, err)
+	}
+
+	// Success trimming zeros, with the last precision for further axes
+	o = EncodeOptions{Precision: []int{1}, TrimZeros: true}
+	expected = []byte(`[[10,0,100.1],[0.5,-1.2]]`)
+	if b, err := o.AppendJSON(nil, Positions{{10.000000000000002, -0.0000001, 100.126}, {0.5, -1.24}}); err != nil || string(b) != string(expected) {
+		t.Errorf("expected %q but got %q, %v", expected, b, err)
+	}
+
+	// Success keeping full precision for negative precisions
+	o = EncodeOptions{Precision: []int{0, -1}}
+	expected = []byte(`{"type":"LineString","coordinates":[[2,10.000000000000002]]}`)
+	if b, err := o.Marshal(&LineString{Object: Object{Type: "LineString"}, Coordinates: Positions{{1.5, 10.000000000000002}}}); err != nil || string(b) != string(expected) {
+		t.Errorf("expected %q but got %q, %v", expected, b, err)
+	}
+
+	// Fail on non-finite numbers naming their path
+	fc := FeatureCollection{Features: []Feature{{}, {Geometry: &Geometry{Polygon: &Polygon{Coordinates: []Positions{{{0, 0}, {1, math.Inf(-1)}}}}}}}}
+	expectedErr := "number must be finite: features[1].geometry.coordinates[0][1][1] is -Inf"
+	for _, o := range []EncodeOptions{{}, {Precision: []int{6}}} {
+		if _, err := o.Marshal(fc); !errors.Is(err, ErrNonFinite) || err.Error() != expectedErr {
+			t.Errorf("expected %q but got %v", expectedErr, err)
+		}
+	}
+	f = Feature{Properties: Properties{"a.b": []interface{}{math.NaN()}}}
+	expectedErr = `number must be finite: properties["a.b"][0] is NaN`
+	if _, err := f.AppendJSON(nil); !errors.Is(err, ErrNonFinite) || err.Error() != expectedErr {
+		t.Errorf("expected %q but got %v", expectedErr, err)
+	}
+
+	// Fail on other types
+	if _, err := (EncodeOptions{}).Marshal(Properties{}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected '%v' but got '%v'", ErrUnsupportedType, err)
+	}
+}
@@ -1,6 +1,9 @@
 package geojson
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+)
 
 // Properties are a general way to specify a free-form JSON object
 type Properties map[string]interface{}
@@ -28,30 +31,92 @@ type FeatureCollection struct {
 
 // MarshalJSON will correctly marshal a Feature (with Type) into JSON
 func (f Feature) MarshalJSON() ([]byte, error) {
-	f.Type = "Feature"
-	// anonymous struct so we don't recurse
-	return json.Marshal(struct {
-		Object
-		ID         interface{} `json:"id,omitempty"`
-		Geometry   *Geometry   `json:"geometry"`
-		Properties Properties  `json:"properties"`
-	}{
-		Object:     f.Object,
-		ID:         f.ID,
-		Geometry:   f.Geometry,
-		Properties: f.Properties,
-	})
+	return f.AppendJSON(nil)
 }
 
 // MarshalJSON will correctly marshal a FeatureCollection (with Type) into JSON
 func (f FeatureCollection) MarshalJSON() ([]byte, error) {
-	f.Type = "FeatureCollection"
-	// anonymous struct so we don't recurse
-	return json.Marshal(struct {
+	return f.AppendJSON(nil)
+}
+
+// RawFeature is a Feature whose properties are kept as the raw JSON, to be
+// decoded into a Go type with DecodeProperties or json.Unmarshal. A numeric
+// ID is unmarshaled as a json.Number so large integers stay exact.
+type RawFeature struct {
+	// Object is the common GeoJSON object
+	Object
+	// ID is the optional commonly used identifier for the Feature
+	ID interface{} `json:"id,omitempty"`
+	// Geometry represents a GeoJSON Geometry object with one of the Geometry
+	// types filled in
+	Geometry *Geometry `json:"geometry"`
+	// Properties is the JSON of the properties of the Feature
+	Properties json.RawMessage `json:"properties"`
+}
+
+// MarshalJSON will correctly marshal a RawFeature (with Type) into JSON
+func (f RawFeature) MarshalJSON() ([]byte, error) {
+	return f.AppendJSON(nil)
+}
+
+// UnmarshalJSON will unmarshal a RawFeature keeping the JSON of its
+// properties
+func (f *RawFeature) UnmarshalJSON(b []byte) error {
+	var v struct {
 		Object
-		Features []Feature `json:"features"`
-	}{
-		Object:   f.Object,
-		Features: f.Features,
-	})
+		ID         json.RawMessage `json:"id"`
+		Geometry   *Geometry       `json:"geometry"`
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	f.Object = v.Object
+	f.Geometry = v.Geometry
+	f.Properties = v.Properties
+	f.ID = nil
+	if len(v.ID) > 0 {
+		d := json.NewDecoder(bytes.NewReader(v.ID))
+		d.UseNumber()
+		if err := d.Decode(&f.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeProperties unmarshals the properties of the Feature into v, such as a
+// pointer to a struct with json tags
+func (f *RawFeature) DecodeProperties(v interface{}) error {
+	if len(f.Properties) == 0 {
+		return nil
+	}
+	return json.Unmarshal(f.Properties, v)
+}
+
+// DecodeProperties decodes the properties of the Feature into v, such as a
+// pointer to a struct, the way encoding/json would unmarshal them
+func (f *Feature) DecodeProperties(v interface{}) error {
+	b, err := json.Marshal(f.Properties)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// EncodeProperties replaces the properties of the Feature with v, such as a
+// struct, encoded the way encoding/json would marshal it. Numbers are float64
+// unless that would lose precision, in which case they're a json.Number.
+func (f *Feature) EncodeProperties(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p, err := decodeProperties(b)
+	if err != nil {
+		return err
+	}
+	f.Properties = p
+	return nil
 }
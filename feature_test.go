@@ -1,6 +1,8 @@
 package geojson
 
 import (
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -50,3 +52,97 @@ func equalFeatureCollections(f1, f2 *FeatureCollection, t *testing.T) {
 		}
 	}
 }
+
+type testAddress struct {
+	Street string `json:"street"`
+	Zip    string `json:"zip,omitempty"`
+}
+
+type testRoad struct {
+	testAddress
+	ID    int64    `json:"road_id"`
+	Lanes int      `json:"lanes"`
+	Width float64  `json:"width,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+	Skip  string   `json:"-"`
+}
+
+func TestFeatureProperties(t *testing.T) {
+	road := testRoad{
+		testAddress: testAddress{Street: "Main Street"},
+		ID:          9007199254740993,
+		Lanes:       2,
+		Tags:        []string{"paved"},
+		Skip:        "skipped",
+	}
+
+	// Success encoding a struct with tags, omitempty and an embedded struct
+	var f Feature
+	if err := f.EncodeProperties(road); err != nil {
+		t.Fatal(err)
+	}
+	expected := Properties{
+		"street":  "Main Street",
+		"road_id": json.Number("9007199254740993"),
+		"lanes":   2.0,
+		"tags":    []interface{}{"paved"},
+	}
+	if !reflect.DeepEqual(f.Properties, expected) {
+		t.Errorf("expected %#v but got %#v", expected, f.Properties)
+	}
+
+	// Success decoding back into the struct
+	var decoded testRoad
+	if err := f.DecodeProperties(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	road.Skip = ""
+	if !reflect.DeepEqual(decoded, road) {
+		t.Errorf("expected %#v but got %#v", road, decoded)
+	}
+
+	// Fail encoding a value that isn't an object
+	if err := f.EncodeProperties([]int{1}); !errors.Is(err, ErrInvalidProperties) {
+		t.Errorf("expected %q but got %v", ErrInvalidProperties, err)
+	}
+
+	// Fail decoding properties of the wrong type
+	f.Properties = Properties{"lanes": "two"}
+	if err := f.DecodeProperties(&decoded); err == nil {
+		t.Error("expected an error decoding a string into an int")
+	}
+}
+
+func TestRawFeature(t *testing.T) {
+	const j = `{"type":"Feature","id":9007199254740993,"geometry":null,"properties":{"road_id":9007199254740993,"lanes":2,"street":"Main Street"}}`
+
+	// Success keeping the properties and ID exact
+	var f RawFeature
+	if err := json.Unmarshal([]byte(j), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.ID != json.Number("9007199254740993") {
+		t.Errorf("expected %q but got %v", "9007199254740993", f.ID)
+	}
+	var road testRoad
+	if err := f.DecodeProperties(&road); err != nil {
+		t.Fatal(err)
+	}
+	if road.ID != 9007199254740993 || road.Street != "Main Street" || road.Lanes != 2 {
+		t.Errorf("expected the road properties but got %#v", road)
+	}
+
+	// Success marshaling back to the same JSON
+	if b, err := json.Marshal(f); err != nil {
+		t.Error(err)
+	} else if string(b) != j {
+		t.Errorf("expected %q but got %q", j, b)
+	}
+
+	// Success marshaling missing properties as null
+	if b, err := json.Marshal(RawFeature{}); err != nil {
+		t.Error(err)
+	} else if string(b) != `{"type":"Feature","geometry":null,"properties":null}` {
+		t.Errorf("expected null properties but got %q", b)
+	}
+}
@@ -5,7 +5,7 @@
 package geojson
 
 import (
-	"encoding/json"
+	"bytes"
 	"errors"
 )
 
@@ -40,47 +40,36 @@ type GeoJSON struct {
 // upon which type is filled in: Geometry, Feature, or FeatureCollection. This will
 // marshal to a null JSON value if all the above types are nil.
 func (g GeoJSON) MarshalJSON() ([]byte, error) {
-	if g.Geometry != nil {
-		return json.Marshal(g.Geometry)
-	}
-	if g.Feature != nil {
-		return json.Marshal(g.Feature)
-	}
-	if g.FeatureCollection != nil {
-		return json.Marshal(g.FeatureCollection)
-	}
-
-	return []byte("null"), nil
+	return g.AppendJSON(nil)
 }
 
 // UnmarshalJSON will take a GeoJSON string and, based on the type, fill in the
-// appropriate GeoJSON object type.
+// appropriate GeoJSON object type. The JSON is only read once, whether the type
+// comes before or after the other members.
 func (g *GeoJSON) UnmarshalJSON(b []byte) error {
-	// Minimum amount to unmarshal on first pass
-	var t struct {
-		Type string `json:"type"`
-	}
+	return g.unmarshal(b, defaultDecodeOptions)
+}
 
-	if err := json.Unmarshal(b, &t); err != nil {
-		return err
+func (g *GeoJSON) unmarshal(b []byte, o *DecodeOptions) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return ErrInvalidGeoJSON
 	}
 
-	g.Type = t.Type
+	return unmarshal(b, kindAny, o, func(m *members) error {
+		g.Type = m.Type
 
-	switch t.Type {
-	case "Point", "MultiPoint",
-		"LineString", "MultiLineString",
-		"Polygon", "MultiPolygon",
-		"GeometryCollection":
-		g.Geometry = new(Geometry)
-		return json.Unmarshal(b, g.Geometry)
-	case "Feature":
-		g.Feature = new(Feature)
-		return json.Unmarshal(b, g.Feature)
-	case "FeatureCollection":
-		g.FeatureCollection = new(FeatureCollection)
-		return json.Unmarshal(b, g.FeatureCollection)
-	}
+		switch kindOf(m.Type) {
+		case kindGeometry:
+			g.Geometry = new(Geometry)
+			return g.Geometry.setGeometry(m)
+		case kindFeature:
+			g.Feature = new(Feature)
+			return g.Feature.set(m)
+		case kindFeatureCollection:
+			g.FeatureCollection = &FeatureCollection{Object: m.Object, Features: m.features}
+			return nil
+		}
 
-	return ErrInvalidGeoJSON
+		return ErrInvalidGeoJSON
+	})
 }
@@ -2,6 +2,7 @@ package geojson
 
 import (
 	"encoding/json"
+	"errors"
 	"regexp"
 	"testing"
 )
@@ -300,7 +301,7 @@ func TestUnmarshalRealGeoJSON(t *testing.T) {
 		}`, ""))
 
 	g = GeoJSON{}
-	if err := json.Unmarshal(j, &g); err != ErrInvalidGeoJSON {
+	if err := json.Unmarshal(j, &g); !errors.Is(err, ErrInvalidGeoJSON) {
 		t.Errorf("expected '%v' but got '%v'", ErrInvalidGeoJSON, err)
 	}
 }
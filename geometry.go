@@ -1,7 +1,7 @@
 package geojson
 
 import (
-	"encoding/json"
+	"bytes"
 	"errors"
 )
 
@@ -86,18 +86,11 @@ type GeometryCollection struct {
 	Geometries []Geometry `json:"geometries"`
 }
 
-type rawGeometry struct {
-	Object
-	Coordinates json.RawMessage `json:"coordinates"`
-	Geometries  json.RawMessage `json:"geometries"`
-}
-
 // Geometry is the top-level object that will appropriately marshal & unmarshal into
 // GeoJSON
 type Geometry struct {
 	// Object is the common GeoJSON object properties
 	Object
-	rawGeometry
 	// Point if set, represents a GeoJSON Point geometry object
 	Point *Point `json:",omitempty"`
 	// MultiPoint if set, represents a GeoJSON MultiPoint geometry object
@@ -112,111 +105,54 @@ type Geometry struct {
 	MultiPolygon *MultiPolygon `json:",omitempty"`
 	// GeometryCollection if set, represents a GeoJSON GeometryCollection geometry object
 	GeometryCollection *GeometryCollection `json:",omitempty"`
+
+	// numbers are the text of the numbers of the coordinates when decoded
+	// with ExactNumbers
+	numbers numberTexts
 }
 
-func (g *Geometry) setGeometry() error {
-	var d interface{}
-	var r json.RawMessage
-
-	switch g.Type {
-	case "Point":
-		g.Point = &Point{Object: g.Object}
-		d, r = &g.Point.Coordinates, g.Coordinates
-	case "MultiPoint":
-		g.MultiPoint = &MultiPoint{Object: g.Object}
-		d, r = &g.MultiPoint.Coordinates, g.Coordinates
-	case "LineString":
-		g.LineString = &LineString{Object: g.Object}
-		d, r = &g.LineString.Coordinates, g.Coordinates
-	case "MultiLineString":
-		g.MultiLineString = &MultiLineString{Object: g.Object}
-		d, r = &g.MultiLineString.Coordinates, g.Coordinates
-	case "Polygon":
-		g.Polygon = &Polygon{Object: g.Object}
-		d, r = &g.Polygon.Coordinates, g.Coordinates
-	case "MultiPolygon":
-		g.MultiPolygon = &MultiPolygon{Object: g.Object}
-		d, r = &g.MultiPolygon.Coordinates, g.Coordinates
-	case "GeometryCollection":
-		g.GeometryCollection = &GeometryCollection{Object: g.Object}
-		d, r = &g.GeometryCollection.Geometries, g.Geometries
-	default:
-		return ErrInvalidGeometry
-	}
+// numberTexts are the text of the numbers of decoded positions keyed by the
+// address of their first number, which keeps the text with its position as
+// positions are added to or removed from the coordinates
+type numberTexts map[*float64][]string
 
-	return json.Unmarshal(r, d)
-}
+// MarshalJSON will marshal the Point into JSON
+func (p Point) MarshalJSON() ([]byte, error) { return p.AppendJSON(nil) }
 
-// MarshalJSON will take a general Geometry object and appropriately marshal the
-// object into GeoJSON based on the geometry type that's filled in.
-func (g Geometry) MarshalJSON() ([]byte, error) {
-	type geometry struct {
-		Object
-		Coordinates interface{} `json:"coordinates,omitempty"`
-		Geometries  interface{} `json:"geometries,omitempty"`
-	}
+// MarshalJSON will marshal the MultiPoint into JSON
+func (m MultiPoint) MarshalJSON() ([]byte, error) { return m.AppendJSON(nil) }
 
-	var j geometry
-	i := 0
+// MarshalJSON will marshal the LineString into JSON
+func (l LineString) MarshalJSON() ([]byte, error) { return l.AppendJSON(nil) }
 
-	if g.Point != nil {
-		g.Type = "Point"
-		j = geometry{Object: g.Object, Coordinates: g.Point.Coordinates}
-		i++
-	}
-	if g.MultiPoint != nil {
-		g.Type = "MultiPoint"
-		j = geometry{Object: g.Object, Coordinates: g.MultiPoint.Coordinates}
-		i++
-	}
-	if g.LineString != nil {
-		g.Type = "LineString"
-		j = geometry{Object: g.Object, Coordinates: g.LineString.Coordinates}
-		i++
-	}
-	if g.MultiLineString != nil {
-		g.Type = "MultiLineString"
-		j = geometry{Object: g.Object, Coordinates: g.MultiLineString.Coordinates}
-		i++
-	}
-	if g.Polygon != nil {
-		g.Type = "Polygon"
-		j = geometry{Object: g.Object, Coordinates: g.Polygon.Coordinates}
-		i++
-	}
-	if g.MultiPolygon != nil {
-		g.Type = "MultiPolygon"
-		j = geometry{Object: g.Object, Coordinates: g.MultiPolygon.Coordinates}
-		i++
-	}
-	if g.GeometryCollection != nil {
-		g.Type = "GeometryCollection"
-		j = geometry{Object: g.Object, Geometries: g.GeometryCollection.Geometries}
-		i++
-	}
+// MarshalJSON will marshal the MultiLineString into JSON
+func (m MultiLineString) MarshalJSON() ([]byte, error) { return m.AppendJSON(nil) }
 
-	// Exactly one geometry must be specified
-	if i == 0 {
-		return nil, ErrNoGeometry
-	} else if i >= 2 {
-		return nil, ErrMultipleGeometries
-	}
+// MarshalJSON will marshal the Polygon into JSON
+func (p Polygon) MarshalJSON() ([]byte, error) { return p.AppendJSON(nil) }
+
+// MarshalJSON will marshal the MultiPolygon into JSON
+func (m MultiPolygon) MarshalJSON() ([]byte, error) { return m.AppendJSON(nil) }
+
+// MarshalJSON will marshal the GeometryCollection into JSON
+func (c GeometryCollection) MarshalJSON() ([]byte, error) { return c.AppendJSON(nil) }
 
-	return json.Marshal(j)
+// MarshalJSON will take a general Geometry object and appropriately marshal the
+// object into GeoJSON based on the geometry type that's filled in.
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	return g.AppendJSON(nil)
 }
 
 // UnmarshalJSON will take a geometry GeoJSON string and appropriately fill in the
-// specific geometry type
+// specific geometry type in a single pass, wherever the type member appears
 func (g *Geometry) UnmarshalJSON(b []byte) error {
-	var r rawGeometry
+	return g.unmarshal(b, defaultDecodeOptions)
+}
 
-	err := json.Unmarshal(b, &r)
-	if err != nil {
-		return err
+func (g *Geometry) unmarshal(b []byte, o *DecodeOptions) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return ErrInvalidGeometry
 	}
 
-	g.Object = r.Object
-	g.rawGeometry = r
-
-	return g.setGeometry()
+	return unmarshal(b, kindGeometry, o, g.setGeometry)
 }
@@ -88,124 +88,132 @@ func equalGeometries(g1, g2 *Geometry, t *testing.T) {
 }
 
 func TestSetGeometry(t *testing.T) {
+	// coordinates are decoded generically as they are before the type
+	coordinates := func(t *testing.T, s string) interface{} {
+		var c interface{}
+		if err := json.Unmarshal([]byte(s), &c); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	geometries := func(t *testing.T, s string) []Geometry {
+		var gs []Geometry
+		if err := json.Unmarshal([]byte(s), &gs); err != nil {
+			t.Fatal(err)
+		}
+		return gs
+	}
+
 	// Success for type Point
-	g := Geometry{
+	m := members{
 		Object: Object{
 			Type: "Point",
 		},
-		rawGeometry: rawGeometry{
-			Coordinates: json.RawMessage(`[1.0, 10]`),
-		},
+		hasCoordinates: true,
+		coordinates:    coordinates(t, `[1.0, 10]`),
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Success for type MultiPoint
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "MultiPoint",
 		},
-		rawGeometry: rawGeometry{
-			Coordinates: json.RawMessage(`[[1.0, 10], [10, 1.0]]`),
-		},
+		hasCoordinates: true,
+		coordinates:    coordinates(t, `[[1.0, 10], [10, 1.0]]`),
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Success for type LineString
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "LineString",
 		},
-		rawGeometry: rawGeometry{
-			Coordinates: json.RawMessage(`[[1.0, 10], [10, 1.0]]`),
-		},
+		hasCoordinates: true,
+		coordinates:    coordinates(t, `[[1.0, 10], [10, 1.0]]`),
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Success for type MultiLineString
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "MultiLineString",
 		},
-		rawGeometry: rawGeometry{
-			Coordinates: json.RawMessage(`[
+		hasCoordinates: true,
+		coordinates: coordinates(t, `[
 				[[1.0, 10], [10, 1.0]],
 				[[2.0, 20], [20, 2.0]]
 			]`),
-		},
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Success for type Polygon
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "Polygon",
 		},
-		rawGeometry: rawGeometry{
-			Coordinates: json.RawMessage(`[[
+		hasCoordinates: true,
+		coordinates: coordinates(t, `[[
 				[100.0, 0.0], [101.0, 0.0], 
 				[101.0, 1.0], [100.0, 1.0],
 				[100.0, 0.0]
 			  ]]`),
-		},
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Success for type MultiPolygon
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "MultiPolygon",
 		},
-		rawGeometry: rawGeometry{
-			Coordinates: json.RawMessage(`[
+		hasCoordinates: true,
+		coordinates: coordinates(t, `[
 				[[[102.0, 2.0], [103.0, 2.0], [103.0, 3.0], [102.0, 3.0], [102.0, 2.0]]],
 				[[[100.0, 0.0], [101.0, 0.0], [101.0, 1.0], [100.0, 1.0], [100.0, 0.0]],
 				 [[100.2, 0.2], [100.8, 0.2], [100.8, 0.8], [100.2, 0.8], [100.2, 0.2]]]
 			]`),
-		},
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Success for type GeometryCollection
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "GeometryCollection",
 		},
-		rawGeometry: rawGeometry{
-			Geometries: json.RawMessage(`[{
+		hasGeometries: true,
+		geometries: geometries(t, `[{
 					"type": "Point",
 					"coordinates": [100.0, 0.0]
 				}, {
 					"type": "LineString",
 					"coordinates": [[101.0, 0.0], [102.0, 1.0]]
 				}]`),
-		},
 	}
-	if err := g.setGeometry(); err != nil {
+	if err := new(Geometry).setGeometry(&m); err != nil {
 		t.Errorf("expected nil but got '%v'", err)
 	}
 
 	// Fail on other types
-	g = Geometry{
+	m = members{
 		Object: Object{
 			Type: "geom",
 		},
-		rawGeometry: rawGeometry{
-			Geometries: json.RawMessage(`{}`),
-		},
+		hasGeometries: true,
+		geometries:    []Geometry{},
 	}
-	if err := g.setGeometry(); err != ErrInvalidGeometry {
+	if err := new(Geometry).setGeometry(&m); err != ErrInvalidGeometry {
 		t.Errorf("expected '%v' but got '%v'", ErrInvalidGeometry, err)
 	}
 }
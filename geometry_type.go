@@ -0,0 +1,377 @@
+package geojson
+
+import "reflect"
+
+// GeometryType is one of the GeoJSON geometry types: *Point, *MultiPoint,
+// *LineString, *MultiLineString, *Polygon, *MultiPolygon or
+// *GeometryCollection. It can't be implemented outside of this package, so a
+// type switch over those seven is exhaustive.
+type GeometryType interface {
+	// GeoJSONType returns the GeoJSON type member of the geometry, such as
+	// "Point"
+	GeoJSONType() string
+	// Bounds returns the bounding box of the positions of the geometry with
+	// as many dimensions as its positions have in common, or nil when it has
+	// none
+	Bounds() BoundingBox
+	// Dimension returns the topological dimension of the geometry: 0 for
+	// points, 1 for lines and 2 for polygons. A GeometryCollection has the
+	// largest dimension of its geometries, -1 when it's empty.
+	Dimension() int
+	// NumPositions returns the number of positions of the geometry
+	NumPositions() int
+	// Clone returns a deep copy of the geometry
+	Clone() GeometryType
+
+	// eachPosition calls fn with every position of the geometry
+	eachPosition(fn func(Position))
+}
+
+// Value returns the geometry that's set, or nil when none is
+func (g *Geometry) Value() GeometryType {
+	switch {
+	case g == nil:
+		return nil
+	case g.Point != nil:
+		return g.Point
+	case g.MultiPoint != nil:
+		return g.MultiPoint
+	case g.LineString != nil:
+		return g.LineString
+	case g.MultiLineString != nil:
+		return g.MultiLineString
+	case g.Polygon != nil:
+		return g.Polygon
+	case g.MultiPolygon != nil:
+		return g.MultiPolygon
+	case g.GeometryCollection != nil:
+		return g.GeometryCollection
+	}
+	return nil
+}
+
+// NewGeometry returns a Geometry with t set, or nil when t is nil or a nil
+// pointer
+func NewGeometry(t GeometryType) *Geometry {
+	if t == nil || reflect.ValueOf(t).IsNil() {
+		return nil
+	}
+	g := &Geometry{}
+	switch t := t.(type) {
+	case *Point:
+		g.Point, g.Object = t, t.Object
+	case *MultiPoint:
+		g.MultiPoint, g.Object = t, t.Object
+	case *LineString:
+		g.LineString, g.Object = t, t.Object
+	case *MultiLineString:
+		g.MultiLineString, g.Object = t, t.Object
+	case *Polygon:
+		g.Polygon, g.Object = t, t.Object
+	case *MultiPolygon:
+		g.MultiPolygon, g.Object = t, t.Object
+	case *GeometryCollection:
+		g.GeometryCollection, g.Object = t, t.Object
+	default:
+		return nil
+	}
+	g.Type = t.GeoJSONType()
+	return g
+}
+
+// GeoJSONType returns "Point"
+func (p *Point) GeoJSONType() string { return "Point" }
+
+// GeoJSONType returns "MultiPoint"
+func (m *MultiPoint) GeoJSONType() string { return "MultiPoint" }
+
+// GeoJSONType returns "LineString"
+func (l *LineString) GeoJSONType() string { return "LineString" }
+
+// GeoJSONType returns "MultiLineString"
+func (m *MultiLineString) GeoJSONType() string { return "MultiLineString" }
+
+// GeoJSONType returns "Polygon"
+func (p *Polygon) GeoJSONType() string { return "Polygon" }
+
+// GeoJSONType returns "MultiPolygon"
+func (m *MultiPolygon) GeoJSONType() string { return "MultiPolygon" }
+
+// GeoJSONType returns "GeometryCollection"
+func (c *GeometryCollection) GeoJSONType() string { return "GeometryCollection" }
+
+// Dimension returns 0
+func (p *Point) Dimension() int { return 0 }
+
+// Dimension returns 0
+func (m *MultiPoint) Dimension() int { return 0 }
+
+// Dimension returns 1
+func (l *LineString) Dimension() int { return 1 }
+
+// Dimension returns 1
+func (m *MultiLineString) Dimension() int { return 1 }
+
+// Dimension returns 2
+func (p *Polygon) Dimension() int { return 2 }
+
+// Dimension returns 2
+func (m *MultiPolygon) Dimension() int { return 2 }
+
+// Dimension returns the largest dimension of the geometries, -1 when there
+// are none
+func (c *GeometryCollection) Dimension() int {
+	d := -1
+	for i := range c.Geometries {
+		if v := c.Geometries[i].Value(); v != nil {
+			d = max(d, v.Dimension())
+		}
+	}
+	return d
+}
+
+func (p *Point) eachPosition(fn func(Position)) {
+	if p.Coordinates != nil {
+		fn(p.Coordinates)
+	}
+}
+
+func (m *MultiPoint) eachPosition(fn func(Position)) {
+	eachPositions(m.Coordinates, fn)
+}
+
+func (l *LineString) eachPosition(fn func(Position)) {
+	eachPositions(l.Coordinates, fn)
+}
+
+func (m *MultiLineString) eachPosition(fn func(Position)) {
+	for _, ps := range m.Coordinates {
+		eachPositions(ps, fn)
+	}
+}
+
+func (p *Polygon) eachPosition(fn func(Position)) {
+	for _, ps := range p.Coordinates {
+		eachPositions(ps, fn)
+	}
+}
+
+func (m *MultiPolygon) eachPosition(fn func(Position)) {
+	for _, rings := range m.Coordinates {
+		for _, ps := range rings {
+			eachPositions(ps, fn)
+		}
+	}
+}
+
+func (c *GeometryCollection) eachPosition(fn func(Position)) {
+	for i := range c.Geometries {
+		if v := c.Geometries[i].Value(); v != nil {
+			v.eachPosition(fn)
+		}
+	}
+}
+
+func eachPositions(ps Positions, fn func(Position)) {
+	for _, p := range ps {
+		fn(p)
+	}
+}
+
+// NumPositions returns 1, or 0 without coordinates
+func (p *Point) NumPositions() int { return numPositions(p) }
+
+// NumPositions returns the number of points
+func (m *MultiPoint) NumPositions() int { return numPositions(m) }
+
+// NumPositions returns the number of positions of the line
+func (l *LineString) NumPositions() int { return numPositions(l) }
+
+// NumPositions returns the number of positions of every line
+func (m *MultiLineString) NumPositions() int { return numPositions(m) }
+
+// NumPositions returns the number of positions of every ring
+func (p *Polygon) NumPositions() int { return numPositions(p) }
+
+// NumPositions returns the number of positions of every polygon
+func (m *MultiPolygon) NumPositions() int { return numPositions(m) }
+
+// NumPositions returns the number of positions of every geometry
+func (c *GeometryCollection) NumPositions() int { return numPositions(c) }
+
+func numPositions(t GeometryType) int {
+	n := 0
+	t.eachPosition(func(Position) { n++ })
+	return n
+}
+
+// Bounds returns the bounding box of the point
+func (p *Point) Bounds() BoundingBox { return bounds(p) }
+
+// Bounds returns the bounding box of the points
+func (m *MultiPoint) Bounds() BoundingBox { return bounds(m) }
+
+// Bounds returns the bounding box of the line
+func (l *LineString) Bounds() BoundingBox { return bounds(l) }
+
+// Bounds returns the bounding box of the lines
+func (m *MultiLineString) Bounds() BoundingBox { return bounds(m) }
+
+// Bounds returns the bounding box of the polygon
+func (p *Polygon) Bounds() BoundingBox { return bounds(p) }
+
+// Bounds returns the bounding box of the polygons
+func (m *MultiPolygon) Bounds() BoundingBox { return bounds(m) }
+
+// Bounds returns the bounding box of the geometries
+func (c *GeometryCollection) Bounds() BoundingBox { return bounds(c) }
+
+// bounds returns the minimums followed by the maximums of the positions of t
+func bounds(t GeometryType) BoundingBox {
+	var low, high []float64
+	t.eachPosition(func(p Position) {
+		if low == nil {
+			low = append([]float64{}, p...)
+			high = append([]float64{}, p...)
+			return
+		}
+		n := min(len(low), len(p))
+		low, high = low[:n], high[:n]
+		for i := range n {
+			low[i] = min(low[i], p[i])
+			high[i] = max(high[i], p[i])
+		}
+	})
+	if len(low) == 0 {
+		return nil
+	}
+	return append(BoundingBox(low), high...)
+}
+
+// Clone returns a deep copy of the point
+func (p *Point) Clone() GeometryType {
+	return &Point{Object: p.Object.clone(), Coordinates: p.Coordinates.clone()}
+}
+
+// Clone returns a deep copy of the points
+func (m *MultiPoint) Clone() GeometryType {
+	return &MultiPoint{Object: m.Object.clone(), Coordinates: m.Coordinates.clone()}
+}
+
+// Clone returns a deep copy of the line
+func (l *LineString) Clone() GeometryType {
+	return &LineString{Object: l.Object.clone(), Coordinates: l.Coordinates.clone()}
+}
+
+// Clone returns a deep copy of the lines
+func (m *MultiLineString) Clone() GeometryType {
+	return &MultiLineString{Object: m.Object.clone(), Coordinates: cloneRings(m.Coordinates)}
+}
+
+// Clone returns a deep copy of the polygon
+func (p *Polygon) Clone() GeometryType {
+	return &Polygon{Object: p.Object.clone(), Coordinates: cloneRings(p.Coordinates)}
+}
+
+// Clone returns a deep copy of the polygons
+func (m *MultiPolygon) Clone() GeometryType {
+	c := &MultiPolygon{Object: m.Object.clone()}
+	if m.Coordinates != nil {
+		c.Coordinates = make([][]Positions, len(m.Coordinates))
+		for i, rings := range m.Coordinates {
+			c.Coordinates[i] = cloneRings(rings)
+		}
+	}
+	return c
+}
+
+// Clone returns a deep copy of the collection and its geometries
+func (c *GeometryCollection) Clone() GeometryType {
+	clone := &GeometryCollection{Object: c.Object.clone()}
+	if c.Geometries != nil {
+		clone.Geometries = make([]Geometry, len(c.Geometries))
+		for i := range c.Geometries {
+			g := &c.Geometries[i]
+			clone.Geometries[i].Object = g.Object.clone()
+			if v := g.Value(); v != nil {
+				n := NewGeometry(v.Clone())
+				n.Object = clone.Geometries[i].Object
+				clone.Geometries[i] = *n
+			}
+		}
+	}
+	return clone
+}
+
+func (p Position) clone() Position {
+	if p == nil {
+		return nil
+	}
+	return append(Position{}, p...)
+}
+
+func (ps Positions) clone() Positions {
+	if ps == nil {
+		return nil
+	}
+	c := make(Positions, len(ps))
+	for i, p := range ps {
+		c[i] = p.clone()
+	}
+	return c
+}
+
+func cloneRings(rings []Positions) []Positions {
+	if rings == nil {
+		return nil
+	}
+	c := make([]Positions, len(rings))
+	for i, ps := range rings {
+		c[i] = ps.clone()
+	}
+	return c
+}
+
+// clone returns a copy of o that shares nothing with it
+func (o Object) clone() Object {
+	if o.BoundingBox != nil {
+		b := append(BoundingBox{}, *o.BoundingBox...)
+		o.BoundingBox = &b
+	}
+	if o.CRS != nil {
+		c := *o.CRS
+		c.Properties = append([]byte(nil), c.Properties...)
+		if c.Name != nil {
+			n := *c.Name
+			c.Name = &n
+		}
+		if c.Link != nil {
+			l := *c.Link
+			c.Link = &l
+		}
+		o.CRS = &c
+	}
+	return o
+}
+
+// ContainsPosition reports whether pos is inside the polygon using the
+// even-odd rule, so positions in its holes aren't. Positions on its rings may
+// be either inside or outside. Positions with fewer than 2 axes are outside.
+func (p *Polygon) ContainsPosition(pos Position) bool {
+	if len(pos) < 2 {
+		return false
+	}
+	inside := false
+	for _, r := range p.Coordinates {
+		for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+			a, c := r[i], r[j]
+			if len(a) < 2 || len(c) < 2 {
+				continue
+			}
+			if (a[1] > pos[1]) != (c[1] > pos[1]) && pos[0] < (c[0]-a[0])*(pos[1]-a[1])/(c[1]-a[1])+a[0] {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
@@ -0,0 +1,130 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGeometryType(t *testing.T) {
+	for j, expected := range map[string]struct {
+		typ          string
+		bounds       BoundingBox
+		dimension    int
+		numPositions int
+	}{
+		`{"type":"Point","coordinates":[1,2,3]}`:                                                                                              {"Point", BoundingBox{1, 2, 3, 1, 2, 3}, 0, 1},
+		`{"type":"MultiPoint","coordinates":[[1,2,3],[-1,5]]}`:                                                                                {"MultiPoint", BoundingBox{-1, 2, 1, 5}, 0, 2},
+		`{"type":"LineString","coordinates":[[0,0],[2,1]]}`:                                                                                   {"LineString", BoundingBox{0, 0, 2, 1}, 1, 2},
+		`{"type":"MultiLineString","coordinates":[[[0,0],[2,1]],[[5,5],[6,6]]]}`:                                                              {"MultiLineString", BoundingBox{0, 0, 6, 6}, 1, 4},
+		`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,0]]]}`:                                                                        {"Polygon", BoundingBox{0, 0, 4, 4}, 2, 4},
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[4,0],[4,4],[0,0]]],[[[9,9],[9,8],[8,8],[9,9]]]]}`:                                     {"MultiPolygon", BoundingBox{0, 0, 9, 9}, 2, 8},
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[0,0],[2,3]]}]}`: {"GeometryCollection", BoundingBox{0, 0, 2, 3}, 1, 3},
+		`{"type":"GeometryCollection","geometries":[]}`:                                                                                       {"GeometryCollection", nil, -1, 0},
+	} {
+		var g Geometry
+		if err := json.Unmarshal([]byte(j), &g); err != nil {
+			t.Fatal(err)
+		}
+
+		// Success describing every type
+		v := g.Value()
+		if v == nil {
+			t.Errorf("expected a value for %s", j)
+			continue
+		}
+		if v.GeoJSONType() != expected.typ {
+			t.Errorf("expected %q but got %q", expected.typ, v.GeoJSONType())
+		}
+		if b := v.Bounds(); !reflect.DeepEqual(b, expected.bounds) {
+			t.Errorf("expected bounds %v for %s but got %v", expected.bounds, j, b)
+		}
+		if d := v.Dimension(); d != expected.dimension {
+			t.Errorf("expected dimension %v for %s but got %v", expected.dimension, j, d)
+		}
+		if n := v.NumPositions(); n != expected.numPositions {
+			t.Errorf("expected %v positions for %s but got %v", expected.numPositions, j, n)
+		}
+
+		// Success round tripping through NewGeometry and a Clone
+		b, err := json.Marshal(NewGeometry(v.Clone()))
+		if err != nil {
+			t.Error(err)
+		} else if string(b) != j {
+			t.Errorf("expected %s but got %s", j, b)
+		}
+	}
+}
+
+func TestGeometryTypeClone(t *testing.T) {
+	bbox := BoundingBox{0, 0, 1, 1}
+	p := &Polygon{Object: Object{BoundingBox: &bbox, CRS: &CRS{Name: &CRSName{"urn:ogc:def:crs:OGC:1.3:CRS84"}}}, Coordinates: []Positions{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}
+	c := &GeometryCollection{Geometries: []Geometry{*NewGeometry(p)}}
+
+	// Success sharing nothing with the original
+	clone := c.Clone().(*GeometryCollection)
+	cp := clone.Geometries[0].Polygon
+	cp.Coordinates[0][0][0] = 5
+	(*cp.BoundingBox)[0] = 5
+	cp.CRS.Name.Name = "x"
+	if p.Coordinates[0][0][0] != 0 || bbox[0] != 0 || p.CRS.Name.Name == "x" {
+		t.Errorf("expected the original to be unchanged but got %v", p)
+	}
+	if clone.Geometries[0].BoundingBox == nil || (*clone.Geometries[0].BoundingBox)[0] != 0 {
+		t.Errorf("expected the geometry object to be cloned but got %v", clone.Geometries[0].Object)
+	}
+}
+
+func TestNewGeometry(t *testing.T) {
+	// Success with a type switch over every value
+	for _, v := range []GeometryType{&Point{}, &MultiPoint{}, &LineString{}, &MultiLineString{}, &Polygon{}, &MultiPolygon{}, &GeometryCollection{}} {
+		g := NewGeometry(v)
+		if g.Value() != v {
+			t.Errorf("expected %T but got %T", v, g.Value())
+		}
+		if g.Type != v.GeoJSONType() {
+			t.Errorf("expected %q but got %q", v.GeoJSONType(), g.Type)
+		}
+	}
+
+	// Success with nil
+	if g := NewGeometry(nil); g != nil {
+		t.Errorf("expected nil but got %v", g)
+	}
+	for _, v := range []GeometryType{(*Point)(nil), (*MultiPoint)(nil), (*LineString)(nil), (*MultiLineString)(nil), (*Polygon)(nil), (*MultiPolygon)(nil), (*GeometryCollection)(nil)} {
+		if g := NewGeometry(v); g != nil {
+			t.Errorf("expected nil for %T but got %v", v, g)
+		}
+	}
+	var g *Geometry
+	if v := g.Value(); v != nil {
+		t.Errorf("expected nil but got %v", v)
+	}
+	if v := (&Geometry{}).Value(); v != nil {
+		t.Errorf("expected nil but got %v", v)
+	}
+}
+
+func TestPolygonContainsPosition(t *testing.T) {
+	p := &Polygon{Coordinates: []Positions{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+	}}
+
+	// Success testing positions inside, in the hole and outside
+	for _, test := range []struct {
+		pos      Position
+		expected bool
+	}{
+		{Position{1, 1}, true},
+		{Position{5, 5}, false},
+		{Position{11, 5}, false},
+		{Position{-1, -1}, false},
+		{Position{5}, false},
+		{nil, false},
+	} {
+		if c := p.ContainsPosition(test.pos); c != test.expected {
+			t.Errorf("expected %v for %v but got %v", test.expected, test.pos, c)
+		}
+	}
+}
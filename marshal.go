@@ -0,0 +1,356 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedValue happens when marshaling or unmarshaling a Feature
+	// from a value other than a struct or a slice of structs
+	ErrUnsupportedValue = errors.New("value must be a struct or a slice of structs")
+	// ErrInvalidTag happens when the geojson tags of a struct are unknown,
+	// repeated or on a field of the wrong type
+	ErrInvalidTag = errors.New("invalid geojson struct tag")
+)
+
+// MarshalFeature marshals the struct v as a Feature. The field tagged
+// `geojson:"geometry"`, a *Geometry, Point, *Point or Position,
+// becomes the geometry and the field tagged `geojson:"id"` the ID, which is
+// left out when it's the zero value. The other fields become the properties
+// the way encoding/json would marshal them. A slice or array of such structs
+// is marshaled as a FeatureCollection.
+func MarshalFeature(v interface{}) ([]byte, error) {
+	rv := indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Struct:
+		f, err := structFeature(rv)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(f)
+	case reflect.Slice, reflect.Array:
+		fc := FeatureCollection{Features: make([]Feature, rv.Len())}
+		for i := range fc.Features {
+			e := indirect(rv.Index(i))
+			if e.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("%w: got %s", ErrUnsupportedValue, rv.Type())
+			}
+			f, err := structFeature(e)
+			if err != nil {
+				return nil, err
+			}
+			fc.Features[i] = *f
+		}
+		return json.Marshal(fc)
+	}
+	return nil, fmt.Errorf("%w: got %T", ErrUnsupportedValue, v)
+}
+
+// UnmarshalFeature unmarshals a Feature into the struct v points to, or a
+// FeatureCollection into the slice v points to, filling in the fields tagged
+// as MarshalFeature describes. Properties are unmarshaled into the other
+// fields as encoding/json would.
+func UnmarshalFeature(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: got %T", ErrUnsupportedValue, v)
+	}
+	rv = rv.Elem()
+
+	switch t := elemType(rv.Type()); t.Kind() {
+	case reflect.Struct:
+		if rv.Kind() == reflect.Slice {
+			return unmarshalCollection(data, rv)
+		}
+		return unmarshalStruct(data, rv)
+	}
+	return fmt.Errorf("%w: got %T", ErrUnsupportedValue, v)
+}
+
+// elemType returns the type of the items of a slice of t or t, without
+// pointers
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func unmarshalCollection(data []byte, rv reflect.Value) error {
+	var fc struct {
+		Type     string            `json:"type"`
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return err
+	}
+	if fc.Type != "FeatureCollection" {
+		return fmt.Errorf("%w: expected a FeatureCollection but got %q", ErrInvalidGeoJSON, fc.Type)
+	}
+
+	s := reflect.MakeSlice(rv.Type(), len(fc.Features), len(fc.Features))
+	for i, f := range fc.Features {
+		e := s.Index(i)
+		for e.Kind() == reflect.Pointer {
+			e.Set(reflect.New(e.Type().Elem()))
+			e = e.Elem()
+		}
+		if err := unmarshalStruct(f, e); err != nil {
+			return err
+		}
+	}
+	rv.Set(s)
+	return nil
+}
+
+func unmarshalStruct(data []byte, rv reflect.Value) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	fields, err := taggedFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	var f RawFeature
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	if f.Type != "Feature" {
+		return fmt.Errorf("%w: expected a Feature but got %q", ErrInvalidGeoJSON, f.Type)
+	}
+
+	// the tagged fields aren't properties
+	if len(f.Properties) > 0 && string(f.Properties) != "null" {
+		var properties map[string]json.RawMessage
+		if err := json.Unmarshal(f.Properties, &properties); err != nil {
+			return err
+		}
+		for _, name := range fields.names {
+			delete(properties, name)
+		}
+		b, err := json.Marshal(properties)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, rv.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+
+	if fields.id != nil {
+		fv, err := rv.FieldByIndexErr(fields.id.Index)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTag, err)
+		}
+		b, err := json.Marshal(f.ID)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, fv.Addr().Interface()); err != nil {
+			return fmt.Errorf("id: %w", err)
+		}
+	}
+	if fields.geometry != nil {
+		fv, err := rv.FieldByIndexErr(fields.geometry.Index)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTag, err)
+		}
+		if err := setGeometryField(fv, f.Geometry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setGeometryField sets the geometry field fv to g
+func setGeometryField(fv reflect.Value, g *Geometry) error {
+	switch p := fv.Addr().Interface().(type) {
+	case **Geometry:
+		*p = g
+		return nil
+	}
+
+	var point *Point
+	if g != nil {
+		if point = g.Point; point == nil {
+			return fmt.Errorf("%w: expected a Point geometry but got %q", ErrInvalidGeometry, g.Type)
+		}
+	}
+	switch p := fv.Addr().Interface().(type) {
+	case **Point:
+		*p = point
+	case *Point:
+		*p = Point{}
+		if point != nil {
+			*p = *point
+		}
+	case *Position:
+		*p = nil
+		if point != nil {
+			*p = point.Coordinates
+		}
+	}
+	return nil
+}
+
+// structFeature returns the Feature of the struct rv
+func structFeature(rv reflect.Value) (*Feature, error) {
+	fields, err := taggedFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	// the tagged fields are zeroed in a copy so they marshal as properties
+	// that can be dropped, unless they're promoted through a pointer that
+	// would be shared with v
+	c := reflect.New(rv.Type()).Elem()
+	c.Set(rv)
+	for _, sf := range []*reflect.StructField{fields.geometry, fields.id} {
+		if sf != nil && !throughPointer(rv.Type(), sf.Index) {
+			c.FieldByIndex(sf.Index).SetZero()
+		}
+	}
+
+	f := &Feature{}
+	if err := f.EncodeProperties(c.Interface()); err != nil {
+		return nil, err
+	}
+	for _, name := range fields.names {
+		delete(f.Properties, name)
+	}
+
+	if fields.id != nil {
+		if fv, err := rv.FieldByIndexErr(fields.id.Index); err == nil && !fv.IsZero() {
+			f.ID = fv.Interface()
+		}
+	}
+	if fields.geometry != nil {
+		if fv, err := rv.FieldByIndexErr(fields.geometry.Index); err == nil {
+			f.Geometry = geometryField(fv)
+		}
+	}
+	return f, nil
+}
+
+// throughPointer reports whether the field at index of the struct type t is
+// promoted through an embedded pointer
+func throughPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		ft := t.Field(i).Type
+		if ft.Kind() == reflect.Pointer {
+			return true
+		}
+		t = ft
+	}
+	return false
+}
+
+// geometryField returns the Geometry of the geometry field fv or nil
+func geometryField(fv reflect.Value) *Geometry {
+	switch v := fv.Interface().(type) {
+	case *Geometry:
+		return v
+	case *Point:
+		if v != nil {
+			return &Geometry{Point: v}
+		}
+	case Point:
+		if v.Coordinates != nil {
+			return &Geometry{Point: &v}
+		}
+	case Position:
+		if v != nil {
+			return &Geometry{Point: &Point{Coordinates: v}}
+		}
+	}
+	return nil
+}
+
+// structFields are the tagged fields of a struct
+type structFields struct {
+	geometry, id *reflect.StructField
+	// names are the JSON names of the tagged fields
+	names []string
+}
+
+var geometryFieldTypes = []reflect.Type{
+	reflect.TypeFor[*Geometry](),
+	reflect.TypeFor[*Point](),
+	reflect.TypeFor[Point](),
+	reflect.TypeFor[Position](),
+}
+
+// taggedFields returns the fields of the struct type t with a geojson tag
+func taggedFields(t reflect.Type) (*structFields, error) {
+	fields := &structFields{}
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup("geojson")
+		if !ok {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("%w: %s.%s isn't exported", ErrInvalidTag, t, sf.Name)
+		}
+
+		switch tag {
+		case "geometry":
+			if fields.geometry != nil {
+				return nil, fmt.Errorf("%w: %s has more than one geometry", ErrInvalidTag, t)
+			}
+			valid := false
+			for _, gt := range geometryFieldTypes {
+				valid = valid || sf.Type == gt
+			}
+			if !valid {
+				return nil, fmt.Errorf("%w: %s.%s can't be a geometry of type %s", ErrInvalidTag, t, sf.Name, sf.Type)
+			}
+			fields.geometry = &sf
+		case "id":
+			if fields.id != nil {
+				return nil, fmt.Errorf("%w: %s has more than one id", ErrInvalidTag, t)
+			}
+			fields.id = &sf
+		default:
+			return nil, fmt.Errorf("%w: %s.%s has unknown tag %q", ErrInvalidTag, t, sf.Name, tag)
+		}
+		if name := jsonName(sf); name != "" {
+			fields.names = append(fields.names, name)
+		}
+	}
+	return fields, nil
+}
+
+// jsonName returns the key encoding/json uses for a field or an empty string
+// when it skips it
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return sf.Name
+	}
+	return name
+}
+
+// indirect follows the pointers and interfaces of rv
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
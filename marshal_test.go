@@ -0,0 +1,143 @@
+package geojson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testPlace struct {
+	ID       int64    `json:"id" geojson:"id"`
+	Location Position `json:"location" geojson:"geometry"`
+	Name     string   `json:"name"`
+	Visits   int      `json:"visits,omitempty"`
+}
+
+type testVenue struct {
+	testPlace
+	Capacity int `json:"capacity"`
+}
+
+type testTrack struct {
+	Key   string    `geojson:"id"`
+	Shape *Geometry `geojson:"geometry"`
+	Note  string
+}
+
+func TestMarshalFeature(t *testing.T) {
+	// Success marshaling a struct as a Feature
+	b, err := MarshalFeature(testPlace{ID: 9007199254740993, Location: Position{1, 2}, Name: "Park"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"Feature","id":9007199254740993,"geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"Park"}}`
+	if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, b)
+	}
+
+	// Success marshaling a slice of embedding structs as a FeatureCollection
+	b, err = MarshalFeature([]*testVenue{{testPlace: testPlace{ID: 1, Name: "Hall", Visits: 3}, Capacity: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"type":"FeatureCollection","features":[{"type":"Feature","id":1,"geometry":null,"properties":{"capacity":100,"name":"Hall","visits":3}}]}`
+	if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, b)
+	}
+
+	// Success leaving out zero IDs
+	b, err = MarshalFeature([]testTrack{{Note: "x"}, {Note: "y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":{"Note":"x"}},{"type":"Feature","geometry":null,"properties":{"Note":"y"}}]}`
+	if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, b)
+	}
+
+	// Success marshaling a *Geometry field
+	line := &Geometry{LineString: &LineString{Coordinates: Positions{{0, 0}, {1, 1}}}}
+	b, err = MarshalFeature(&testTrack{Key: "a", Shape: line, Note: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"type":"Feature","id":"a","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"Note":"x"}}`
+	if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, b)
+	}
+
+	// Fail on values other than structs
+	for _, v := range []interface{}{1, []int{1}, nil} {
+		if _, err := MarshalFeature(v); !errors.Is(err, ErrUnsupportedValue) {
+			t.Errorf("expected %q for %#v but got %v", ErrUnsupportedValue, v, err)
+		}
+	}
+
+	// Fail on invalid tags
+	for _, v := range []interface{}{
+		struct {
+			A Position `geojson:"geometry"`
+			B Position `geojson:"geometry"`
+		}{},
+		struct {
+			A string `geojson:"geometry"`
+		}{},
+		struct {
+			A string `geojson:"properties"`
+		}{},
+		struct {
+			a string `geojson:"id"`
+		}{},
+	} {
+		if _, err := MarshalFeature(v); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expected %q for %#v but got %v", ErrInvalidTag, v, err)
+		}
+	}
+}
+
+func TestUnmarshalFeature(t *testing.T) {
+	// Success unmarshaling a Feature into a struct
+	var p testPlace
+	err := UnmarshalFeature([]byte(`{"type":"Feature","id":9007199254740993,"geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"Park","location":"ignored"}}`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := testPlace{ID: 9007199254740993, Location: Position{1, 2}, Name: "Park"}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("expected %#v but got %#v", expected, p)
+	}
+
+	// Success unmarshaling a FeatureCollection into a slice
+	var venues []*testVenue
+	err = UnmarshalFeature([]byte(`{"type":"FeatureCollection","features":[{"type":"Feature","id":1,"geometry":null,"properties":{"name":"Hall","capacity":100}},{"type":"Feature","id":2,"geometry":null,"properties":null}]}`), &venues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(venues) != 2 || venues[0].Name != "Hall" || venues[0].Capacity != 100 || venues[1].ID != 2 {
+		t.Errorf("expected 2 venues but got %#v", venues)
+	}
+
+	// Success unmarshaling any geometry into a *Geometry
+	var track testTrack
+	if err := UnmarshalFeature([]byte(`{"type":"Feature","id":"a","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"Note":"x"}}`), &track); err != nil {
+		t.Fatal(err)
+	}
+	if track.Key != "a" || track.Shape == nil || track.Shape.LineString == nil || track.Note != "x" {
+		t.Errorf("expected the track but got %#v", track)
+	}
+
+	// Fail on a geometry that doesn't fit the field
+	if err := UnmarshalFeature([]byte(`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":null}`), &p); !errors.Is(err, ErrInvalidGeometry) {
+		t.Errorf("expected %q but got %v", ErrInvalidGeometry, err)
+	}
+
+	// Fail on the wrong GeoJSON type
+	if err := UnmarshalFeature([]byte(`{"type":"Feature","geometry":null,"properties":null}`), &venues); !errors.Is(err, ErrInvalidGeoJSON) {
+		t.Errorf("expected %q but got %v", ErrInvalidGeoJSON, err)
+	}
+
+	// Fail on values other than pointers to structs or slices
+	if err := UnmarshalFeature([]byte(`{}`), p); !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("expected %q but got %v", ErrUnsupportedValue, err)
+	}
+}
@@ -0,0 +1,251 @@
+package ogcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// Client fetches features from an OGC API - Features or WFS 3 service
+type Client struct {
+	// BaseURL is the URL of the landing page of the service
+	BaseURL string
+	// HTTPClient makes the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+// NewClient returns a Client of the service whose landing page is at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: baseURL}
+}
+
+// Page is a page of features of an items response
+type Page struct {
+	// FeatureCollection holds the features of the page
+	geojson.FeatureCollection
+	// Links are the links of the page, including the next one
+	Links []Link
+	// NumberMatched is the number of features matching the query across every
+	// page, -1 when the service didn't tell
+	NumberMatched int
+	// NumberReturned is the number of features of the page
+	NumberReturned int
+}
+
+// Next returns the URL of the next page or an empty string on the last page
+func (p *Page) Next() string {
+	for _, l := range p.Links {
+		if l.Rel == "next" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// Collections returns the description of every collection of the service
+func (c *Client) Collections(ctx context.Context) ([]Collection, error) {
+	var v struct {
+		Collections []Collection `json:"collections"`
+	}
+	if err := c.get(ctx, c.url("collections"), &v); err != nil {
+		return nil, err
+	}
+	return v.Collections, nil
+}
+
+// Collection returns the description of the collection id
+func (c *Client) Collection(ctx context.Context, id string) (*Collection, error) {
+	var v Collection
+	if err := c.get(ctx, c.url("collections", id), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Item returns the feature id of a collection. The error wraps ErrNotFound
+// when the service has no such feature.
+func (c *Client) Item(ctx context.Context, collection, id string) (*geojson.Feature, error) {
+	var f geojson.Feature
+	if err := c.get(ctx, c.url("collections", collection, "items", id), &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Pages returns an iterator over the pages of the features of a collection
+// matching q. The first page is requested with q, the others by following
+// the next links until a page has none. Iteration stops after the first
+// error.
+func (c *Client) Pages(ctx context.Context, collection string, q Query) iter.Seq2[*Page, error] {
+	return func(yield func(*Page, error) bool) {
+		next := c.url("collections", collection, "items") + "?" + q.values().Encode()
+		seen := make(map[string]bool)
+		for next != "" && !seen[next] {
+			seen[next] = true
+
+			p, err := c.page(ctx, next)
+			if !yield(p, err) || err != nil {
+				return
+			}
+			if next, err = resolve(next, p.Next()); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// Items returns an iterator over the features of a collection matching q,
+// fetching the pages as they're needed. q.Limit is the size of the pages
+// requested, not the number of features returned.
+func (c *Client) Items(ctx context.Context, collection string, q Query) iter.Seq2[*geojson.Feature, error] {
+	return func(yield func(*geojson.Feature, error) bool) {
+		for p, err := range c.Pages(ctx, collection, q) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range p.Features {
+				if !yield(&p.Features[i], nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// page fetches and decodes the page at u
+func (c *Client) page(ctx context.Context, u string) (*Page, error) {
+	var raw json.RawMessage
+	if err := c.get(ctx, u, &raw); err != nil {
+		return nil, err
+	}
+
+	p := &Page{NumberMatched: -1}
+	if err := json.Unmarshal(raw, &p.FeatureCollection); err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+	var members struct {
+		Links          []Link `json:"links"`
+		NumberMatched  *int   `json:"numberMatched"`
+		NumberReturned *int   `json:"numberReturned"`
+	}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+
+	p.Links = members.Links
+	if members.NumberMatched != nil {
+		p.NumberMatched = *members.NumberMatched
+	}
+	p.NumberReturned = len(p.Features)
+	if members.NumberReturned != nil {
+		p.NumberReturned = *members.NumberReturned
+	}
+	return p, nil
+}
+
+// get decodes the JSON response to a GET of u into v. Error responses are
+// returned as an *Exception wrapping ErrNotFound for a 404.
+func (c *Client) get(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", MediaTypeGeoJSON+", "+MediaTypeJSON+";q=0.9")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e := &Exception{Code: http.StatusText(resp.StatusCode)}
+		if isJSON(resp.Header.Get("Content-Type")) {
+			json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(e)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%s: %w: %w", u, ErrNotFound, e)
+		}
+		return fmt.Errorf("%s: %w", u, e)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", u, err)
+	}
+	return nil
+}
+
+// url returns the URL of the path segments below BaseURL
+func (c *Client) url(segments ...string) string {
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.Join(segments, "/")
+}
+
+// values returns the query parameters of q
+func (q Query) values() url.Values {
+	v := make(url.Values)
+	for k, p := range q.Properties {
+		v.Set(k, p)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		v.Set("offset", strconv.Itoa(q.Offset))
+	}
+	if len(q.BBox) > 0 {
+		bbox := make([]string, len(q.BBox))
+		for i, f := range q.BBox {
+			bbox[i] = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		v.Set("bbox", strings.Join(bbox, ","))
+	}
+	if q.Datetime != "" {
+		v.Set("datetime", q.Datetime)
+	}
+	if q.Filter != "" {
+		v.Set("filter", q.Filter)
+		if q.FilterLang != "" {
+			v.Set("filter-lang", q.FilterLang)
+		}
+	}
+	return v
+}
+
+// resolve returns the reference ref relative to base, empty when ref is
+func resolve(base, ref string) (string, error) {
+	if ref == "" {
+		return "", nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := b.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// isJSON reports whether the media type is JSON or a JSON based type
+func isJSON(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (t == MediaTypeJSON || strings.HasSuffix(t, "+json"))
+}
@@ -0,0 +1,138 @@
+package ogcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	s := NewServer("test")
+	s.Add("test", NewMemorySource(testCollection))
+
+	// requests records the query of every items request made to the server
+	var requests []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query())
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL + "/")
+	ctx := context.Background()
+
+	// Success listing the collections
+	if collections, err := c.Collections(ctx); err != nil {
+		t.Error(err)
+	} else if len(collections) != 1 || collections[0].ID != "test" {
+		t.Errorf("expected the test collection but got %v", collections)
+	}
+	if collection, err := c.Collection(ctx, "test"); err != nil {
+		t.Error(err)
+	} else if collection.Extent == nil {
+		t.Errorf("expected the extent of the collection but got %v", collection)
+	}
+
+	// Success following the next links
+	requests = nil
+	var ids []interface{}
+	for f, err := range c.Items(ctx, "test", Query{Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[2] != 3.0 {
+		t.Errorf("expected the features a, 2 and 3 but got %v", ids)
+	}
+	if len(requests) != 2 || requests[1].Get("offset") != "2" {
+		t.Errorf("expected 2 pages but got %v", requests)
+	}
+
+	// Success reading the members of a page
+	for p, err := range c.Pages(ctx, "test", Query{Limit: 1}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.NumberMatched != 3 || p.NumberReturned != 1 || p.Next() == "" {
+			t.Errorf("expected the first of 3 features but got %v of %v", p.NumberReturned, p.NumberMatched)
+		}
+		break
+	}
+
+	// Success sending the filters
+	requests = nil
+	q := Query{
+		BBox:       []float64{99, -1, 101.5, 2},
+		Datetime:   "2018-02-12T00:00:00Z/..",
+		Properties: map[string]string{"prop0": "value1"},
+		Filter:     "prop1 IS NOT NULL",
+	}
+	ids = nil
+	for f, err := range c.Items(ctx, "test", q) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if len(ids) != 1 || ids[0] != 3.0 {
+		t.Errorf("expected the feature 3 but got %v", ids)
+	}
+	if len(requests) != 1 || requests[0].Get("bbox") != "99,-1,101.5,2" || requests[0].Get("datetime") != q.Datetime || requests[0].Get("prop0") != "value1" || requests[0].Get("filter") != q.Filter {
+		t.Errorf("expected the filters but got %v", requests)
+	}
+
+	// Success getting a feature
+	if f, err := c.Item(ctx, "test", "a"); err != nil {
+		t.Error(err)
+	} else if f.Properties["prop0"] != "value0" {
+		t.Errorf("expected the feature a but got %v", f)
+	}
+
+	// Fail on a missing feature
+	var e *Exception
+	if _, err := c.Item(ctx, "test", "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %q but got %v", ErrNotFound, err)
+	} else if !errors.As(err, &e) || e.Description != `feature "b" not found` {
+		t.Errorf("expected the exception of the service but got %v", err)
+	}
+
+	// Fail on an invalid query
+	for _, err := range c.Items(ctx, "test", Query{BBox: []float64{1, 2, 3}}) {
+		if !errors.As(err, &e) || e.Code != http.StatusText(http.StatusBadRequest) {
+			t.Errorf("expected a bad request but got %v", err)
+		}
+	}
+
+	// Fail when the context is done
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, err := range c.Items(cancelled, "test", Query{}) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %q but got %v", context.Canceled, err)
+		}
+	}
+}
+
+func TestClientLoop(t *testing.T) {
+	// a service whose next link points back to the same page
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeGeoJSON)
+		w.Write([]byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null}],"links":[{"href":"items?","rel":"next"}]}`))
+	}))
+	defer ts.Close()
+
+	// Success stopping on a page already fetched
+	n := 0
+	for _, err := range NewClient(ts.URL).Items(context.Background(), "loop", Query{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Errorf("expected 1 feature but got %v", n)
+	}
+}
@@ -0,0 +1,50 @@
+package ogcapi
+
+import "github.com/losinggeneration/geojson"
+
+// intersects reports whether the box a of minx, miny, maxx and maxy and the
+// bounding box b share any point in x and y. A nil box intersects nothing.
+func intersects(a []float64, b geojson.BoundingBox) bool {
+	if a == nil || len(b) < 4 {
+		return false
+	}
+	d := len(b) / 2
+	return a[0] <= b[d] && b[0] <= a[2] && a[1] <= b[d+1] && b[1] <= a[3]
+}
+
+// transformFeature returns a copy of f with fn applied to every position and
+// bounding box corner, leaving f as it is
+func transformFeature(f geojson.Feature, fn func(geojson.Position) geojson.Position) geojson.Feature {
+	f.BoundingBox = transformBBox(f.BoundingBox, fn)
+	v := f.Geometry.Value()
+	if v == nil {
+		return f
+	}
+
+	g := geojson.NewGeometry(v.Clone())
+	g.Object = f.Geometry.Object
+	geojson.Transform(geojson.GeoJSON{Geometry: g}, fn)
+	geojson.Walk(geojson.GeoJSON{Geometry: g}, geojson.VisitFunc(func(v interface{}) error {
+		if g, ok := v.(*geojson.Geometry); ok {
+			g.BoundingBox = transformBBox(g.BoundingBox, fn)
+		}
+		return nil
+	}))
+	f.Geometry = g
+	return f
+}
+
+// transformBBox returns the bounding box of the lower and upper corners of b
+// with fn applied to copies of them
+func transformBBox(b *geojson.BoundingBox, fn func(geojson.Position) geojson.Position) *geojson.BoundingBox {
+	if b == nil || len(*b)%2 != 0 {
+		return b
+	}
+
+	d := len(*b) / 2
+	lower := fn(append(geojson.Position{}, (*b)[:d]...))
+	upper := fn(append(geojson.Position{}, (*b)[d:]...))
+	t := append(geojson.BoundingBox{}, lower...)
+	t = append(t, upper...)
+	return &t
+}
@@ -0,0 +1,100 @@
+// Package ogcapi serves and consumes GeoJSON features following OGC API -
+// Features - Part 1: Core, the successor of WFS 3.
+//
+// A Server is an http.Handler publishing the landing page, conformance
+// declaration, collections and items of any number of FeatureSource. The crs
+// and bbox-crs parameters of Part 2 are supported for CRS84 and Web Mercator,
+// as are CQL2 filters of Part 3.
+//
+// A Client reads the collections of a service, iterating over the features
+// across pages by following the next links.
+package ogcapi
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned by a FeatureSource when there's no feature with
+	// the requested ID
+	ErrNotFound = errors.New("feature not found")
+	// ErrDuplicateCollection happens when adding a collection with an ID that
+	// was already added
+	ErrDuplicateCollection = errors.New("duplicate collection")
+)
+
+// Media types of the responses
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeGeoJSON = "application/geo+json"
+)
+
+// Coordinate reference systems supported by the crs and bbox-crs parameters
+const (
+	// CRS84 is the default, WGS 84 longitude and latitude
+	CRS84 = "http://www.opengis.net/def/crs/OGC/1.3/CRS84"
+	// WebMercator is EPSG:3857
+	WebMercator = "http://www.opengis.net/def/crs/EPSG/0/3857"
+)
+
+// Conformance classes implemented by Server
+var Conformance = []string{
+	"http://www.opengis.net/spec/ogcapi-features-1/1.0/conf/core",
+	"http://www.opengis.net/spec/ogcapi-features-1/1.0/conf/geojson",
+	"http://www.opengis.net/spec/ogcapi-features-2/1.0/conf/crs",
+	"http://www.opengis.net/spec/ogcapi-features-3/1.0/conf/filter",
+	"http://www.opengis.net/spec/ogcapi-features-3/1.0/conf/features-filter",
+	"http://www.opengis.net/spec/cql2/1.0/conf/cql2-text",
+	"http://www.opengis.net/spec/cql2/1.0/conf/cql2-json",
+	"http://www.opengis.net/spec/cql2/1.0/conf/basic-cql2",
+	"http://www.opengis.net/spec/cql2/1.0/conf/advanced-comparison-operators",
+	"http://www.opengis.net/spec/cql2/1.0/conf/basic-spatial-functions",
+	"http://www.opengis.net/spec/cql2/1.0/conf/temporal-functions",
+}
+
+// Link is a link of a response
+type Link struct {
+	Href  string `json:"href"`
+	Rel   string `json:"rel"`
+	Type  string `json:"type,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// Collection describes a collection of features
+type Collection struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Links       []Link  `json:"links"`
+	Extent      *Extent `json:"extent,omitempty"`
+	ItemType    string  `json:"itemType,omitempty"`
+	// CRS are the coordinate reference systems the features can be requested in
+	CRS []string `json:"crs,omitempty"`
+	// StorageCRS is the coordinate reference system of the features stored
+	StorageCRS string `json:"storageCrs,omitempty"`
+}
+
+// Extent is the spatial extent of a collection
+type Extent struct {
+	Spatial *SpatialExtent `json:"spatial,omitempty"`
+}
+
+// SpatialExtent holds the bounding boxes of a collection, the first one
+// covering every feature
+type SpatialExtent struct {
+	BBox [][]float64 `json:"bbox"`
+	CRS  string      `json:"crs,omitempty"`
+}
+
+// Exception is the body of an error response
+type Exception struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+}
+
+func (e *Exception) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
@@ -0,0 +1,426 @@
+package ogcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// Limits of the items requests used when the Server leaves them at zero
+const (
+	DefaultLimit = 10
+	MaxLimit     = 10000
+)
+
+// reserved are the query parameters of an items request that aren't
+// property filters
+var reserved = map[string]bool{
+	"limit":       true,
+	"offset":      true,
+	"bbox":        true,
+	"bbox-crs":    true,
+	"crs":         true,
+	"datetime":    true,
+	"f":           true,
+	"filter":      true,
+	"filter-lang": true,
+	"filter-crs":  true,
+}
+
+// Server is an http.Handler serving collections of features. Links are made
+// absolute using the host of the request and keep any prefix stripped with
+// http.StripPrefix, so a Server can be mounted under any path.
+//
+// Items requests accept the limit, offset, bbox, bbox-crs, crs, datetime, f,
+// filter and filter-lang parameters. Any other parameter selects the features
+// whose property of the same name formats as its value with FormatValue.
+type Server struct {
+	// Title and Description are shown on the landing page
+	Title, Description string
+	// DefaultLimit is the number of features of a page when the limit
+	// parameter is missing
+	DefaultLimit int
+	// MaxLimit is the largest number of features of a page
+	MaxLimit int
+
+	order   []string
+	sources map[string]FeatureSource
+}
+
+// NewServer returns a Server without any collection
+func NewServer(title string) *Server {
+	return &Server{Title: title, sources: make(map[string]FeatureSource)}
+}
+
+// Add serves the features of src as the collection id
+func (s *Server) Add(id string, src FeatureSource) error {
+	if s.sources[id] != nil {
+		return fmt.Errorf("%w %q", ErrDuplicateCollection, id)
+	}
+	s.order = append(s.order, id)
+	s.sources[id] = src
+	return nil
+}
+
+// ServeHTTP routes the requests to the landing page, conformance, collections
+// and items
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "")
+		return
+	}
+	if f := r.URL.Query().Get("f"); f != "" && f != "json" {
+		writeError(w, http.StatusNotAcceptable, fmt.Sprintf("unsupported format %q, only json is available", f))
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "":
+		s.landing(w, r)
+	case path == "conformance":
+		writeJSON(w, MediaTypeJSON, map[string]interface{}{"conformsTo": Conformance})
+	case path == "collections":
+		s.collections(w, r)
+	case len(parts) >= 2 && len(parts) <= 4 && parts[0] == "collections":
+		id := parts[1]
+		src := s.sources[id]
+		switch {
+		case src == nil:
+			writeError(w, http.StatusNotFound, fmt.Sprintf("collection %q not found", id))
+		case len(parts) == 2:
+			s.collection(w, r, id, src)
+		case parts[2] != "items":
+			writeError(w, http.StatusNotFound, "")
+		case len(parts) == 3:
+			s.items(w, r, id, src)
+		default:
+			s.item(w, r, id, src, parts[3])
+		}
+	default:
+		writeError(w, http.StatusNotFound, "")
+	}
+}
+
+func (s *Server) landing(w http.ResponseWriter, r *http.Request) {
+	base := baseURL(r)
+	writeJSON(w, MediaTypeJSON, map[string]interface{}{
+		"title":       s.Title,
+		"description": s.Description,
+		"links": []Link{
+			{Href: base + "/", Rel: "self", Type: MediaTypeJSON, Title: "This document"},
+			{Href: base + "/conformance", Rel: "conformance", Type: MediaTypeJSON, Title: "Conformance classes"},
+			{Href: base + "/collections", Rel: "data", Type: MediaTypeJSON, Title: "Collections"},
+		},
+	})
+}
+
+func (s *Server) collections(w http.ResponseWriter, r *http.Request) {
+	base := baseURL(r)
+	collections := make([]*Collection, 0, len(s.order))
+	for _, id := range s.order {
+		c, err := s.describe(r, base, id, s.sources[id])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		collections = append(collections, c)
+	}
+
+	writeJSON(w, MediaTypeJSON, map[string]interface{}{
+		"links":       []Link{{Href: base + "/collections", Rel: "self", Type: MediaTypeJSON}},
+		"collections": collections,
+		"crs":         []string{CRS84, WebMercator},
+	})
+}
+
+func (s *Server) collection(w http.ResponseWriter, r *http.Request, id string, src FeatureSource) {
+	c, err := s.describe(r, baseURL(r), id, src)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, MediaTypeJSON, c)
+}
+
+// describe returns the description of src with the members set by the Server
+func (s *Server) describe(r *http.Request, base, id string, src FeatureSource) (*Collection, error) {
+	c, err := src.Describe(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	href := base + "/collections/" + url.PathEscape(id)
+	c.ID = id
+	c.Links = append(c.Links,
+		Link{Href: href, Rel: "self", Type: MediaTypeJSON},
+		Link{Href: href + "/items", Rel: "items", Type: MediaTypeGeoJSON},
+	)
+	c.ItemType = "feature"
+	c.CRS = []string{CRS84, WebMercator}
+	c.StorageCRS = CRS84
+	return c, nil
+}
+
+// itemsResponse is a page of features with the members OGC API - Features
+// adds to a FeatureCollection
+type itemsResponse struct {
+	Type           string            `json:"type"`
+	Features       []geojson.Feature `json:"features"`
+	Links          []Link            `json:"links"`
+	TimeStamp      string            `json:"timeStamp"`
+	NumberMatched  int               `json:"numberMatched"`
+	NumberReturned int               `json:"numberReturned"`
+}
+
+func (s *Server) items(w http.ResponseWriter, r *http.Request, id string, src FeatureSource) {
+	params := r.URL.Query()
+	q, err := s.query(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	crs, err := crsParam(params, "crs")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	features, matched, err := src.Items(r.Context(), q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if crs == WebMercator {
+		projected := make([]geojson.Feature, len(features))
+		for i, f := range features {
+			projected[i] = transformFeature(f, geojson.ToWebMercator)
+		}
+		features = projected
+	}
+
+	base := baseURL(r) + "/collections/" + url.PathEscape(id)
+	pageLink := func(rel string, offset int) Link {
+		p := r.URL.Query()
+		p.Set("offset", strconv.Itoa(offset))
+		p.Set("limit", strconv.Itoa(q.Limit))
+		return Link{Href: base + "/items?" + p.Encode(), Rel: rel, Type: MediaTypeGeoJSON}
+	}
+	self := base + "/items"
+	if r.URL.RawQuery != "" {
+		self += "?" + r.URL.RawQuery
+	}
+	links := []Link{
+		{Href: self, Rel: "self", Type: MediaTypeGeoJSON},
+		{Href: base, Rel: "collection", Type: MediaTypeJSON},
+	}
+	if q.Offset+len(features) < matched {
+		links = append(links, pageLink("next", q.Offset+q.Limit))
+	}
+	if q.Offset > 0 {
+		links = append(links, pageLink("prev", max(0, q.Offset-q.Limit)))
+	}
+
+	w.Header().Set("Content-Crs", "<"+crs+">")
+	writeJSON(w, MediaTypeGeoJSON, itemsResponse{
+		Type:           "FeatureCollection",
+		Features:       features,
+		Links:          links,
+		TimeStamp:      time.Now().UTC().Format(time.RFC3339),
+		NumberMatched:  matched,
+		NumberReturned: len(features),
+	})
+}
+
+func (s *Server) item(w http.ResponseWriter, r *http.Request, id string, src FeatureSource, featureID string) {
+	crs, err := crsParam(r.URL.Query(), "crs")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f, err := src.Item(r.Context(), featureID)
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("feature %q not found", featureID))
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	feature := *f
+	if crs == WebMercator {
+		feature = transformFeature(feature, geojson.ToWebMercator)
+	}
+
+	base := baseURL(r) + "/collections/" + url.PathEscape(id)
+	b, err := json.Marshal(feature)
+	if err == nil {
+		b, err = appendMember(b, "links", []Link{
+			{Href: base + "/items/" + url.PathEscape(featureID), Rel: "self", Type: MediaTypeGeoJSON},
+			{Href: base, Rel: "collection", Type: MediaTypeJSON},
+		})
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Crs", "<"+crs+">")
+	w.Header().Set("Content-Type", MediaTypeGeoJSON)
+	w.Write(b)
+}
+
+// appendMember adds the member key with the JSON of v to the object b
+func appendMember(b []byte, key string, v interface{}) ([]byte, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	k, _ := json.Marshal(key)
+
+	b = b[:len(b)-1]
+	if len(b) > 1 {
+		b = append(b, ',')
+	}
+	b = append(b, k...)
+	b = append(b, ':')
+	b = append(b, value...)
+	return append(b, '}'), nil
+}
+
+// query parses the parameters of an items request
+func (s *Server) query(params url.Values) (Query, error) {
+	defaultLimit, maxLimit := s.DefaultLimit, s.MaxLimit
+	if defaultLimit <= 0 {
+		defaultLimit = DefaultLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = MaxLimit
+	}
+
+	q := Query{Limit: defaultLimit}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return q, errors.New("limit must be a positive integer")
+		}
+		q.Limit = min(limit, maxLimit)
+	}
+	if v := params.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return q, errors.New("offset must be a non-negative integer")
+		}
+		q.Offset = offset
+	}
+
+	if v := params.Get("bbox"); v != "" {
+		bbox, err := parseBBox(v)
+		if err != nil {
+			return q, err
+		}
+		crs, err := crsParam(params, "bbox-crs")
+		if err != nil {
+			return q, err
+		}
+		if crs == WebMercator {
+			lower, upper := geojson.FromWebMercator(geojson.Position(bbox[:2])), geojson.FromWebMercator(geojson.Position(bbox[2:]))
+			bbox = []float64{lower[0], lower[1], upper[0], upper[1]}
+		}
+		q.BBox = bbox
+	}
+
+	q.Datetime = params.Get("datetime")
+	if v := params.Get("filter"); v != "" {
+		q.Filter, q.FilterLang = v, params.Get("filter-lang")
+		if _, err := q.filter(); err != nil {
+			return q, err
+		}
+		if crs, err := crsParam(params, "filter-crs"); err != nil || crs != CRS84 {
+			return q, errors.New("filter-crs must be CRS84")
+		}
+	}
+	for k, v := range params {
+		if reserved[k] {
+			continue
+		}
+		if q.Properties == nil {
+			q.Properties = make(map[string]string)
+		}
+		q.Properties[k] = v[0]
+	}
+	return q, nil
+}
+
+// parseBBox parses a bbox parameter of 4 or 6 numbers into minx, miny, maxx
+// and maxy
+func parseBBox(s string) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 && len(parts) != 6 {
+		return nil, errors.New("bbox must have 4 or 6 numbers")
+	}
+
+	v := make([]float64, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox number %q", p)
+		}
+		v[i] = f
+	}
+	d := len(v) / 2
+	return []float64{v[0], v[1], v[d], v[d+1]}, nil
+}
+
+// crsParam returns the CRS of the named parameter, CRS84 when it's missing
+func crsParam(params url.Values, name string) (string, error) {
+	switch v := strings.Trim(params.Get(name), "[]"); v {
+	case "", CRS84:
+		return CRS84, nil
+	case WebMercator:
+		return WebMercator, nil
+	default:
+		return "", fmt.Errorf("unsupported %s %q", name, v)
+	}
+}
+
+// baseURL returns the URL the Server is mounted at, without a trailing slash
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	prefix := ""
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		prefix = strings.TrimSuffix(u.Path, r.URL.Path)
+	}
+	return scheme + "://" + r.Host + strings.TrimSuffix(prefix, "/")
+}
+
+func writeJSON(w http.ResponseWriter, mediaType string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Write(b)
+}
+
+// writeError writes an Exception with the status
+func writeError(w http.ResponseWriter, status int, description string) {
+	b, _ := json.Marshal(&Exception{Code: http.StatusText(status), Description: description})
+	w.Header().Set("Content-Type", MediaTypeJSON)
+	w.WriteHeader(status)
+	w.Write(b)
+}
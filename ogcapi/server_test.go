@@ -0,0 +1,213 @@
+package ogcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var testCollection = &geojson.FeatureCollection{Features: []geojson.Feature{
+	{
+		ID:         "a",
+		Geometry:   geojson.NewGeometry(&geojson.Point{Coordinates: geojson.Position{102, 0.5}}),
+		Properties: geojson.Properties{"prop0": "value0"},
+	},
+	{
+		Geometry:   geojson.NewGeometry(&geojson.LineString{Coordinates: geojson.Positions{{102, 0}, {103, 1}, {104, 0}, {105, 1}}}),
+		Properties: geojson.Properties{"prop0": "value0", "prop1": 0.0},
+	},
+	{
+		Geometry:   geojson.NewGeometry(&geojson.Polygon{Coordinates: []geojson.Positions{{{100, 0}, {101, 0}, {101, 1}, {100, 1}, {100, 0}}}}),
+		Properties: geojson.Properties{"prop0": "value1", "prop1": map[string]interface{}{"this": "that"}},
+	},
+}}
+
+type testItems struct {
+	Features []struct {
+		ID       interface{}
+		Geometry struct {
+			Coordinates json.RawMessage
+		}
+	}
+	Links          []Link
+	NumberMatched  int
+	NumberReturned int
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer("test")
+	if err := s.Add("test", NewMemorySource(testCollection)); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", s))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(path string, status int, mediaType string, v interface{}) http.Header {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/api" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("expected status %v for %v but got %v", status, path, resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != mediaType {
+			t.Errorf("expected %q for %v but got %q", mediaType, path, ct)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Errorf("expected JSON for %v but got %v", path, err)
+		}
+		return resp.Header
+	}
+
+	// Success getting the landing page with links under the prefix
+	var landing struct {
+		Title string
+		Links []Link
+	}
+	get("/", http.StatusOK, MediaTypeJSON, &landing)
+	if landing.Title != "test" || len(landing.Links) != 3 || landing.Links[2].Href != ts.URL+"/api/collections" {
+		t.Errorf("expected the landing page but got %v", landing)
+	}
+
+	// Success getting the conformance classes
+	var conformance struct {
+		ConformsTo []string
+	}
+	get("/conformance?f=json", http.StatusOK, MediaTypeJSON, &conformance)
+	if len(conformance.ConformsTo) != len(Conformance) {
+		t.Errorf("expected %v but got %v", Conformance, conformance.ConformsTo)
+	}
+
+	// Success describing the collections
+	var collections struct {
+		Collections []Collection
+	}
+	get("/collections", http.StatusOK, MediaTypeJSON, &collections)
+	if len(collections.Collections) != 1 {
+		t.Fatalf("expected 1 collection but got %v", collections.Collections)
+	}
+	c := collections.Collections[0]
+	if c.ID != "test" || c.StorageCRS != CRS84 || len(c.CRS) != 2 {
+		t.Errorf("expected the test collection but got %v", c)
+	}
+	if c.Extent == nil || len(c.Extent.Spatial.BBox) != 1 || c.Extent.Spatial.BBox[0][0] != 100 || c.Extent.Spatial.BBox[0][2] != 105 {
+		t.Errorf("expected the extent of the features but got %v", c.Extent)
+	}
+
+	// Success paging the items
+	var items testItems
+	get("/collections/test/items?limit=2", http.StatusOK, MediaTypeGeoJSON, &items)
+	if items.NumberMatched != 3 || items.NumberReturned != 2 || len(items.Features) != 2 {
+		t.Errorf("expected 2 of 3 features but got %v of %v", items.NumberReturned, items.NumberMatched)
+	}
+	if len(items.Links) != 3 || items.Links[2].Rel != "next" || !strings.HasPrefix(items.Links[2].Href, ts.URL+"/api/collections/test/items?") || !strings.Contains(items.Links[2].Href, "offset=2") {
+		t.Errorf("expected a next link but got %v", items.Links)
+	}
+	items = testItems{}
+	get("/collections/test/items?limit=2&offset=2", http.StatusOK, MediaTypeGeoJSON, &items)
+	if items.NumberReturned != 1 || len(items.Links) != 3 || items.Links[2].Rel != "prev" || !strings.Contains(items.Links[2].Href, "offset=0") {
+		t.Errorf("expected the last feature and a prev link but got %v", items)
+	}
+
+	// Success selecting by bbox and properties
+	items = testItems{}
+	get("/collections/test/items?bbox=99,-1,101.5,2", http.StatusOK, MediaTypeGeoJSON, &items)
+	if items.NumberMatched != 1 || items.Features[0].ID != 3.0 {
+		t.Errorf("expected the feature 3 but got %v", items.Features)
+	}
+	items = testItems{}
+	get("/collections/test/items?prop0=value0&prop1=0", http.StatusOK, MediaTypeGeoJSON, &items)
+	if items.NumberMatched != 1 || items.Features[0].ID != 2.0 {
+		t.Errorf("expected the feature 2 but got %v", items.Features)
+	}
+
+	// Success selecting with CQL2 filters
+	for _, filter := range []string{
+		"filter=" + url.QueryEscape("prop0 LIKE 'value%' AND prop1 = 0"),
+		"filter-lang=cql2-json&filter=" + url.QueryEscape(`{"op":"=","args":[{"property":"prop1"},0]}`),
+	} {
+		items = testItems{}
+		get("/collections/test/items?"+filter, http.StatusOK, MediaTypeGeoJSON, &items)
+		if items.NumberMatched != 1 || items.Features[0].ID != 2.0 {
+			t.Errorf("expected the feature 2 for %v but got %v", filter, items.Features)
+		}
+	}
+
+	// Success selecting with a Web Mercator bbox and returning Web Mercator
+	items = testItems{}
+	h := get("/collections/test/items?bbox=11354000,55000,11355000,56000&bbox-crs="+WebMercator+"&crs="+WebMercator, http.StatusOK, MediaTypeGeoJSON, &items)
+	if items.NumberMatched != 2 || items.Features[0].ID != "a" {
+		t.Errorf("expected the feature a but got %v", items.Features)
+	} else {
+		var p []float64
+		json.Unmarshal(items.Features[0].Geometry.Coordinates, &p)
+		if math.Abs(p[0]-11354588.06) > 0.01 || math.Abs(p[1]-55660.45) > 0.01 {
+			t.Errorf("expected a Web Mercator point but got %v", p)
+		}
+	}
+	if crs := h.Get("Content-Crs"); crs != "<"+WebMercator+">" {
+		t.Errorf("expected %q but got %q", WebMercator, crs)
+	}
+
+	// Success projecting again, leaving the features of the source as they are
+	items = testItems{}
+	get("/collections/test/items?bbox=11354000,55000,11355000,56000&bbox-crs="+WebMercator+"&crs="+WebMercator, http.StatusOK, MediaTypeGeoJSON, &items)
+	if items.NumberMatched != 2 {
+		t.Errorf("expected 2 features but got %v", items.Features)
+	} else {
+		var p []float64
+		json.Unmarshal(items.Features[0].Geometry.Coordinates, &p)
+		if math.Abs(p[0]-11354588.06) > 0.01 || math.Abs(p[1]-55660.45) > 0.01 {
+			t.Errorf("expected a Web Mercator point but got %v", p)
+		}
+	}
+
+	// Success getting a feature by ID with its links
+	var feature struct {
+		Type       string
+		ID         interface{}
+		Properties map[string]interface{}
+		Links      []Link
+	}
+	get("/collections/test/items/a", http.StatusOK, MediaTypeGeoJSON, &feature)
+	if feature.Type != "Feature" || feature.ID != "a" || feature.Properties["prop0"] != "value0" || len(feature.Links) != 2 {
+		t.Errorf("expected the feature a but got %v", feature)
+	}
+
+	// Fail on unknown paths and invalid parameters
+	var exception Exception
+	for path, status := range map[string]int{
+		"/collections/none":                                   http.StatusNotFound,
+		"/collections/test/other":                             http.StatusNotFound,
+		"/collections/test/items/b":                           http.StatusNotFound,
+		"/collections/test/items?limit=0":                     http.StatusBadRequest,
+		"/collections/test/items?offset=-1":                   http.StatusBadRequest,
+		"/collections/test/items?bbox=1,2,3":                  http.StatusBadRequest,
+		"/collections/test/items?crs=EPSG:27700":              http.StatusBadRequest,
+		"/collections/test/items?f=html":                      http.StatusNotAcceptable,
+		"/collections/test/items?filter=prop0":                http.StatusBadRequest,
+		"/collections/test/items?filter=true&filter-lang=sql": http.StatusBadRequest,
+	} {
+		exception = Exception{}
+		get(path, status, MediaTypeJSON, &exception)
+		if exception.Code != http.StatusText(status) {
+			t.Errorf("expected an exception for %v but got %v", path, exception)
+		}
+	}
+
+	// Fail adding a collection twice
+	if err := s.Add("test", NewMemorySource(testCollection)); !errors.Is(err, ErrDuplicateCollection) {
+		t.Errorf("expected %q but got %v", ErrDuplicateCollection, err)
+	}
+}
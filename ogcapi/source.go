@@ -0,0 +1,272 @@
+package ogcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/losinggeneration/geojson"
+	"github.com/losinggeneration/geojson/cql2"
+)
+
+// Query selects the features of an items request
+type Query struct {
+	// Limit is the maximum number of features returned
+	Limit int
+	// Offset is the number of matching features skipped
+	Offset int
+	// BBox is the minx, miny, maxx and maxy in CRS84 the features must
+	// intersect, nil to select every feature
+	BBox []float64
+	// Datetime is the instant or interval, in RFC 3339 and separated with
+	// a slash, the features must intersect, empty to select every feature
+	Datetime string
+	// Properties are the values the properties of the features must have
+	// formatted as strings
+	Properties map[string]string
+	// Filter is a CQL2 filter the features must match, empty to select every
+	// feature
+	Filter string
+	// FilterLang is the encoding of Filter, cql2-text or cql2-json, cql2-text
+	// when empty
+	FilterLang string
+}
+
+// Filter languages of Query.FilterLang
+const (
+	CQL2Text = "cql2-text"
+	CQL2JSON = "cql2-json"
+)
+
+// filter compiles the Filter of q, nil when there's none
+func (q Query) filter() (cql2.Predicate, error) {
+	switch {
+	case q.Filter == "":
+		return nil, nil
+	case q.FilterLang == "" || q.FilterLang == CQL2Text:
+		return cql2.ParseText(q.Filter)
+	case q.FilterLang == CQL2JSON:
+		return cql2.ParseJSON([]byte(q.Filter))
+	}
+	return nil, fmt.Errorf("unsupported filter-lang %q", q.FilterLang)
+}
+
+// FeatureSource provides the features of a collection. The features returned
+// are in CRS84 and must not be modified.
+type FeatureSource interface {
+	// Describe returns the title, description and extent of the collection.
+	// Its ID, links and CRSs are set by the Server.
+	Describe(ctx context.Context) (*Collection, error)
+	// Items returns a page of the features matching q and the number of
+	// features matching q across every page
+	Items(ctx context.Context, q Query) ([]geojson.Feature, int, error)
+	// Item returns the feature with the ID or ErrNotFound
+	Item(ctx context.Context, id string) (*geojson.Feature, error)
+}
+
+// MemorySource is a FeatureSource of a FeatureCollection held in memory.
+// Features without an ID are given their position in the collection,
+// starting from 1. As the features have no time, Query.Datetime is ignored.
+type MemorySource struct {
+	// Title and Description describe the collection
+	Title, Description string
+
+	features []geojson.Feature
+	ids      []string
+	bounds   geojson.BoundingBox
+	// boxes are the bounding boxes of the features, nil for those without a
+	// geometry
+	boxes []geojson.BoundingBox
+}
+
+// NewMemorySource returns a FeatureSource of the features of fc. The
+// collection must not be modified afterwards.
+func NewMemorySource(fc *geojson.FeatureCollection) *MemorySource {
+	m := &MemorySource{
+		features: make([]geojson.Feature, len(fc.Features)),
+		ids:      make([]string, len(fc.Features)),
+		boxes:    make([]geojson.BoundingBox, len(fc.Features)),
+	}
+	copy(m.features, fc.Features)
+
+	for i := range m.features {
+		f := &m.features[i]
+		if f.ID == nil {
+			f.ID = i + 1
+		}
+		m.ids[i] = FormatValue(f.ID)
+		if v := f.Geometry.Value(); v != nil {
+			m.boxes[i] = v.Bounds()
+		}
+		m.bounds = m.bounds.Union(m.boxes[i])
+	}
+	return m
+}
+
+// Describe returns the title, description and extent of the features
+func (m *MemorySource) Describe(ctx context.Context) (*Collection, error) {
+	c := &Collection{Title: m.Title, Description: m.Description}
+	if b := m.bounds; len(b) >= 4 {
+		d := len(b) / 2
+		c.Extent = &Extent{Spatial: &SpatialExtent{BBox: [][]float64{{b[0], b[1], b[d], b[d+1]}}, CRS: CRS84}}
+	}
+	return c, nil
+}
+
+// Items returns the features matching q in the order of the collection
+func (m *MemorySource) Items(ctx context.Context, q Query) ([]geojson.Feature, int, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page := []geojson.Feature{}
+	matched := 0
+	for i := range m.features {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		if q.BBox != nil && !intersects(q.BBox, m.boxes[i]) {
+			continue
+		}
+		if !matchProperties(m.features[i].Properties, q.Properties) {
+			continue
+		}
+		if filter != nil && !filter(&m.features[i]) {
+			continue
+		}
+
+		if matched >= q.Offset && len(page) < q.Limit {
+			page = append(page, m.features[i])
+		}
+		matched++
+	}
+	return page, matched, nil
+}
+
+// Item returns the feature whose ID formats as id
+func (m *MemorySource) Item(ctx context.Context, id string) (*geojson.Feature, error) {
+	for i := range m.features {
+		if m.ids[i] == id {
+			return &m.features[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// FileSource is a FeatureSource of a GeoJSON FeatureCollection file. The file
+// is read again whenever it changes.
+type FileSource struct {
+	// Title and Description describe the collection, Title defaults to the
+	// name of the file without its extension
+	Title, Description string
+
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	m       *MemorySource
+}
+
+// NewFileSource returns a FeatureSource of the file at path. The file is read
+// on the first request.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{
+		Title: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		path:  path,
+	}
+}
+
+// load returns the features of the file, reading it when it changed since
+// the last time
+func (f *FileSource) load() (*MemorySource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if f.m != nil && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return f.m, nil
+	}
+
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal(b, &fc); err != nil {
+		return nil, err
+	}
+
+	f.m, f.modTime, f.size = NewMemorySource(&fc), fi.ModTime(), fi.Size()
+	return f.m, nil
+}
+
+// Describe returns the title, description and extent of the file
+func (f *FileSource) Describe(ctx context.Context) (*Collection, error) {
+	m, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	c, err := m.Describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.Title, c.Description = f.Title, f.Description
+	return c, nil
+}
+
+// Items returns the features of the file matching q
+func (f *FileSource) Items(ctx context.Context, q Query) ([]geojson.Feature, int, error) {
+	m, err := f.load()
+	if err != nil {
+		return nil, 0, err
+	}
+	return m.Items(ctx, q)
+}
+
+// Item returns the feature of the file whose ID formats as id
+func (f *FileSource) Item(ctx context.Context, id string) (*geojson.Feature, error) {
+	m, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	return m.Item(ctx, id)
+}
+
+// FormatValue formats a property value or feature ID the way they're
+// compared to query parameters: strings as they are, null as an empty string
+// and anything else as JSON
+func FormatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// matchProperties reports whether every property has the value of the query
+func matchProperties(p geojson.Properties, values map[string]string) bool {
+	for k, v := range values {
+		if FormatValue(p[k]) != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package ogcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemorySource(t *testing.T) {
+	m := NewMemorySource(testCollection)
+	ctx := context.Background()
+
+	// Success paging past the matching features
+	features, matched, err := m.Items(ctx, Query{Limit: 10, Offset: 5})
+	if err != nil {
+		t.Error(err)
+	} else if matched != 3 || len(features) != 0 {
+		t.Errorf("expected no features of 3 but got %v of %v", len(features), matched)
+	}
+
+	// Success finding features without an ID by position
+	if f, err := m.Item(ctx, "2"); err != nil {
+		t.Error(err)
+	} else if f.Geometry.LineString == nil {
+		t.Errorf("expected the LineString but got %v", f.Geometry)
+	}
+
+	// Fail to find a missing feature
+	if _, err := m.Item(ctx, "4"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %q but got %v", ErrNotFound, err)
+	}
+
+	// Fail when the context is done
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := m.Items(cancelled, Query{Limit: 10}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %q but got %v", context.Canceled, err)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "places.geojson")
+	b, err := json.Marshal(testCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFileSource(path)
+	ctx := context.Background()
+
+	// Success describing the file
+	if c, err := f.Describe(ctx); err != nil {
+		t.Error(err)
+	} else if c.Title != "places" {
+		t.Errorf("expected %q but got %q", "places", c.Title)
+	}
+
+	// Success reading the file again when it changes
+	if err := os.WriteFile(path, []byte(`{"type":"FeatureCollection","features":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Hour))
+	if _, matched, err := f.Items(ctx, Query{Limit: 10}); err != nil {
+		t.Error(err)
+	} else if matched != 0 {
+		t.Errorf("expected no features but got %v", matched)
+	}
+
+	// Fail on a missing file
+	if _, err := NewFileSource(path+".missing").Item(ctx, "1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected %q but got %v", os.ErrNotExist, err)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	for v, expected := range map[interface{}]string{
+		nil:     "",
+		"value": "value",
+		1.5:     "1.5",
+		1e21:    "1000000000000000000000",
+		true:    "true",
+	} {
+		if s := FormatValue(v); s != expected {
+			t.Errorf("expected %q but got %q", expected, s)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPropertyNotFound happens when getting a property that's missing or
+	// null without a default
+	ErrPropertyNotFound = errors.New("property not found")
+	// ErrPropertyType happens when a property isn't of the type asked for
+	ErrPropertyType = errors.New("property of the wrong type")
+	// ErrInvalidPath happens when a property path can't be parsed or doesn't
+	// fit the properties, such as indexing an object
+	ErrInvalidPath = errors.New("invalid property path")
+	// ErrInvalidProperties happens when encoding properties from a value that
+	// isn't a JSON object
+	ErrInvalidProperties = errors.New("properties must be a JSON object")
+)
+
+// Get returns the property at path. A path is made of keys separated by dots
+// and indexes of arrays in brackets, such as "address.lines[0]". Keys holding
+// dots or brackets can be quoted in brackets, such as `tags["addr:street"]`.
+func (p Properties) Get(path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{} = map[string]interface{}(p)
+	for i, s := range segments {
+		if nested, ok := v.(Properties); ok {
+			v = map[string]interface{}(nested)
+		}
+		switch c := v.(type) {
+		case map[string]interface{}:
+			if s.index >= 0 {
+				return nil, pathError(path, segments[:i+1], "not an array")
+			}
+			var ok bool
+			if v, ok = c[s.key]; !ok {
+				return nil, fmt.Errorf("%w: %q", ErrPropertyNotFound, path)
+			}
+		case []interface{}:
+			if s.index < 0 {
+				return nil, pathError(path, segments[:i+1], "not an object")
+			}
+			if s.index >= len(c) {
+				return nil, fmt.Errorf("%w: %q", ErrPropertyNotFound, path)
+			}
+			v = c[s.index]
+		case nil:
+			return nil, fmt.Errorf("%w: %q", ErrPropertyNotFound, path)
+		default:
+			return nil, pathError(path, segments[:i+1], fmt.Sprintf("%T has no members", c))
+		}
+	}
+	return v, nil
+}
+
+// maxGrowth is the most nulls Set grows an array by to fit an index
+const maxGrowth = 1024
+
+// Set sets the property at path, a path as Get takes it, creating the
+// objects and arrays leading to it. Arrays are grown with nulls to fit an
+// index, up to 1024 past their length.
+func (p *Properties) Set(path string, v interface{}) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if segments[0].index >= 0 {
+		return pathError(path, segments[:1], "not an array")
+	}
+	if *p == nil {
+		*p = make(Properties)
+	}
+
+	root, err := setPath(map[string]interface{}(*p), segments, v, path, 0)
+	if err != nil {
+		return err
+	}
+	*p = Properties(root.(map[string]interface{}))
+	return nil
+}
+
+// setPath sets v in the container c at segments[i:], returning c or the
+// container replacing it
+func setPath(c interface{}, segments []pathSegment, v interface{}, path string, i int) (interface{}, error) {
+	if i == len(segments) {
+		return v, nil
+	}
+	s := segments[i]
+
+	if c == nil {
+		if s.index >= 0 {
+			c = []interface{}{}
+		} else {
+			c = map[string]interface{}{}
+		}
+	}
+
+	switch c := c.(type) {
+	case Properties:
+		m, err := setPath(map[string]interface{}(c), segments, v, path, i)
+		if err != nil {
+			return nil, err
+		}
+		return Properties(m.(map[string]interface{})), nil
+	case map[string]interface{}:
+		if s.index >= 0 {
+			return nil, pathError(path, segments[:i+1], "not an array")
+		}
+		e, err := setPath(c[s.key], segments, v, path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		c[s.key] = e
+		return c, nil
+	case []interface{}:
+		if s.index < 0 {
+			return nil, pathError(path, segments[:i+1], "not an object")
+		}
+		if s.index-len(c) >= maxGrowth {
+			return nil, pathError(path, segments[:i+1], fmt.Sprintf("more than %d past the end of the array", maxGrowth))
+		}
+		for len(c) <= s.index {
+			c = append(c, nil)
+		}
+		e, err := setPath(c[s.index], segments, v, path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		c[s.index] = e
+		return c, nil
+	}
+	return nil, pathError(path, segments[:i+1], fmt.Sprintf("%T has no members", c))
+}
+
+// value returns the property at path or ErrPropertyNotFound when it's null
+func (p Properties) value(path string) (interface{}, error) {
+	v, err := p.Get(path)
+	if err == nil && v == nil {
+		err = fmt.Errorf("%w: %q is null", ErrPropertyNotFound, path)
+	}
+	return v, err
+}
+
+// GetString returns the string at path. When the property is missing or
+// null, def is returned if given.
+func (p Properties) GetString(path string, def ...string) (string, error) {
+	v, err := p.value(path)
+	if err != nil {
+		return orDefault(def, err)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", typeError(path, "a string", v)
+	}
+	return s, nil
+}
+
+// GetFloat returns the number at path. When the property is missing or null,
+// def is returned if given.
+func (p Properties) GetFloat(path string, def ...float64) (float64, error) {
+	v, err := p.value(path)
+	if err != nil {
+		return orDefault(def, err)
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return 0, typeError(path, "a number", v)
+}
+
+// GetInt returns the integer at path, failing for numbers with a fraction or
+// out of the range of an int. When the property is missing or null, def is
+// returned if given.
+func (p Properties) GetInt(path string, def ...int) (int, error) {
+	v, err := p.value(path)
+	if err != nil {
+		return orDefault(def, err)
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		if n >= math.MinInt && n <= math.MaxInt {
+			return int(n), nil
+		}
+	case json.Number:
+		if i, err := strconv.ParseInt(string(n), 10, strconv.IntSize); err == nil {
+			return int(i), nil
+		}
+		if f, err := n.Float64(); err == nil && f == math.Trunc(f) && f >= math.MinInt && f < math.MaxInt {
+			return int(f), nil
+		}
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt && n < math.MaxInt {
+			return int(n), nil
+		}
+	}
+	return 0, typeError(path, "an integer", v)
+}
+
+// GetBool returns the boolean at path. When the property is missing or null,
+// def is returned if given.
+func (p Properties) GetBool(path string, def ...bool) (bool, error) {
+	v, err := p.value(path)
+	if err != nil {
+		return orDefault(def, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, typeError(path, "a boolean", v)
+	}
+	return b, nil
+}
+
+// GetTime returns the time at path, a time.Time or a string of an RFC 3339
+// timestamp or date. When the property is missing or null, def is returned if
+// given.
+func (p Properties) GetTime(path string, def ...time.Time) (time.Time, error) {
+	v, err := p.value(path)
+	if err != nil {
+		return orDefault(def, err)
+	}
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, typeError(path, "a time", v)
+}
+
+// GetSlice returns the array at path. When the property is missing or null,
+// def is returned if given.
+func (p Properties) GetSlice(path string, def ...[]interface{}) ([]interface{}, error) {
+	v, err := p.value(path)
+	if err != nil {
+		return orDefault(def, err)
+	}
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil, typeError(path, "an array", v)
+	}
+	return s, nil
+}
+
+// decodeProperties decodes the JSON object b keeping the numbers that
+// float64 can't hold exactly as a json.Number
+func decodeProperties(b []byte) (Properties, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	switch m := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return Properties(exactNumbers(m).(map[string]interface{})), nil
+	}
+	return nil, fmt.Errorf("%w: got %s", ErrInvalidProperties, bytes.TrimSpace(b))
+}
+
+// exactNumbers turns the json.Numbers of v into float64 where it holds them
+// exactly
+func exactNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v
+		}
+		if i, err := v.Int64(); err == nil && int64(f) != i {
+			return v
+		}
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = exactNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = exactNumbers(e)
+		}
+	}
+	return v
+}
+
+// orDefault returns the first of def for a missing property or the error
+func orDefault[T any](def []T, err error) (T, error) {
+	var zero T
+	if len(def) > 0 && errors.Is(err, ErrPropertyNotFound) {
+		return def[0], nil
+	}
+	return zero, err
+}
+
+func typeError(path, expected string, v interface{}) error {
+	return fmt.Errorf("%w: %q is %T, not %s", ErrPropertyType, path, v, expected)
+}
+
+// pathSegment is an object key or, when index isn't negative, an array index
+// of a property path
+type pathSegment struct {
+	key   string
+	index int
+}
+
+func (s pathSegment) String() string {
+	if s.index >= 0 {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	if strings.ContainsAny(s.key, `.[]"`) {
+		return "[" + strconv.Quote(s.key) + "]"
+	}
+	return "." + s.key
+}
+
+func pathError(path string, at []pathSegment, msg string) error {
+	var b strings.Builder
+	for _, s := range at {
+		b.WriteString(s.String())
+	}
+	return fmt.Errorf("%w: %q: %s is %s", ErrInvalidPath, path, strings.TrimPrefix(b.String(), "."), msg)
+}
+
+// parsePath splits a property path into its segments
+func parsePath(path string) ([]pathSegment, error) {
+	invalid := func(offset int, msg string) error {
+		return fmt.Errorf("%w: %q at %d: %s", ErrInvalidPath, path, offset, msg)
+	}
+
+	var segments []pathSegment
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if path[i+1:i+min(2, len(path)-i)] == `"` {
+				// the closing bracket of a quoted key follows its closing quote
+				end = quotedEnd(path[i+1:])
+				if end < 0 || i+1+end >= len(path) || path[i+1+end] != ']' {
+					return nil, invalid(i, "unterminated quoted key")
+				}
+				key, err := strconv.Unquote(path[i+1 : i+1+end])
+				if err != nil {
+					return nil, invalid(i+1, err.Error())
+				}
+				segments = append(segments, pathSegment{key: key, index: -1})
+				i += end + 2
+				break
+			}
+			if end < 0 {
+				return nil, invalid(i, "missing ]")
+			}
+			n, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || n < 0 {
+				return nil, invalid(i+1, "index must be a non negative integer")
+			}
+			segments = append(segments, pathSegment{index: n})
+			i += end + 1
+		default:
+			if len(segments) > 0 {
+				if path[i] != '.' {
+					return nil, invalid(i, "expected . or [")
+				}
+				i++
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, invalid(i, "missing key")
+			}
+			segments = append(segments, pathSegment{key: path[i : i+end], index: -1})
+			i += end
+		}
+	}
+	if len(segments) == 0 {
+		return nil, invalid(0, "empty path")
+	}
+	return segments, nil
+}
+
+// quotedEnd returns the length of the double quoted string s starts with or
+// -1 when it's unterminated
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,150 @@
+package geojson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testProperties = Properties{
+	"name":     "Main Street",
+	"lanes":    2.0,
+	"width":    7.5,
+	"oneway":   true,
+	"opened":   "2015-06-01T12:00:00Z",
+	"surveyed": "2020-01-31",
+	"closed":   nil,
+	"tags":     []interface{}{"paved", "lit"},
+	"address":  map[string]interface{}{"lines": []interface{}{"1 Main Street", "Springfield"}, "zip": 12345.0},
+	"a.b":      map[string]interface{}{"c": 1.0},
+}
+
+func TestPropertiesGet(t *testing.T) {
+	p := testProperties
+
+	// Success looking up paths
+	for path, expected := range map[string]interface{}{
+		"name":             "Main Street",
+		"address.lines[1]": "Springfield",
+		"address.zip":      12345.0,
+		`["address"].zip`:  12345.0,
+		`["a.b"].c`:        1.0,
+		"tags[0]":          "paved",
+		"closed":           nil,
+	} {
+		if v, err := p.Get(path); err != nil {
+			t.Errorf("expected %q to be found but got %v", path, err)
+		} else if v != expected {
+			t.Errorf("expected %v for %q but got %v", expected, path, v)
+		}
+	}
+
+	// Fail on missing properties
+	for _, path := range []string{"missing", "address.missing", "tags[2]", "closed.x"} {
+		if _, err := p.Get(path); !errors.Is(err, ErrPropertyNotFound) {
+			t.Errorf("expected %q for %q but got %v", ErrPropertyNotFound, path, err)
+		}
+	}
+
+	// Fail on invalid paths
+	for _, path := range []string{"", ".name", "name.", "a..b", "tags[", "tags[-1]", "tags[x]", "tags[0]x", `["a.b`, `["a.b"`, "name.x", "tags.x", "address[0]"} {
+		if _, err := p.Get(path); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("expected %q for %q but got %v", ErrInvalidPath, path, err)
+		}
+	}
+}
+
+func TestPropertiesGetTyped(t *testing.T) {
+	p := testProperties
+
+	// Success getting values of their type
+	if s, err := p.GetString("address.lines[0]"); err != nil || s != "1 Main Street" {
+		t.Errorf("expected %q but got %q, %v", "1 Main Street", s, err)
+	}
+	if f, err := p.GetFloat("width"); err != nil || f != 7.5 {
+		t.Errorf("expected 7.5 but got %v, %v", f, err)
+	}
+	if i, err := p.GetInt("address.zip"); err != nil || i != 12345 {
+		t.Errorf("expected 12345 but got %v, %v", i, err)
+	}
+	if b, err := p.GetBool("oneway"); err != nil || !b {
+		t.Errorf("expected true but got %v, %v", b, err)
+	}
+	if tm, err := p.GetTime("opened"); err != nil || !tm.Equal(time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 2015-06-01T12:00:00Z but got %v, %v", tm, err)
+	}
+	if tm, err := p.GetTime("surveyed"); err != nil || !tm.Equal(time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 2020-01-31 but got %v, %v", tm, err)
+	}
+	if s, err := p.GetSlice("tags"); err != nil || !reflect.DeepEqual(s, []interface{}{"paved", "lit"}) {
+		t.Errorf("expected the tags but got %v, %v", s, err)
+	}
+
+	// Success falling back to the default for missing and null properties
+	if s, err := p.GetString("missing", "none"); err != nil || s != "none" {
+		t.Errorf("expected %q but got %q, %v", "none", s, err)
+	}
+	if i, err := p.GetInt("closed", -1); err != nil || i != -1 {
+		t.Errorf("expected -1 but got %v, %v", i, err)
+	}
+
+	// Fail on missing properties without a default
+	if _, err := p.GetBool("closed"); !errors.Is(err, ErrPropertyNotFound) {
+		t.Errorf("expected %q but got %v", ErrPropertyNotFound, err)
+	}
+
+	// Fail on values of another type, even with a default
+	if _, err := p.GetString("lanes", "none"); !errors.Is(err, ErrPropertyType) {
+		t.Errorf("expected %q but got %v", ErrPropertyType, err)
+	}
+	if _, err := p.GetInt("width"); !errors.Is(err, ErrPropertyType) {
+		t.Errorf("expected %q but got %v", ErrPropertyType, err)
+	}
+	if _, err := p.GetTime("name"); !errors.Is(err, ErrPropertyType) {
+		t.Errorf("expected %q but got %v", ErrPropertyType, err)
+	}
+	if _, err := p.GetSlice("address"); !errors.Is(err, ErrPropertyType) {
+		t.Errorf("expected %q but got %v", ErrPropertyType, err)
+	}
+}
+
+func TestPropertiesSet(t *testing.T) {
+	// Success creating the objects and arrays on the way
+	var p Properties
+	for path, v := range map[string]interface{}{
+		"name":             "Main Street",
+		"address.lines[1]": "Springfield",
+		`tags["a.b"]`:      true,
+	} {
+		if err := p.Set(path, v); err != nil {
+			t.Errorf("expected %q to be set but got %v", path, err)
+		}
+	}
+	expected := Properties{
+		"name":    "Main Street",
+		"address": map[string]interface{}{"lines": []interface{}{nil, "Springfield"}},
+		"tags":    map[string]interface{}{"a.b": true},
+	}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("expected %v but got %v", expected, p)
+	}
+
+	// Success replacing a value
+	if err := p.Set("address.lines[0]", "1 Main Street"); err != nil {
+		t.Error(err)
+	}
+	if s, _ := p.GetString("address.lines[0]"); s != "1 Main Street" {
+		t.Errorf("expected %q but got %q", "1 Main Street", s)
+	}
+	if err := p.Set("big[1023]", 1); err != nil {
+		t.Errorf("expected nil but got %v", err)
+	}
+
+	// Fail setting through values that aren't objects or arrays
+	for _, path := range []string{"[0]", "name.first", "address[0]", "address.lines.x", "a..b", "address.lines[99999999999]", "list[1024]"} {
+		if err := p.Set(path, 1); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("expected %q for %q but got %v", ErrInvalidPath, path, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package style
+
+import "math"
+
+func compileNot(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, 1); err != nil {
+		return nil, err
+	}
+	e, err := compile(args[0], scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		b, err := evalBoolean(c, e)
+		return !b, err
+	}, nil
+}
+
+// comparison compiles ==, !=, <, <=, > and >=
+func comparison(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 2, 2); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		a, err := es[0](c)
+		if err != nil {
+			return nil, err
+		}
+		b, err := es[1](c)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case "==":
+			return equal(a, b), nil
+		case "!=":
+			return !equal(a, b), nil
+		}
+
+		var cmp int
+		switch a := a.(type) {
+		case float64:
+			b, ok := b.(float64)
+			if !ok {
+				return nil, incomparable(name, a, b)
+			}
+			if math.IsNaN(a) || math.IsNaN(b) {
+				return false, nil
+			}
+			cmp = compareOrdered(a, b)
+		case string:
+			b, ok := b.(string)
+			if !ok {
+				return nil, incomparable(name, a, b)
+			}
+			cmp = compareOrdered(a, b)
+		default:
+			return nil, incomparable(name, a, b)
+		}
+
+		switch name {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	}, nil
+}
+
+func compareOrdered[T float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// incomparable returns the error of ordering values that aren't both
+// numbers or both strings
+func incomparable(name string, a, b interface{}) error {
+	return evalError(`expected arguments for "%s" to be (string, string) or (number, number), but found (%s, %s) instead`, name, typeOf(a), typeOf(b))
+}
+
+func compileAll(name string, args []interface{}, scope []string) (expr, error) {
+	return logical(name, args, scope, false)
+}
+
+func compileAny(name string, args []interface{}, scope []string) (expr, error) {
+	return logical(name, args, scope, true)
+}
+
+// logical compiles all and any, returning stop as soon as an operand is stop
+func logical(name string, args []interface{}, scope []string, stop bool) (expr, error) {
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		for _, e := range es {
+			b, err := evalBoolean(c, e)
+			if err != nil {
+				return nil, err
+			}
+			if b == stop {
+				return stop, nil
+			}
+		}
+		return !stop, nil
+	}, nil
+}
+
+// compileCase compiles ["case", condition, output, ..., fallback]
+func compileCase(name string, args []interface{}, scope []string) (expr, error) {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return nil, invalid("case takes condition and output pairs followed by a fallback")
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		for i := 0; i+1 < len(es); i += 2 {
+			b, err := evalBoolean(c, es[i])
+			if err != nil {
+				return nil, err
+			}
+			if b {
+				return es[i+1](c)
+			}
+		}
+		return es[len(es)-1](c)
+	}, nil
+}
+
+// compileCoalesce compiles coalesce, returning the first argument that isn't
+// null
+func compileCoalesce(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, -1); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		for _, e := range es {
+			v, err := e(c)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				return v, nil
+			}
+		}
+		return nil, nil
+	}, nil
+}
+
+// compileMatch compiles ["match", input, labels, output, ..., fallback]
+// where labels are a literal string or integer or an array of them
+func compileMatch(name string, args []interface{}, scope []string) (expr, error) {
+	if len(args) < 4 || len(args)%2 != 0 {
+		return nil, invalid("match takes an input, label and output pairs and a fallback")
+	}
+	input, err := compile(args[0], scope)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make(map[interface{}]int)
+	var outputs []expr
+	var labelType string
+	for i := 1; i+1 < len(args); i += 2 {
+		labels, ok := args[i].([]interface{})
+		if !ok {
+			labels = []interface{}{args[i]}
+		}
+		if len(labels) == 0 {
+			return nil, invalid("match branches need at least one label")
+		}
+		for _, l := range labels {
+			l = normalize(l)
+			switch l := l.(type) {
+			case float64:
+				if l != math.Trunc(l) {
+					return nil, invalid("match numeric labels must be integers but got %s", formatNumber(l))
+				}
+			case string:
+			default:
+				return nil, invalid("match labels must be strings or numbers but got %s", typeOf(l))
+			}
+			if labelType == "" {
+				labelType = typeOf(l)
+			} else if labelType != typeOf(l) {
+				return nil, invalid("match labels must all be %ss", labelType)
+			}
+			if _, ok := branches[l]; ok {
+				return nil, invalid("match has a duplicate label %s", toString(l))
+			}
+			branches[l] = len(outputs)
+		}
+		o, err := compile(args[i+1], scope)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, o)
+	}
+	fallback, err := compile(args[len(args)-1], scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *context) (interface{}, error) {
+		v, err := input(c)
+		if err != nil {
+			return nil, err
+		}
+		switch v.(type) {
+		case float64, string:
+			if i, ok := branches[v]; ok {
+				return outputs[i](c)
+			}
+		}
+		return fallback(c)
+	}, nil
+}
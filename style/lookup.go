@@ -0,0 +1,272 @@
+package style
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// compileGet compiles ["get", name] of the feature properties and
+// ["get", name, object]
+func compileGet(name string, args []interface{}, scope []string) (expr, error) {
+	return property(name, args, scope, func(v interface{}, ok bool) interface{} {
+		return v
+	})
+}
+
+// compileHas compiles ["has", name] of the feature properties and
+// ["has", name, object]
+func compileHas(name string, args []interface{}, scope []string) (expr, error) {
+	return property(name, args, scope, func(v interface{}, ok bool) interface{} {
+		return ok
+	})
+}
+
+// property compiles an operator looking up a property with fn returning its
+// result from the value and whether the property was there
+func property(name string, args []interface{}, scope []string, fn func(v interface{}, ok bool) interface{}) (expr, error) {
+	if err := arity(name, args, 1, 2); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		key, err := evalString(c, es[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(es) == 1 {
+			v, ok := c.feature.Properties[key]
+			return fn(normalize(v), ok), nil
+		}
+		o, err := es[1](c)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := o.(map[string]interface{})
+		if !ok {
+			return nil, evalError("expected value to be of type object, but found %s instead", typeOf(o))
+		}
+		v, ok := m[key]
+		return fn(v, ok), nil
+	}, nil
+}
+
+// compileAt compiles ["at", index, array]
+func compileAt(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 2, 2); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		i, err := evalNumber(c, es[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := es[1](c)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := v.([]interface{})
+		if !ok {
+			return nil, evalError("expected value to be an array but found %s instead", typeOf(v))
+		}
+		switch {
+		case i < 0:
+			return nil, evalError("array index out of bounds: %s < 0", formatNumber(i))
+		case i >= float64(len(a)):
+			return nil, evalError("array index out of bounds: %s > %d", formatNumber(i), len(a)-1)
+		case i != float64(int(i)):
+			return nil, evalError("array index must be an integer, but found %s instead", formatNumber(i))
+		}
+		return a[int(i)], nil
+	}, nil
+}
+
+// compileIn compiles ["in", needle, haystack]
+func compileIn(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 2, 2); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		i, err := indexOf(c, es[0], es[1], 0)
+		return i >= 0, err
+	}, nil
+}
+
+// compileIndexOf compiles ["index-of", needle, haystack] and
+// ["index-of", needle, haystack, from]
+func compileIndexOf(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 2, 3); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		from := 0.0
+		if len(es) == 3 {
+			if from, err = evalNumber(c, es[2]); err != nil {
+				return nil, err
+			}
+		}
+		i, err := indexOf(c, es[0], es[1], from)
+		if err != nil {
+			return nil, err
+		}
+		return float64(i), nil
+	}, nil
+}
+
+// indexOf returns the index of needle in the string or array haystack from
+// an index or -1. A string index counts characters.
+func indexOf(c *context, needle, haystack expr, from float64) (int, error) {
+	n, err := needle(c)
+	if err != nil {
+		return -1, err
+	}
+	switch n.(type) {
+	case nil, bool, float64, string:
+	default:
+		return -1, evalError("expected first argument to be of type boolean, string, number or null, but found %s instead", typeOf(n))
+	}
+	h, err := haystack(c)
+	if err != nil {
+		return -1, err
+	}
+
+	start := max(int(from), 0)
+	switch h := h.(type) {
+	case string:
+		s, ok := n.(string)
+		if !ok {
+			return -1, nil
+		}
+		runes := []rune(h)
+		if start > len(runes) {
+			return -1, nil
+		}
+		i := strings.Index(string(runes[start:]), s)
+		if i < 0 {
+			return -1, nil
+		}
+		return start + utf8.RuneCountInString(string(runes[start:])[:i]), nil
+	case []interface{}:
+		for i := start; i < len(h); i++ {
+			if equal(h[i], n) {
+				return i, nil
+			}
+		}
+		return -1, nil
+	}
+	return -1, evalError("expected second argument to be of type array or string, but found %s instead", typeOf(h))
+}
+
+// compileSlice compiles ["slice", input, start] and
+// ["slice", input, start, end] of a string or an array
+func compileSlice(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 2, 3); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		v, err := es[0](c)
+		if err != nil {
+			return nil, err
+		}
+		var n int
+		switch v := v.(type) {
+		case string:
+			n = utf8.RuneCountInString(v)
+		case []interface{}:
+			n = len(v)
+		default:
+			return nil, evalError("expected first argument to be of type array or string, but found %s instead", typeOf(v))
+		}
+
+		start, err := evalNumber(c, es[1])
+		if err != nil {
+			return nil, err
+		}
+		end := float64(n)
+		if len(es) == 3 {
+			if end, err = evalNumber(c, es[2]); err != nil {
+				return nil, err
+			}
+		}
+		i, j := sliceIndex(start, n), sliceIndex(end, n)
+		j = max(i, j)
+
+		if s, ok := v.(string); ok {
+			return string([]rune(s)[i:j]), nil
+		}
+		return append([]interface{}{}, v.([]interface{})[i:j]...), nil
+	}, nil
+}
+
+// sliceIndex returns the index i of a sequence of length n, counting from the
+// end when negative
+func sliceIndex(i float64, n int) int {
+	k := int(i)
+	if k < 0 {
+		k += n
+	}
+	return min(max(k, 0), n)
+}
+
+// length returns the number of characters of a string or items of an array
+func length(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, evalError("expected an array or string, but found %s instead", typeOf(v))
+}
+
+// compileConcat compiles concat, joining its arguments as to-string converts
+// them
+func compileConcat(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, -1); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		var b strings.Builder
+		for _, e := range es {
+			v, err := e(c)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(toString(v))
+		}
+		return b.String(), nil
+	}, nil
+}
+
+// stringOp returns an operator of a single string
+func stringOp(fn func(string) string) operator {
+	return unary(func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, evalError("expected value to be of type string, but found %s instead", typeOf(v))
+		}
+		return fn(s), nil
+	})
+}
@@ -0,0 +1,102 @@
+package style
+
+import "math"
+
+// mathOperators are the operators of numbers
+var mathOperators = map[string]operator{
+	"+": variadic(func(a, b float64) float64 { return a + b }),
+	"*": variadic(func(a, b float64) float64 { return a * b }),
+	"-": compileMinus,
+	"/": binary(func(a, b float64) float64 { return a / b }),
+	"%": binary(math.Mod),
+	"^": binary(math.Pow),
+
+	"min": variadic(math.Min),
+	"max": variadic(math.Max),
+
+	"abs":   function(math.Abs),
+	"ceil":  function(math.Ceil),
+	"floor": function(math.Floor),
+	"round": function(math.Round),
+	"sqrt":  function(math.Sqrt),
+	"ln":    function(math.Log),
+	"log10": function(math.Log10),
+	"log2":  function(math.Log2),
+	"sin":   function(math.Sin),
+	"cos":   function(math.Cos),
+	"tan":   function(math.Tan),
+	"asin":  function(math.Asin),
+	"acos":  function(math.Acos),
+	"atan":  function(math.Atan),
+
+	"e":   constant(math.E),
+	"pi":  constant(math.Pi),
+	"ln2": constant(math.Ln2),
+}
+
+// constant returns an operator without arguments returning f
+func constant(f float64) operator {
+	return nullary(func(*context) interface{} { return f })
+}
+
+// numbers compiles the arguments of an operator of numbers
+func numbers(name string, args []interface{}, scope []string, min, max int, fn func(fs []float64) float64) (expr, error) {
+	if err := arity(name, args, min, max); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		fs := make([]float64, len(es))
+		for i, e := range es {
+			if fs[i], err = evalNumber(c, e); err != nil {
+				return nil, err
+			}
+		}
+		return fn(fs), nil
+	}, nil
+}
+
+// function returns an operator of a single number
+func function(fn func(float64) float64) operator {
+	return func(name string, args []interface{}, scope []string) (expr, error) {
+		return numbers(name, args, scope, 1, 1, func(fs []float64) float64 {
+			return fn(fs[0])
+		})
+	}
+}
+
+// binary returns an operator of two numbers
+func binary(fn func(a, b float64) float64) operator {
+	return func(name string, args []interface{}, scope []string) (expr, error) {
+		return numbers(name, args, scope, 2, 2, func(fs []float64) float64 {
+			return fn(fs[0], fs[1])
+		})
+	}
+}
+
+// variadic returns an operator folding one or more numbers with fn
+func variadic(fn func(a, b float64) float64) operator {
+	return func(name string, args []interface{}, scope []string) (expr, error) {
+		return numbers(name, args, scope, 1, -1, func(fs []float64) float64 {
+			v := fs[0]
+			for _, f := range fs[1:] {
+				v = fn(v, f)
+			}
+			return v
+		})
+	}
+}
+
+// compileMinus compiles the negation ["-", a] and the subtraction
+// ["-", a, b]
+func compileMinus(name string, args []interface{}, scope []string) (expr, error) {
+	return numbers(name, args, scope, 1, 2, func(fs []float64) float64 {
+		if len(fs) == 1 {
+			return -fs[0]
+		}
+		return fs[0] - fs[1]
+	})
+}
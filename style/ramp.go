@@ -0,0 +1,293 @@
+package style
+
+import (
+	"math"
+	"sort"
+)
+
+// stops are the ascending literal inputs of a ramp and their outputs
+type stops struct {
+	inputs  []float64
+	outputs []expr
+}
+
+// compileStops compiles the input and output pairs of args
+func compileStops(name string, args []interface{}, scope []string) (*stops, error) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return nil, invalid("%s takes stop input and output pairs", name)
+	}
+	s := &stops{}
+	for i := 0; i < len(args); i += 2 {
+		f, ok := normalize(args[i]).(float64)
+		if !ok {
+			return nil, invalid("%s stop inputs must be literal numbers", name)
+		}
+		if n := len(s.inputs); n > 0 && f <= s.inputs[n-1] {
+			return nil, invalid("%s stop inputs must be in strictly ascending order", name)
+		}
+		o, err := compile(args[i+1], scope)
+		if err != nil {
+			return nil, err
+		}
+		s.inputs = append(s.inputs, f)
+		s.outputs = append(s.outputs, o)
+	}
+	return s, nil
+}
+
+// index returns the index of the last stop whose input is less than or equal
+// to f, 0 when there isn't any
+func (s *stops) index(f float64) int {
+	i := sort.Search(len(s.inputs), func(i int) bool { return s.inputs[i] > f })
+	return max(i-1, 0)
+}
+
+// compileStep compiles ["step", input, output, stop, output, ...]
+func compileStep(name string, args []interface{}, scope []string) (expr, error) {
+	if len(args) < 2 || len(args)%2 != 0 {
+		return nil, invalid("step takes an input, an output and stop input and output pairs")
+	}
+	input, err := compile(args[0], scope)
+	if err != nil {
+		return nil, err
+	}
+	// the first output is a stop at -Infinity
+	s, err := compileStops(name, append([]interface{}{math.Inf(-1)}, args[1:]...), scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		f, err := evalNumber(c, input)
+		if err != nil {
+			return nil, err
+		}
+		return s.outputs[s.index(f)](c)
+	}, nil
+}
+
+// compileInterpolate compiles ["interpolate", type, input, stop, output, ...]
+// where type is ["linear"], ["exponential", base] or
+// ["cubic-bezier", x1, y1, x2, y2]
+func compileInterpolate(name string, args []interface{}, scope []string) (expr, error) {
+	if len(args) < 4 || len(args)%2 != 0 {
+		return nil, invalid("interpolate takes a type, an input and stop input and output pairs")
+	}
+	factor, err := interpolation(args[0])
+	if err != nil {
+		return nil, err
+	}
+	input, err := compile(args[1], scope)
+	if err != nil {
+		return nil, err
+	}
+	s, err := compileStops(name, args[2:], scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *context) (interface{}, error) {
+		f, err := evalNumber(c, input)
+		if err != nil {
+			return nil, err
+		}
+		n := len(s.inputs)
+		switch {
+		case f <= s.inputs[0]:
+			return s.outputs[0](c)
+		case f >= s.inputs[n-1]:
+			return s.outputs[n-1](c)
+		}
+
+		i := s.index(f)
+		lower, upper := s.inputs[i], s.inputs[i+1]
+		t := factor(f, lower, upper)
+		a, err := s.outputs[i](c)
+		if err != nil {
+			return nil, err
+		}
+		b, err := s.outputs[i+1](c)
+		if err != nil {
+			return nil, err
+		}
+		return interpolate(a, b, t)
+	}, nil
+}
+
+// interpolation returns the function of the progress between two stops of
+// an interpolation type
+func interpolation(v interface{}) (func(f, lower, upper float64) float64, error) {
+	a, ok := v.([]interface{})
+	if !ok || len(a) == 0 {
+		return nil, invalid("interpolation type must be an array")
+	}
+	params := make([]float64, len(a)-1)
+	for i, p := range a[1:] {
+		if params[i], ok = normalize(p).(float64); !ok {
+			return nil, invalid("interpolation parameters must be literal numbers")
+		}
+	}
+
+	switch t, _ := a[0].(string); {
+	case t == "linear" && len(params) == 0:
+		return func(f, lower, upper float64) float64 {
+			return exponential(f, 1, lower, upper)
+		}, nil
+	case t == "exponential" && len(params) == 1:
+		base := params[0]
+		return func(f, lower, upper float64) float64 {
+			return exponential(f, base, lower, upper)
+		}, nil
+	case t == "cubic-bezier" && len(params) == 4:
+		for _, x := range []float64{params[0], params[2]} {
+			if x < 0 || x > 1 {
+				return nil, invalid("cubic-bezier control point x values must be between 0 and 1")
+			}
+		}
+		b := newBezier(params[0], params[1], params[2], params[3])
+		return func(f, lower, upper float64) float64 {
+			return b.solve(exponential(f, 1, lower, upper))
+		}, nil
+	}
+	return nil, invalid("unknown interpolation type %s", toString(normalize(v)))
+}
+
+// exponential returns the progress of f between lower and upper growing by
+// base, linear when base is 1
+func exponential(f, base, lower, upper float64) float64 {
+	difference := upper - lower
+	progress := f - lower
+	switch {
+	case difference == 0:
+		return 0
+	case base == 1:
+		return progress / difference
+	}
+	return (math.Pow(base, progress) - 1) / (math.Pow(base, difference) - 1)
+}
+
+// interpolate returns the value a fraction t between a and b which are both
+// numbers, colors or arrays of numbers of the same length
+func interpolate(a, b interface{}, t float64) (interface{}, error) {
+	lerp := func(a, b float64) float64 {
+		return a + t*(b-a)
+	}
+
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return lerp(a, b), nil
+		}
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			break
+		}
+		v := make([]interface{}, len(a))
+		for i := range a {
+			x, ok := a[i].(float64)
+			y, ok2 := b[i].(float64)
+			if !ok || !ok2 {
+				return nil, evalError("cannot interpolate %s and %s", typeOf(a), typeOf(b))
+			}
+			v[i] = lerp(x, y)
+		}
+		return v, nil
+	}
+
+	// colors are interpolated with their channels premultiplied by alpha
+	ca, ok := colorValue(a)
+	cb, ok2 := colorValue(b)
+	if !ok || !ok2 {
+		return nil, evalError("cannot interpolate %s and %s", typeOf(a), typeOf(b))
+	}
+	alpha := lerp(ca.A, cb.A)
+	c := Color{A: alpha}
+	if alpha != 0 {
+		c.R = lerp(ca.R*ca.A, cb.R*cb.A) / alpha
+		c.G = lerp(ca.G*ca.A, cb.G*cb.A) / alpha
+		c.B = lerp(ca.B*ca.A, cb.B*cb.A) / alpha
+	}
+	return c, nil
+}
+
+// colorValue returns a Color or a color string as a Color
+func colorValue(v interface{}) (Color, bool) {
+	switch v := v.(type) {
+	case Color:
+		return v, true
+	case string:
+		return ParseColor(v)
+	}
+	return Color{}, false
+}
+
+// bezier is a cubic Bézier easing curve from (0, 0) to (1, 1), solved the
+// way the style specification's reference implementation does
+type bezier struct {
+	cx, bx, ax float64
+	cy, by, ay float64
+}
+
+func newBezier(x1, y1, x2, y2 float64) *bezier {
+	b := &bezier{}
+	b.cx = 3 * x1
+	b.bx = 3*(x2-x1) - b.cx
+	b.ax = 1 - b.cx - b.bx
+	b.cy = 3 * y1
+	b.by = 3*(y2-y1) - b.cy
+	b.ay = 1 - b.cy - b.by
+	return b
+}
+
+func (b *bezier) x(t float64) float64 {
+	return ((b.ax*t+b.bx)*t + b.cx) * t
+}
+
+func (b *bezier) y(t float64) float64 {
+	return ((b.ay*t+b.by)*t + b.cy) * t
+}
+
+func (b *bezier) dx(t float64) float64 {
+	return (3*b.ax*t+2*b.bx)*t + b.cx
+}
+
+// solve returns y of the curve at x
+func (b *bezier) solve(x float64) float64 {
+	const epsilon = 1e-6
+
+	// Newton's method first, then bisection
+	t := x
+	for i := 0; i < 8; i++ {
+		x2 := b.x(t) - x
+		if math.Abs(x2) < epsilon {
+			return b.y(t)
+		}
+		d := b.dx(t)
+		if math.Abs(d) < epsilon {
+			break
+		}
+		t -= x2 / d
+	}
+
+	lo, hi := 0.0, 1.0
+	t = x
+	if t < lo {
+		return b.y(lo)
+	}
+	if t > hi {
+		return b.y(hi)
+	}
+	for i := 0; i < 64 && lo < hi; i++ {
+		x2 := b.x(t)
+		if math.Abs(x2-x) < epsilon {
+			break
+		}
+		if x > x2 {
+			lo = t
+		} else {
+			hi = t
+		}
+		t = (hi-lo)*0.5 + lo
+	}
+	return b.y(t)
+}
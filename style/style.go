@@ -0,0 +1,349 @@
+// Package style evaluates Mapbox GL and MapLibre style expressions, such as
+// ["==", ["get", "class"], "road"], against GeoJSON features.
+//
+// The lookup, decision, ramp, math, string, color, type and variable binding
+// operators of the style specification are supported. Values are nil, bool,
+// float64, string, Color, []interface{} and map[string]interface{}.
+//
+// As in the style specification, equality between values of different types
+// is false while ordering them, asserting a type or converting a value that
+// can't be is an error.
+package style
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var (
+	// ErrInvalidExpression happens when compiling an expression with an
+	// unknown operator or the wrong number or kind of arguments
+	ErrInvalidExpression = errors.New("invalid style expression")
+	// ErrEvaluation happens when evaluating an expression fails, such as
+	// comparing a string to a number
+	ErrEvaluation = errors.New("style expression evaluation error")
+)
+
+// Expression is a compiled style expression
+type Expression struct {
+	e expr
+}
+
+// Parse compiles the JSON of an expression
+func Parse(b []byte) (*Expression, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+	return Compile(v)
+}
+
+// Compile compiles an expression decoded from JSON
+func Compile(v interface{}) (*Expression, error) {
+	e, err := compile(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{e}, nil
+}
+
+// Evaluate returns the value of the expression for a Feature at a zoom level
+func (e *Expression) Evaluate(f *geojson.Feature, zoom float64) (interface{}, error) {
+	if f == nil {
+		f = &geojson.Feature{}
+	}
+	return e.e(&context{feature: f, zoom: zoom})
+}
+
+// Filter reports whether the expression is true for a Feature at a zoom
+// level. Evaluation errors are false as they are for a layer filter.
+func (e *Expression) Filter(f *geojson.Feature, zoom float64) bool {
+	v, err := e.Evaluate(f, zoom)
+	return err == nil && v == true
+}
+
+// context is what an expression is evaluated against
+type context struct {
+	feature *geojson.Feature
+	zoom    float64
+	vars    *binding
+}
+
+// binding is a variable of let, linked to the variables of the enclosing
+// lets
+type binding struct {
+	name   string
+	value  interface{}
+	parent *binding
+}
+
+func (c *context) lookup(name string) interface{} {
+	for b := c.vars; b != nil; b = b.parent {
+		if b.name == name {
+			return b.value
+		}
+	}
+	return nil
+}
+
+// expr is a compiled expression
+type expr func(c *context) (interface{}, error)
+
+func literal(v interface{}) expr {
+	return func(*context) (interface{}, error) {
+		return v, nil
+	}
+}
+
+// evalError returns an ErrEvaluation with details
+func evalError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrEvaluation, fmt.Sprintf(format, args...))
+}
+
+// invalid returns an ErrInvalidExpression with details
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidExpression, fmt.Sprintf(format, args...))
+}
+
+// compile compiles v where scope are the names of the variables bound by the
+// enclosing lets
+func compile(v interface{}, scope []string) (expr, error) {
+	switch v := v.(type) {
+	case nil, bool, string, float64, json.Number, int:
+		return literal(normalize(v)), nil
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, invalid("expected an operator but got an empty array")
+		}
+		name, ok := v[0].(string)
+		if !ok {
+			return nil, invalid("expected an operator but got %v", v[0])
+		}
+		op, ok := operators[name]
+		if !ok {
+			return nil, invalid("unknown operator %q", name)
+		}
+		return op(name, v[1:], scope)
+	}
+	return nil, invalid("bare objects must be wrapped in literal")
+}
+
+// compileArgs compiles every argument
+func compileArgs(args []interface{}, scope []string) ([]expr, error) {
+	es := make([]expr, len(args))
+	for i, a := range args {
+		e, err := compile(a, scope)
+		if err != nil {
+			return nil, err
+		}
+		es[i] = e
+	}
+	return es, nil
+}
+
+// arity checks that an operator has between min and max arguments, max < 0
+// being unbounded
+func arity(name string, args []interface{}, min, max int) error {
+	switch {
+	case len(args) < min:
+		return invalid("%s takes at least %d arguments but got %d", name, min, len(args))
+	case max >= 0 && len(args) > max:
+		return invalid("%s takes at most %d arguments but got %d", name, max, len(args))
+	}
+	return nil
+}
+
+// operator compiles the arguments of an operator
+type operator func(name string, args []interface{}, scope []string) (expr, error)
+
+var operators map[string]operator
+
+func init() {
+	operators = map[string]operator{
+		// types
+		"literal":    compileLiteral,
+		"typeof":     unary(func(v interface{}) (interface{}, error) { return typeOf(v), nil }),
+		"string":     assertion("string"),
+		"number":     assertion("number"),
+		"boolean":    assertion("boolean"),
+		"object":     assertion("object"),
+		"array":      compileArray,
+		"to-boolean": unary(func(v interface{}) (interface{}, error) { return toBoolean(v), nil }),
+		"to-string":  unary(func(v interface{}) (interface{}, error) { return toString(v), nil }),
+		"to-number":  compileToNumber,
+		"to-color":   compileToColor,
+		"to-rgba":    unary(toRGBA),
+		"rgb":        compileRGB,
+		"rgba":       compileRGB,
+
+		// lookup
+		"get":           compileGet,
+		"has":           compileHas,
+		"properties":    nullary(func(c *context) interface{} { return normalize(map[string]interface{}(c.feature.Properties)) }),
+		"id":            nullary(func(c *context) interface{} { return normalize(c.feature.ID) }),
+		"geometry-type": nullary(geometryType),
+		"zoom":          nullary(func(c *context) interface{} { return c.zoom }),
+		"at":            compileAt,
+		"in":            compileIn,
+		"index-of":      compileIndexOf,
+		"slice":         compileSlice,
+		"length":        unary(length),
+
+		// decision
+		"!":        compileNot,
+		"==":       comparison,
+		"!=":       comparison,
+		"<":        comparison,
+		"<=":       comparison,
+		">":        comparison,
+		">=":       comparison,
+		"all":      compileAll,
+		"any":      compileAny,
+		"case":     compileCase,
+		"coalesce": compileCoalesce,
+		"match":    compileMatch,
+
+		// ramps
+		"interpolate": compileInterpolate,
+		"step":        compileStep,
+
+		// variable binding
+		"let": compileLet,
+		"var": compileVar,
+
+		// string
+		"concat":   compileConcat,
+		"downcase": stringOp(strings.ToLower),
+		"upcase":   stringOp(strings.ToUpper),
+	}
+	for name, op := range mathOperators {
+		operators[name] = op
+	}
+}
+
+// nullary returns an operator without arguments
+func nullary(fn func(c *context) interface{}) operator {
+	return func(name string, args []interface{}, scope []string) (expr, error) {
+		if err := arity(name, args, 0, 0); err != nil {
+			return nil, err
+		}
+		return func(c *context) (interface{}, error) {
+			return fn(c), nil
+		}, nil
+	}
+}
+
+// unary returns an operator of a single argument
+func unary(fn func(v interface{}) (interface{}, error)) operator {
+	return func(name string, args []interface{}, scope []string) (expr, error) {
+		if err := arity(name, args, 1, 1); err != nil {
+			return nil, err
+		}
+		a, err := compile(args[0], scope)
+		if err != nil {
+			return nil, err
+		}
+		return func(c *context) (interface{}, error) {
+			v, err := a(c)
+			if err != nil {
+				return nil, err
+			}
+			return fn(v)
+		}, nil
+	}
+}
+
+func compileLiteral(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, 1); err != nil {
+		return nil, err
+	}
+	return literal(normalize(args[0])), nil
+}
+
+// geometryType returns Point, LineString or Polygon for the geometry of the
+// feature and its multi variants, or null
+func geometryType(c *context) interface{} {
+	g := c.feature.Geometry
+	switch {
+	case g == nil:
+		return nil
+	case g.Point != nil, g.MultiPoint != nil:
+		return "Point"
+	case g.LineString != nil, g.MultiLineString != nil:
+		return "LineString"
+	case g.Polygon != nil, g.MultiPolygon != nil:
+		return "Polygon"
+	}
+	return nil
+}
+
+func compileLet(name string, args []interface{}, scope []string) (expr, error) {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return nil, invalid("let takes name and value pairs followed by an expression")
+	}
+
+	type variable struct {
+		name  string
+		value expr
+	}
+	var vars []variable
+	for i := 0; i+1 < len(args); i += 2 {
+		n, ok := args[i].(string)
+		if !ok {
+			return nil, invalid("let variable names must be strings")
+		}
+		v, err := compile(args[i+1], scope)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, variable{n, v})
+	}
+
+	inner := append([]string{}, scope...)
+	for _, v := range vars {
+		inner = append(inner, v.name)
+	}
+	body, err := compile(args[len(args)-1], inner)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *context) (interface{}, error) {
+		bound := c.vars
+		for _, v := range vars {
+			value, err := v.value(c)
+			if err != nil {
+				return nil, err
+			}
+			bound = &binding{v.name, value, bound}
+		}
+		inner := *c
+		inner.vars = bound
+		return body(&inner)
+	}, nil
+}
+
+func compileVar(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, 1); err != nil {
+		return nil, err
+	}
+	n, ok := args[0].(string)
+	if !ok {
+		return nil, invalid("var takes a variable name")
+	}
+	for _, s := range scope {
+		if s == n {
+			return func(c *context) (interface{}, error) {
+				return c.lookup(n), nil
+			}, nil
+		}
+	}
+	return nil, invalid("unknown variable %q", n)
+}
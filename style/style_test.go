@@ -0,0 +1,201 @@
+package style
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var testFeature = &geojson.Feature{
+	ID:       7.0,
+	Geometry: geojson.NewGeometry(&geojson.MultiLineString{Coordinates: []geojson.Positions{{{0, 0}, {1, 1}}}}),
+	Properties: geojson.Properties{
+		"name":    "Main Street",
+		"class":   "road",
+		"lanes":   2.0,
+		"oneway":  true,
+		"tags":    []interface{}{"paved", "lit"},
+		"surface": map[string]interface{}{"kind": "asphalt"},
+		"width":   nil,
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	f := testFeature
+
+	// Success evaluating every kind of operator
+	for expression, expected := range map[string]string{
+		`["==", ["get", "class"], "road"]`:                              `true`,
+		`["!=", ["get", "lanes"], "2"]`:                                 `true`,
+		`["get", "missing"]`:                                            `null`,
+		`["has", "width"]`:                                              `true`,
+		`["has", "missing"]`:                                            `false`,
+		`["get", "kind", ["get", "surface"]]`:                           `"asphalt"`,
+		`["id"]`:                                                        `7`,
+		`["geometry-type"]`:                                             `"LineString"`,
+		`["zoom"]`:                                                      `12.5`,
+		`["at", 1, ["get", "tags"]]`:                                    `"lit"`,
+		`["in", "lit", ["get", "tags"]]`:                                `true`,
+		`["in", "Street", ["get", "name"]]`:                             `true`,
+		`["index-of", "Street", ["get", "name"]]`:                       `5`,
+		`["index-of", "x", ["get", "tags"]]`:                            `-1`,
+		`["slice", ["get", "name"], -6]`:                                `"Street"`,
+		`["slice", ["literal", [1, 2, 3]], 1, 2]`:                       `[2]`,
+		`["length", ["get", "tags"]]`:                                   `2`,
+		`["all", ["get", "oneway"], [">=", ["get", "lanes"], 2]]`:       `true`,
+		`["any", false, ["<", "a", "b"]]`:                               `true`,
+		`["!", ["get", "oneway"]]`:                                      `false`,
+		`["case", ["<", ["get", "lanes"], 2], "narrow", "wide"]`:        `"wide"`,
+		`["coalesce", ["get", "width"], ["get", "lanes"]]`:              `2`,
+		`["match", ["get", "class"], ["path", "road"], 1, 0]`:           `1`,
+		`["match", ["get", "lanes"], 1, "one", 2, "two", "many"]`:       `"two"`,
+		`["match", ["get", "class"], 2, "two", "fallback"]`:             `"fallback"`,
+		`["step", ["zoom"], 0, 10, 1, 12, 2, 14, 3]`:                    `2`,
+		`["step", 5, 0, 10, 1]`:                                         `0`,
+		`["interpolate", ["linear"], ["zoom"], 10, 0, 15, 10]`:          `5`,
+		`["interpolate", ["linear"], 0, 10, 0, 15, 10]`:                 `0`,
+		`["interpolate", ["exponential", 2], 1, 0, 0, 2, 3]`:            `1`,
+		`["interpolate", ["cubic-bezier", 0, 0, 1, 1], 5, 0, 0, 10, 1]`: `0.5`,
+		`["interpolate", ["linear"], 1, 0, ["literal", [0, 10]], 2, ["literal", [10, 20]]]`:      `[5,15]`,
+		`["to-string", ["interpolate", ["linear"], 1, 0, "black", 2, "white"]]`:                  `"rgba(128,128,128,1)"`,
+		`["to-rgba", ["interpolate", ["linear"], 1, 0, "rgba(255,0,0,0)", 2, "blue"]]`:           `[0,0,255,0.5]`,
+		`["let", "n", ["get", "lanes"], ["*", ["var", "n"], ["var", "n"]]]`:                      `4`,
+		`["let", "a", 1, ["let", "a", 2, "b", ["var", "a"], ["+", ["var", "a"], ["var", "b"]]]]`: `3`,
+		`["concat", ["get", "name"], " ", ["get", "lanes"], ["get", "width"], true]`:             `"Main Street 2true"`,
+		`["upcase", ["get", "class"]]`:    `"ROAD"`,
+		`["downcase", "ROAD"]`:            `"road"`,
+		`["+", 1, 2, 3]`:                  `6`,
+		`["-", 5]`:                        `-5`,
+		`["-", 5, 7]`:                     `-2`,
+		`["/", 1, 4]`:                     `0.25`,
+		`["%", 7, 3]`:                     `1`,
+		`["^", 2, 10]`:                    `1024`,
+		`["round", -2.5]`:                 `-3`,
+		`["min", 3, 1, 2]`:                `1`,
+		`["max", 3, 1, 2]`:                `3`,
+		`["floor", ["pi"]]`:               `3`,
+		`["typeof", ["get", "tags"]]`:     `"array<string, 2>"`,
+		`["typeof", ["to-color", "red"]]`: `"color"`,
+		`["string", ["get", "lanes"], ["get", "name"]]`: `"Main Street"`,
+		`["number", ["get", "lanes"]]`:                  `2`,
+		`["array", "string", 2, ["get", "tags"]]`:       `["paved","lit"]`,
+		`["to-number", "1e3"]`:                          `1000`,
+		`["to-number", "x", ["get", "lanes"]]`:          `2`,
+		`["to-number", null]`:                           `0`,
+		`["to-boolean", ""]`:                            `false`,
+		`["to-boolean", "false"]`:                       `true`,
+		`["to-string", 0.1]`:                            `"0.1"`,
+		`["to-string", 1e21]`:                           `"1e+21"`,
+		`["to-string", ["get", "surface"]]`:             `"{\"kind\":\"asphalt\"}"`,
+		`["to-string", ["rgba", 255, 0, 0, 0.5]]`:       `"rgba(255,0,0,0.5)"`,
+		`["to-rgba", ["to-color", "#0f08"]]`:            `[0,255,0,0.5333333333333333]`,
+		`["properties"]`:                                `{"class":"road","lanes":2,"name":"Main Street","oneway":true,"surface":{"kind":"asphalt"},"tags":["paved","lit"],"width":null}`,
+		`["literal", {"a": [1]}]`:                       `{"a":[1]}`,
+	} {
+		e, err := Parse([]byte(expression))
+		if err != nil {
+			t.Errorf("expected %s to compile but got %v", expression, err)
+			continue
+		}
+		v, err := e.Evaluate(f, 12.5)
+		if err != nil {
+			t.Errorf("expected %s to evaluate but got %v", expression, err)
+			continue
+		}
+		if b, _ := marshal(v); string(b) != expected {
+			t.Errorf("expected %s for %s but got %s", expected, expression, b)
+		}
+	}
+
+	// Fail evaluating values of the wrong type
+	for _, expression := range []string{
+		`["<", ["get", "lanes"], "3"]`,
+		`["!", ["get", "name"]]`,
+		`["all", true, 1]`,
+		`["at", 2, ["get", "tags"]]`,
+		`["at", 0.5, ["get", "tags"]]`,
+		`["number", ["get", "name"]]`,
+		`["array", "number", ["get", "tags"]]`,
+		`["to-number", "x"]`,
+		`["to-color", "not a color"]`,
+		`["rgb", 256, 0, 0]`,
+		`["+", 1, "2"]`,
+		`["length", 1]`,
+		`["upcase", 1]`,
+		`["get", 1]`,
+		`["get", "a", "b"]`,
+		`["interpolate", ["linear"], ["get", "name"], 0, 0, 1, 1]`,
+		`["interpolate", ["linear"], 0.5, 0, "a", 1, 1]`,
+	} {
+		e, err := Parse([]byte(expression))
+		if err != nil {
+			t.Errorf("expected %s to compile but got %v", expression, err)
+			continue
+		}
+		if v, err := e.Evaluate(f, 0); !errors.Is(err, ErrEvaluation) {
+			t.Errorf("expected %q for %s but got %v, %v", ErrEvaluation, expression, v, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Fail compiling invalid expressions
+	for _, expression := range []string{
+		`[`,
+		`[]`,
+		`[1, 2]`,
+		`{"a": 1}`,
+		`["unknown"]`,
+		`["get"]`,
+		`["zoom", 1]`,
+		`["case", true, 1]`,
+		`["match", ["get", "a"], "x", 1]`,
+		`["match", ["get", "a"], "x", 1, "x", 2, 0]`,
+		`["match", ["get", "a"], "x", 1, 2, 2, 0]`,
+		`["match", ["get", "a"], 1.5, 1, 0]`,
+		`["step", ["zoom"], 0, 10]`,
+		`["step", ["zoom"], 0, 10, 1, 5, 2]`,
+		`["interpolate", ["quadratic"], ["zoom"], 0, 0, 1, 1]`,
+		`["interpolate", ["linear"], ["zoom"], ["get", "a"], 0]`,
+		`["interpolate", ["cubic-bezier", 2, 0, 1, 1], ["zoom"], 0, 0, 1, 1]`,
+		`["let", "a", 1]`,
+		`["var", "a"]`,
+		`["let", "a", 1, ["var", "b"]]`,
+		`["array", "color", ["get", "a"]]`,
+		`["rgba", 1, 2, 3]`,
+	} {
+		if _, err := Parse([]byte(expression)); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("expected %q for %s but got %v", ErrInvalidExpression, expression, err)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := testFeature
+
+	// Success only for true
+	for expression, expected := range map[string]bool{
+		`["==", ["get", "class"], "road"]`: true,
+		`["==", ["get", "class"], "rail"]`: false,
+		`["get", "lanes"]`:                 false,
+		`["<", ["get", "name"], 1]`:        false,
+	} {
+		e, err := Parse([]byte(expression))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Filter(f, 0) != expected {
+			t.Errorf("expected %v for %s but got %v", expected, expression, !expected)
+		}
+	}
+
+	// Success evaluating a feature without geometry or properties
+	e, err := Compile([]interface{}{"geometry-type"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := e.Evaluate(nil, 0); v != nil || err != nil {
+		t.Errorf("expected null but got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,234 @@
+package style
+
+// assertion returns an operator returning the first of its arguments of the
+// type, failing when none is
+func assertion(typ string) operator {
+	return func(name string, args []interface{}, scope []string) (expr, error) {
+		if err := arity(name, args, 1, -1); err != nil {
+			return nil, err
+		}
+		es, err := compileArgs(args, scope)
+		if err != nil {
+			return nil, err
+		}
+		return func(c *context) (interface{}, error) {
+			var v interface{}
+			for _, e := range es {
+				if v, err = e(c); err != nil {
+					return nil, err
+				}
+				if typeOf(v) == typ {
+					return v, nil
+				}
+			}
+			return nil, evalError("expected value to be of type %s, but found %s instead", typ, typeOf(v))
+		}, nil
+	}
+}
+
+// compileArray compiles ["array", value], ["array", type, value] and
+// ["array", type, length, value]
+func compileArray(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, 3); err != nil {
+		return nil, err
+	}
+	item, n := "", -1
+	if len(args) > 1 {
+		switch t := args[0].(type) {
+		case string:
+			switch t {
+			case "string", "number", "boolean":
+				item = t
+			default:
+				return nil, invalid("array item type must be string, number or boolean but got %q", t)
+			}
+		default:
+			return nil, invalid("array item type must be a string")
+		}
+	}
+	if len(args) > 2 {
+		f, ok := normalize(args[1]).(float64)
+		if !ok || f < 0 || f != float64(int(f)) {
+			return nil, invalid("array length must be a non negative integer")
+		}
+		n = int(f)
+	}
+	e, err := compile(args[len(args)-1], scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *context) (interface{}, error) {
+		v, err := e(c)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := v.([]interface{})
+		if ok && n >= 0 && len(a) != n {
+			ok = false
+		}
+		for i := 0; ok && item != "" && i < len(a); i++ {
+			ok = typeOf(a[i]) == item
+		}
+		if !ok {
+			return nil, evalError("expected value to be an array but found %s instead", typeOf(v))
+		}
+		return a, nil
+	}, nil
+}
+
+// compileToNumber compiles to-number, converting the first argument that
+// can be
+func compileToNumber(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, -1); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		var v interface{}
+		for _, e := range es {
+			if v, err = e(c); err != nil {
+				return nil, err
+			}
+			if f, ok := toNumber(v); ok {
+				return f, nil
+			}
+		}
+		return nil, evalError("could not convert %s to number", toString(v))
+	}, nil
+}
+
+// compileToColor compiles to-color, converting the first argument that can
+// be
+func compileToColor(name string, args []interface{}, scope []string) (expr, error) {
+	if err := arity(name, args, 1, -1); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		var v interface{}
+		for _, e := range es {
+			if v, err = e(c); err != nil {
+				return nil, err
+			}
+			if col, ok := toColor(v); ok {
+				return col, nil
+			}
+		}
+		return nil, evalError("could not parse color from value %s", toString(v))
+	}, nil
+}
+
+// toColor converts a Color, a CSS color string or an array of 3 or 4 numbers
+// to a Color
+func toColor(v interface{}) (Color, bool) {
+	switch v := v.(type) {
+	case Color:
+		return v, true
+	case string:
+		return ParseColor(v)
+	case []interface{}:
+		if len(v) != 3 && len(v) != 4 {
+			return Color{}, false
+		}
+		rgba := []float64{0, 0, 0, 1}
+		for i, e := range v {
+			f, ok := e.(float64)
+			if !ok {
+				return Color{}, false
+			}
+			rgba[i] = f
+		}
+		col, err := newColor(rgba)
+		return col, err == nil
+	}
+	return Color{}, false
+}
+
+// newColor returns the color of red, green, blue and alpha, failing when a
+// channel is out of range
+func newColor(rgba []float64) (Color, error) {
+	for i, f := range rgba {
+		if (i < 3 && (f < 0 || f > 255)) || (i == 3 && (f < 0 || f > 1)) {
+			return Color{}, evalError("invalid rgba value %v: channel out of range", rgba)
+		}
+	}
+	return Color{rgba[0], rgba[1], rgba[2], rgba[3]}, nil
+}
+
+func toRGBA(v interface{}) (interface{}, error) {
+	c, ok := v.(Color)
+	if !ok {
+		return nil, evalError("expected value to be of type color, but found %s instead", typeOf(v))
+	}
+	return []interface{}{c.R, c.G, c.B, c.A}, nil
+}
+
+// compileRGB compiles rgb and rgba
+func compileRGB(name string, args []interface{}, scope []string) (expr, error) {
+	n := 3
+	if name == "rgba" {
+		n = 4
+	}
+	if err := arity(name, args, n, n); err != nil {
+		return nil, err
+	}
+	es, err := compileArgs(args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context) (interface{}, error) {
+		rgba := []float64{0, 0, 0, 1}
+		for i, e := range es {
+			if rgba[i], err = evalNumber(c, e); err != nil {
+				return nil, err
+			}
+		}
+		return newColor(rgba)
+	}, nil
+}
+
+// evalNumber evaluates e, failing when it isn't a number
+func evalNumber(c *context, e expr) (float64, error) {
+	v, err := e(c)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, evalError("expected value to be of type number, but found %s instead", typeOf(v))
+	}
+	return f, nil
+}
+
+// evalString evaluates e, failing when it isn't a string
+func evalString(c *context, e expr) (string, error) {
+	v, err := e(c)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", evalError("expected value to be of type string, but found %s instead", typeOf(v))
+	}
+	return s, nil
+}
+
+// evalBoolean evaluates e, failing when it isn't a boolean
+func evalBoolean(c *context, e expr) (bool, error) {
+	v, err := e(c)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, evalError("expected value to be of type boolean, but found %s instead", typeOf(v))
+	}
+	return b, nil
+}
@@ -0,0 +1,345 @@
+package style
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Color is an RGBA color. R, G and B are from 0 to 255 and A from 0 to 1.
+type Color struct {
+	R, G, B, A float64
+}
+
+// String returns the color as rgba(r,g,b,a) with rounded channels, the way
+// to-string formats it
+func (c Color) String() string {
+	return fmt.Sprintf("rgba(%s,%s,%s,%s)", formatNumber(math.Round(c.R)), formatNumber(math.Round(c.G)), formatNumber(math.Round(c.B)), formatNumber(c.A))
+}
+
+// namedColors are the CSS level 2 color keywords and transparent
+var namedColors = map[string]Color{
+	"transparent": {0, 0, 0, 0},
+	"black":       {0, 0, 0, 1},
+	"silver":      {192, 192, 192, 1},
+	"gray":        {128, 128, 128, 1},
+	"grey":        {128, 128, 128, 1},
+	"white":       {255, 255, 255, 1},
+	"maroon":      {128, 0, 0, 1},
+	"red":         {255, 0, 0, 1},
+	"purple":      {128, 0, 128, 1},
+	"fuchsia":     {255, 0, 255, 1},
+	"green":       {0, 128, 0, 1},
+	"lime":        {0, 255, 0, 1},
+	"olive":       {128, 128, 0, 1},
+	"yellow":      {255, 255, 0, 1},
+	"navy":        {0, 0, 128, 1},
+	"blue":        {0, 0, 255, 1},
+	"teal":        {0, 128, 128, 1},
+	"aqua":        {0, 255, 255, 1},
+	"orange":      {255, 165, 0, 1},
+}
+
+// ParseColor parses a CSS color: a keyword of CSS level 2, #rgb, #rgba,
+// #rrggbb, #rrggbbaa, rgb() or rgba()
+func ParseColor(s string) (Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+
+	if strings.HasPrefix(s, "#") {
+		h := s[1:]
+		if len(h) == 3 || len(h) == 4 {
+			var b strings.Builder
+			for _, r := range h {
+				b.WriteRune(r)
+				b.WriteRune(r)
+			}
+			h = b.String()
+		}
+		if len(h) != 6 && len(h) != 8 {
+			return Color{}, false
+		}
+		v, err := strconv.ParseUint(h, 16, 32)
+		if err != nil {
+			return Color{}, false
+		}
+		if len(h) == 6 {
+			v = v<<8 | 0xff
+		}
+		return Color{float64(v >> 24), float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v&0xff) / 255}, true
+	}
+
+	for _, fn := range []string{"rgba(", "rgb("} {
+		if !strings.HasPrefix(s, fn) || !strings.HasSuffix(s, ")") {
+			continue
+		}
+		parts := strings.Split(s[len(fn):len(s)-1], ",")
+		if len(parts) != 3 && len(parts) != 4 {
+			return Color{}, false
+		}
+		v := []float64{0, 0, 0, 1}
+		for i, p := range parts {
+			p = strings.TrimSpace(p)
+			percent := strings.HasSuffix(p, "%")
+			f, err := strconv.ParseFloat(strings.TrimSuffix(p, "%"), 64)
+			if err != nil {
+				return Color{}, false
+			}
+			switch {
+			case percent && i < 3:
+				f = f * 255 / 100
+			case percent:
+				f /= 100
+			}
+			v[i] = f
+		}
+		return Color{clamp(v[0], 0, 255), clamp(v[1], 0, 255), clamp(v[2], 0, 255), clamp(v[3], 0, 1)}, true
+	}
+	return Color{}, false
+}
+
+func clamp(v, low, high float64) float64 {
+	return math.Max(low, math.Min(high, v))
+}
+
+// normalize turns the numbers of decoded JSON into float64 and Properties
+// like values into plain maps
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = normalize(e)
+		}
+		return a
+	}
+	return v
+}
+
+// typeOf returns the name of the type of v as the typeof operator does
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case Color:
+		return "color"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		item := ""
+		for _, e := range v {
+			t := typeOf(e)
+			switch {
+			case item == "":
+				item = t
+			case item != t:
+				item = "value"
+			}
+		}
+		if item == "" {
+			item = "value"
+		}
+		return fmt.Sprintf("array<%s, %d>", item, len(v))
+	}
+	return "value"
+}
+
+// formatNumber formats a number the way JavaScript does
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+
+	if a := math.Abs(f); a >= 1e21 || a < 1e-6 {
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		// JavaScript doesn't pad the exponent
+		mantissa, exp, _ := strings.Cut(s, "e")
+		sign := exp[0]
+		exp = strings.TrimLeft(exp[1:], "0")
+		return mantissa + "e" + string(sign) + exp
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// toString converts v as the to-string operator does
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return formatNumber(v)
+	case string:
+		return v
+	case Color:
+		return v.String()
+	}
+	b, _ := marshal(v)
+	return string(b)
+}
+
+// marshal encodes v as JSON with the keys of objects sorted and numbers
+// formatted the way JavaScript does
+func marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case float64:
+		return []byte(formatNumber(v)), nil
+	case string:
+		return marshalString(v), nil
+	case Color:
+		return marshalString(v.String()), nil
+	case []interface{}:
+		b := []byte{'['}
+		for i, e := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			eb, err := marshal(e)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, eb...)
+		}
+		return append(b, ']'), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b := []byte{'{'}
+		for i, k := range keys {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			kb := marshalString(k)
+			eb, err := marshal(v[k])
+			if err != nil {
+				return nil, err
+			}
+			b = append(append(append(b, kb...), ':'), eb...)
+		}
+		return append(b, '}'), nil
+	}
+	return json.Marshal(v)
+}
+
+// marshalString encodes s as a JSON string without escaping HTML, as
+// JavaScript does
+func marshalString(s string) []byte {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	e.Encode(s)
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// toBoolean converts v as the to-boolean operator does
+func toBoolean(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// toNumber converts v as the to-number operator does, failing for strings
+// that aren't numbers and values other than null, booleans and numbers
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case nil:
+		return 0, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case float64:
+		return v, true
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, true
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	}
+	return 0, false
+}
+
+// equal reports whether a and b have the same type and value
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
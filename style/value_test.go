@@ -0,0 +1,45 @@
+package style
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	// Success parsing every notation
+	for s, expected := range map[string]Color{
+		"red":                      {255, 0, 0, 1},
+		" Transparent ":            {0, 0, 0, 0},
+		"#0f0":                     {0, 255, 0, 1},
+		"#00ff0080":                {0, 255, 0, 128.0 / 255},
+		"#ABCDEF":                  {171, 205, 239, 1},
+		"rgb(1, 2, 3)":             {1, 2, 3, 1},
+		"rgba(100%, 0%, 50%, 0.5)": {255, 0, 127.5, 0.5},
+		"rgba(300, 0, 0, 2)":       {255, 0, 0, 1},
+	} {
+		if c, ok := ParseColor(s); !ok || c != expected {
+			t.Errorf("expected %v for %q but got %v", expected, s, c)
+		}
+	}
+
+	// Fail on anything else
+	for _, s := range []string{"", "redish", "#12", "#12345", "#gggggg", "rgb(1, 2)", "rgb(a, b, c)", "rgb(1, 2, 3"} {
+		if c, ok := ParseColor(s); ok {
+			t.Errorf("expected %q not to parse but got %v", s, c)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	// Success formatting numbers as JavaScript does
+	for f, expected := range map[float64]string{
+		0:        "0",
+		-1.5:     "-1.5",
+		1e20:     "100000000000000000000",
+		1e21:     "1e+21",
+		1.5e-7:   "1.5e-7",
+		0.000001: "0.000001",
+		1 / 3.0:  "0.3333333333333333",
+	} {
+		if s := formatNumber(f); s != expected {
+			t.Errorf("expected %q but got %q", expected, s)
+		}
+	}
+}
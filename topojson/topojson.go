@@ -0,0 +1,468 @@
+// Package topojson converts between GeoJSON and TopoJSON topologies.
+//
+// Decoding supports both quantized and non-quantized topologies. Topologies
+// built by this package are not quantized and every line or ring is stored as
+// its own arc; shared boundaries are not detected.
+package topojson
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var (
+	// ErrInvalidTopology happens when unmarshalling JSON that isn't a Topology
+	ErrInvalidTopology = errors.New("invalid TopoJSON topology")
+	// ErrInvalidObject happens when an Object has an unknown type or its arcs
+	// or coordinates don't match the type
+	ErrInvalidObject = errors.New("invalid TopoJSON object")
+	// ErrInvalidArc happens when an Object references an arc that doesn't exist
+	ErrInvalidArc = errors.New("invalid arc index")
+	// ErrNoObject happens when looking up an Object name that isn't in the
+	// Topology
+	ErrNoObject = errors.New("no such object")
+	// ErrQuantized happens when adding objects to a quantized Topology
+	ErrQuantized = errors.New("cannot add objects to a quantized topology")
+)
+
+// Transform is the quantization transform of a Topology
+type Transform struct {
+	// Scale is the x, y multiplier applied to quantized positions
+	Scale [2]float64 `json:"scale"`
+	// Translate is the x, y offset applied to quantized positions
+	Translate [2]float64 `json:"translate"`
+}
+
+// Topology is a TopoJSON topology object
+type Topology struct {
+	// Type is always "Topology"
+	Type string `json:"type"`
+	// BoundingBox optionally specifies the extent of the Topology
+	BoundingBox *geojson.BoundingBox `json:"bbox,omitempty"`
+	// Transform is set when the Topology is quantized
+	Transform *Transform `json:"transform,omitempty"`
+	// Objects are the named geometry objects of the Topology
+	Objects map[string]*Object `json:"objects"`
+	// Arcs are the shared lines referenced by the Objects
+	Arcs []geojson.Positions `json:"arcs"`
+
+	// decoded caches Arcs after applying Transform
+	decoded []geojson.Positions
+}
+
+// Object is a TopoJSON geometry object. Type is empty for a null geometry.
+type Object struct {
+	// Type is a GeoJSON geometry type name or empty for a null geometry
+	Type string `json:"type"`
+	// ID is the optional identifier of the Object
+	ID interface{} `json:"id,omitempty"`
+	// Properties are the optional user defined key/values of the Object
+	Properties geojson.Properties `json:"properties,omitempty"`
+	// BoundingBox optionally specifies the extent of the Object
+	BoundingBox *geojson.BoundingBox `json:"bbox,omitempty"`
+	// Coordinates are the positions of Point and MultiPoint objects
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	// Arcs are the arc indexes of line and polygon objects
+	Arcs json.RawMessage `json:"arcs,omitempty"`
+	// Geometries are the members of a GeometryCollection object
+	Geometries []*Object `json:"geometries,omitempty"`
+}
+
+// New returns an empty, non-quantized Topology
+func New() *Topology {
+	return &Topology{
+		Type:    "Topology",
+		Objects: make(map[string]*Object),
+		Arcs:    []geojson.Positions{},
+	}
+}
+
+// MarshalJSON will correctly marshal a Topology (with Type) into JSON
+func (t Topology) MarshalJSON() ([]byte, error) {
+	t.Type = "Topology"
+	if t.Objects == nil {
+		t.Objects = map[string]*Object{}
+	}
+	if t.Arcs == nil {
+		t.Arcs = []geojson.Positions{}
+	}
+	// local type so we don't recurse
+	type topology Topology
+	return json.Marshal(topology(t))
+}
+
+// UnmarshalJSON will unmarshal a Topology and verify its Type
+func (t *Topology) UnmarshalJSON(b []byte) error {
+	// local type so we don't recurse
+	type topology Topology
+	var r topology
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	if r.Type != "Topology" {
+		return ErrInvalidTopology
+	}
+
+	*t = Topology(r)
+	return nil
+}
+
+// MarshalJSON will marshal an Object writing a null type for a null geometry
+func (o Object) MarshalJSON() ([]byte, error) {
+	var typ *string
+	if o.Type != "" {
+		typ = &o.Type
+	}
+
+	// anonymous struct so we don't recurse
+	return json.Marshal(struct {
+		Type        *string              `json:"type"`
+		ID          interface{}          `json:"id,omitempty"`
+		Properties  geojson.Properties   `json:"properties,omitempty"`
+		BoundingBox *geojson.BoundingBox `json:"bbox,omitempty"`
+		Coordinates json.RawMessage      `json:"coordinates,omitempty"`
+		Arcs        json.RawMessage      `json:"arcs,omitempty"`
+		Geometries  []*Object            `json:"geometries,omitempty"`
+	}{
+		Type:        typ,
+		ID:          o.ID,
+		Properties:  o.Properties,
+		BoundingBox: o.BoundingBox,
+		Coordinates: o.Coordinates,
+		Arcs:        o.Arcs,
+		Geometries:  o.Geometries,
+	})
+}
+
+// FeatureCollection converts the named Object into a FeatureCollection. A
+// GeometryCollection object becomes one Feature per member, any other object
+// becomes a single Feature.
+func (t *Topology) FeatureCollection(name string) (*geojson.FeatureCollection, error) {
+	o := t.Objects[name]
+	if o == nil {
+		return nil, ErrNoObject
+	}
+
+	members := []*Object{o}
+	if o.Type == "GeometryCollection" {
+		members = o.Geometries
+	}
+
+	fc := &geojson.FeatureCollection{
+		Object:   geojson.Object{Type: "FeatureCollection"},
+		Features: make([]geojson.Feature, 0, len(members)),
+	}
+	for _, m := range members {
+		g, err := t.Geometry(m)
+		if err != nil {
+			return nil, err
+		}
+		fc.Features = append(fc.Features, geojson.Feature{
+			Object:     geojson.Object{Type: "Feature", BoundingBox: m.BoundingBox},
+			ID:         m.ID,
+			Geometry:   g,
+			Properties: m.Properties,
+		})
+	}
+
+	return fc, nil
+}
+
+// Geometry converts an Object of the Topology into a Geometry. A nil Geometry
+// is returned for a null object.
+func (t *Topology) Geometry(o *Object) (*geojson.Geometry, error) {
+	if t.decoded == nil {
+		t.decodeArcs()
+	}
+
+	g := &geojson.Geometry{Object: geojson.Object{Type: o.Type, BoundingBox: o.BoundingBox}}
+
+	var err error
+	switch o.Type {
+	case "":
+		return nil, nil
+	case "Point":
+		var p geojson.Position
+		if err = unmarshal(o.Coordinates, &p); err == nil {
+			g.Point = &geojson.Point{Object: g.Object, Coordinates: t.point(p)}
+		}
+	case "MultiPoint":
+		var ps geojson.Positions
+		if err = unmarshal(o.Coordinates, &ps); err == nil {
+			for i := range ps {
+				ps[i] = t.point(ps[i])
+			}
+			g.MultiPoint = &geojson.MultiPoint{Object: g.Object, Coordinates: ps}
+		}
+	case "LineString":
+		var a []int
+		if err = unmarshal(o.Arcs, &a); err == nil {
+			var l geojson.Positions
+			if l, err = t.line(a); err == nil {
+				g.LineString = &geojson.LineString{Object: g.Object, Coordinates: l}
+			}
+		}
+	case "MultiLineString":
+		var a [][]int
+		if err = unmarshal(o.Arcs, &a); err == nil {
+			var ls []geojson.Positions
+			if ls, err = t.lines(a); err == nil {
+				g.MultiLineString = &geojson.MultiLineString{Object: g.Object, Coordinates: ls}
+			}
+		}
+	case "Polygon":
+		var a [][]int
+		if err = unmarshal(o.Arcs, &a); err == nil {
+			var rs []geojson.Positions
+			if rs, err = t.lines(a); err == nil {
+				g.Polygon = &geojson.Polygon{Object: g.Object, Coordinates: rs}
+			}
+		}
+	case "MultiPolygon":
+		var a [][][]int
+		if err = unmarshal(o.Arcs, &a); err == nil {
+			ps := make([][]geojson.Positions, 0, len(a))
+			for _, p := range a {
+				var rs []geojson.Positions
+				if rs, err = t.lines(p); err != nil {
+					break
+				}
+				ps = append(ps, rs)
+			}
+			g.MultiPolygon = &geojson.MultiPolygon{Object: g.Object, Coordinates: ps}
+		}
+	case "GeometryCollection":
+		gs := make([]geojson.Geometry, 0, len(o.Geometries))
+		for _, m := range o.Geometries {
+			var mg *geojson.Geometry
+			if mg, err = t.Geometry(m); err != nil {
+				break
+			}
+			if mg != nil {
+				gs = append(gs, *mg)
+			}
+		}
+		g.GeometryCollection = &geojson.GeometryCollection{Object: g.Object, Geometries: gs}
+	default:
+		return nil, ErrInvalidObject
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func unmarshal(b json.RawMessage, v interface{}) error {
+	if len(b) == 0 {
+		return ErrInvalidObject
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrInvalidObject
+	}
+	return nil
+}
+
+// decodeArcs applies the Transform, if any, to the delta encoded Arcs
+func (t *Topology) decodeArcs() {
+	t.decoded = make([]geojson.Positions, len(t.Arcs))
+	for i, a := range t.Arcs {
+		if t.Transform == nil {
+			t.decoded[i] = a
+			continue
+		}
+
+		d := make(geojson.Positions, len(a))
+		var x, y float64
+		for j, p := range a {
+			if len(p) < 2 {
+				continue
+			}
+			x, y = x+p[0], y+p[1]
+			d[j] = append(geojson.Position{
+				x*t.Transform.Scale[0] + t.Transform.Translate[0],
+				y*t.Transform.Scale[1] + t.Transform.Translate[1],
+			}, p[2:]...)
+		}
+		t.decoded[i] = d
+	}
+}
+
+// point applies the Transform, if any, to a Point or MultiPoint position
+func (t *Topology) point(p geojson.Position) geojson.Position {
+	if t.Transform == nil || len(p) < 2 {
+		return p
+	}
+	return append(geojson.Position{
+		p[0]*t.Transform.Scale[0] + t.Transform.Translate[0],
+		p[1]*t.Transform.Scale[1] + t.Transform.Translate[1],
+	}, p[2:]...)
+}
+
+// line stitches arcs together, a negative index ~i being arc i reversed
+func (t *Topology) line(arcs []int) (geojson.Positions, error) {
+	l := geojson.Positions{}
+	for _, i := range arcs {
+		reversed := i < 0
+		if reversed {
+			i = ^i
+		}
+		if i >= len(t.decoded) {
+			return nil, ErrInvalidArc
+		}
+
+		a := t.decoded[i]
+		for j := range a {
+			p := a[j]
+			if reversed {
+				p = a[len(a)-1-j]
+			}
+			// consecutive arcs share their end and start positions
+			if j == 0 && len(l) > 0 {
+				continue
+			}
+			l = append(l, p)
+		}
+	}
+	return l, nil
+}
+
+func (t *Topology) lines(arcs [][]int) ([]geojson.Positions, error) {
+	ls := make([]geojson.Positions, 0, len(arcs))
+	for _, a := range arcs {
+		l, err := t.line(a)
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
+
+// AddFeatureCollection adds fc to the Topology as a GeometryCollection object
+// with one member per Feature
+func (t *Topology) AddFeatureCollection(name string, fc *geojson.FeatureCollection) error {
+	if t.Transform != nil {
+		return ErrQuantized
+	}
+
+	o := &Object{Type: "GeometryCollection", Geometries: []*Object{}}
+	for _, f := range fc.Features {
+		m, err := t.object(f.Geometry)
+		if err != nil {
+			return err
+		}
+		m.ID, m.Properties = f.ID, f.Properties
+		o.Geometries = append(o.Geometries, m)
+	}
+
+	t.add(name, o)
+	return nil
+}
+
+// AddGeometry adds g to the Topology as a single object
+func (t *Topology) AddGeometry(name string, g *geojson.Geometry) error {
+	if t.Transform != nil {
+		return ErrQuantized
+	}
+
+	o, err := t.object(g)
+	if err != nil {
+		return err
+	}
+
+	t.add(name, o)
+	return nil
+}
+
+func (t *Topology) add(name string, o *Object) {
+	if t.Objects == nil {
+		t.Objects = make(map[string]*Object)
+	}
+	t.Objects[name] = o
+	t.decoded = nil
+}
+
+// arc appends a new arc and returns its index
+func (t *Topology) arc(ps geojson.Positions) int {
+	t.Arcs = append(t.Arcs, ps)
+	return len(t.Arcs) - 1
+}
+
+func (t *Topology) arcs(ls []geojson.Positions) [][]int {
+	a := make([][]int, 0, len(ls))
+	for _, l := range ls {
+		a = append(a, []int{t.arc(l)})
+	}
+	return a
+}
+
+func (t *Topology) object(g *geojson.Geometry) (*Object, error) {
+	if g == nil {
+		return &Object{}, nil
+	}
+
+	i := 0
+	for _, set := range []bool{
+		g.Point != nil, g.MultiPoint != nil,
+		g.LineString != nil, g.MultiLineString != nil,
+		g.Polygon != nil, g.MultiPolygon != nil,
+		g.GeometryCollection != nil,
+	} {
+		if set {
+			i++
+		}
+	}
+
+	// Exactly one geometry must be specified
+	if i == 0 {
+		return nil, geojson.ErrNoGeometry
+	} else if i >= 2 {
+		return nil, geojson.ErrMultipleGeometries
+	}
+
+	o := new(Object)
+	var coordinates, arcs interface{}
+	switch {
+	case g.Point != nil:
+		o.Type, coordinates = "Point", g.Point.Coordinates
+	case g.MultiPoint != nil:
+		o.Type, coordinates = "MultiPoint", g.MultiPoint.Coordinates
+	case g.LineString != nil:
+		o.Type, arcs = "LineString", []int{t.arc(g.LineString.Coordinates)}
+	case g.MultiLineString != nil:
+		o.Type, arcs = "MultiLineString", t.arcs(g.MultiLineString.Coordinates)
+	case g.Polygon != nil:
+		o.Type, arcs = "Polygon", t.arcs(g.Polygon.Coordinates)
+	case g.MultiPolygon != nil:
+		a := make([][][]int, 0, len(g.MultiPolygon.Coordinates))
+		for _, p := range g.MultiPolygon.Coordinates {
+			a = append(a, t.arcs(p))
+		}
+		o.Type, arcs = "MultiPolygon", a
+	case g.GeometryCollection != nil:
+		o.Type, o.Geometries = "GeometryCollection", []*Object{}
+		for i := range g.GeometryCollection.Geometries {
+			m, err := t.object(&g.GeometryCollection.Geometries[i])
+			if err != nil {
+				return nil, err
+			}
+			o.Geometries = append(o.Geometries, m)
+		}
+	}
+
+	var err error
+	if coordinates != nil {
+		o.Coordinates, err = json.Marshal(coordinates)
+	}
+	if arcs != nil {
+		o.Arcs, err = json.Marshal(arcs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
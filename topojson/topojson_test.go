@@ -0,0 +1,197 @@
+package topojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+func TestUnmarshalQuantized(t *testing.T) {
+	// Example topology from the TopoJSON specification
+	j := []byte(`{
+		"type": "Topology",
+		"transform": {
+			"scale": [0.0005000500050005, 0.00010001000100010001],
+			"translate": [100, 0]
+		},
+		"objects": {
+			"example": {
+				"type": "GeometryCollection",
+				"geometries": [
+					{
+						"type": "Point",
+						"properties": {"prop0": "value0"},
+						"coordinates": [4000, 5000]
+					},
+					{
+						"type": "LineString",
+						"properties": {"prop0": "value0", "prop1": 0},
+						"arcs": [0]
+					},
+					{
+						"type": "Polygon",
+						"properties": {"prop0": "value0", "prop1": {"this": "that"}},
+						"arcs": [[-2]]
+					}
+				]
+			}
+		},
+		"arcs": [
+			[[4000, 0], [1999, 9999], [2000, -9999], [2000, 9999]],
+			[[0, 0], [0, 9999], [2000, 0], [0, -9999], [-2000, 0]]
+		]
+	}`)
+
+	var topo Topology
+	if err := json.Unmarshal(j, &topo); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	fc, err := topo.FeatureCollection("example")
+	if err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+	if len(fc.Features) != 3 {
+		t.Errorf("expected 3 features but got %v", len(fc.Features))
+		return
+	}
+
+	round := func(p geojson.Position) geojson.Position {
+		r := make(geojson.Position, len(p))
+		for i, v := range p {
+			r[i] = float64(int(v*1000+0.5)) / 1000
+		}
+		return r
+	}
+
+	if g := fc.Features[0].Geometry; g.Point == nil {
+		t.Errorf("expected Point but got %#v", g)
+	} else if p := round(g.Point.Coordinates); !reflect.DeepEqual(p, geojson.Position{102, 0.5}) {
+		t.Errorf("expected %v but got %v", geojson.Position{102, 0.5}, p)
+	}
+
+	if g := fc.Features[1].Geometry; g.LineString == nil {
+		t.Errorf("expected LineString but got %#v", g)
+	} else if len(g.LineString.Coordinates) != 4 {
+		t.Errorf("expected 4 positions but got %v", len(g.LineString.Coordinates))
+	} else if p := round(g.LineString.Coordinates[3]); !reflect.DeepEqual(p, geojson.Position{105, 1}) {
+		t.Errorf("expected %v but got %v", geojson.Position{105, 1}, p)
+	}
+
+	// The polygon ring is arc 1 reversed
+	if g := fc.Features[2].Geometry; g.Polygon == nil {
+		t.Errorf("expected Polygon but got %#v", g)
+	} else if r := g.Polygon.Coordinates[0]; len(r) != 5 {
+		t.Errorf("expected 5 positions but got %v", len(r))
+	} else if p := round(r[0]); !reflect.DeepEqual(p, geojson.Position{100, 0}) {
+		t.Errorf("expected %v but got %v", geojson.Position{100, 0}, p)
+	} else if p := round(r[1]); !reflect.DeepEqual(p, geojson.Position{101, 0}) {
+		t.Errorf("expected %v but got %v", geojson.Position{101, 0}, p)
+	}
+
+	if fc.Features[1].Properties["prop0"] != "value0" {
+		t.Errorf("expected %q but got %v", "value0", fc.Features[1].Properties["prop0"])
+	}
+
+	// Fail on unknown object names
+	if _, err := topo.FeatureCollection("missing"); err != ErrNoObject {
+		t.Errorf("expected '%v' but got '%v'", ErrNoObject, err)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	// Fail on other types
+	var topo Topology
+	if err := json.Unmarshal([]byte(`{"type": "FeatureCollection"}`), &topo); err != ErrInvalidTopology {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidTopology, err)
+	}
+
+	// Fail on out of range arcs
+	topo = Topology{}
+	j := []byte(`{"type": "Topology", "objects": {"a": {"type": "LineString", "arcs": [3]}}, "arcs": []}`)
+	if err := json.Unmarshal(j, &topo); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if _, err := topo.FeatureCollection("a"); err != ErrInvalidArc {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidArc, err)
+	}
+
+	// Fail on unknown object types
+	topo = Topology{}
+	j = []byte(`{"type": "Topology", "objects": {"a": {"type": "Circle"}}, "arcs": []}`)
+	if err := json.Unmarshal(j, &topo); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if _, err := topo.FeatureCollection("a"); err != ErrInvalidObject {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidObject, err)
+	}
+}
+
+func TestThereAndBackAgain(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{{
+			ID: "a",
+			Geometry: &geojson.Geometry{
+				Point: &geojson.Point{Coordinates: geojson.Position{1, 2}},
+			},
+			Properties: geojson.Properties{"name": "point"},
+		}, {
+			Geometry: &geojson.Geometry{
+				MultiPolygon: &geojson.MultiPolygon{Coordinates: [][]geojson.Positions{
+					{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+					{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+				}},
+			},
+		}, {
+			Properties: geojson.Properties{"name": "null"},
+		}},
+	}
+
+	topo := New()
+	if err := topo.AddFeatureCollection("collection", fc); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	b, err := json.Marshal(topo)
+	if err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	var again Topology
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	actual, err := again.FeatureCollection("collection")
+	if err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	for i := range fc.Features {
+		e, a := fc.Features[i], actual.Features[i]
+		if e.ID != a.ID {
+			t.Errorf("expected ID %v but got %v", e.ID, a.ID)
+		}
+		if !reflect.DeepEqual(e.Properties, a.Properties) {
+			t.Errorf("expected Properties %v but got %v", e.Properties, a.Properties)
+		}
+
+		eb, _ := json.Marshal(e.Geometry)
+		ab, _ := json.Marshal(a.Geometry)
+		if string(eb) != string(ab) {
+			t.Errorf("expected Geometry %s but got %s", eb, ab)
+		}
+	}
+
+	// Fail adding to a quantized topology
+	again.Transform = &Transform{Scale: [2]float64{1, 1}}
+	if err := again.AddGeometry("point", fc.Features[0].Geometry); err != ErrQuantized {
+		t.Errorf("expected '%v' but got '%v'", ErrQuantized, err)
+	}
+}
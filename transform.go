@@ -0,0 +1,117 @@
+package geojson
+
+import (
+	"errors"
+	"iter"
+	"math"
+)
+
+const (
+	// earthRadius is the radius in meters of the Web Mercator sphere
+	earthRadius = 6378137.0
+	// MaxWebMercatorLatitude is the latitude where Web Mercator is square,
+	// which ToWebMercator clamps latitudes to
+	MaxWebMercatorLatitude = 85.0511287798066
+)
+
+// errStopped stops a Walk once the loop over AllPositions is left
+var errStopped = errors.New("stopped")
+
+// AllPositions returns an iterator over pointers to every position of the
+// FeatureCollection, Feature or Geometry of g, through which the positions
+// can be changed in place
+func AllPositions(g GeoJSON) iter.Seq[*Position] {
+	return func(yield func(*Position) bool) {
+		Walk(g, positionFunc(func(p *Position) error {
+			if !yield(p) {
+				return errStopped
+			}
+			return nil
+		}))
+	}
+}
+
+// Transform replaces every position of the FeatureCollection, Feature or
+// Geometry of g with fn of it. fn may change the position it's given in place
+// and return it. Bounding boxes are left as they are.
+func Transform(g GeoJSON, fn func(p Position) Position) {
+	Walk(g, positionFunc(func(p *Position) error {
+		*p = fn(*p)
+		return nil
+	}))
+}
+
+// positionFunc is a PositionVisitor calling a function with every position
+type positionFunc func(p *Position) error
+
+func (f positionFunc) Visit(interface{}) error { return nil }
+
+func (f positionFunc) VisitPosition(p *Position) error { return f(p) }
+
+// Translate returns a Transform function adding offsets to the axes of a
+// position in order, such as x, y and z
+func Translate(offsets ...float64) func(p Position) Position {
+	return func(p Position) Position {
+		for i := 0; i < len(p) && i < len(offsets); i++ {
+			p[i] += offsets[i]
+		}
+		return p
+	}
+}
+
+// Scale returns a Transform function multiplying the axes of a position in
+// order by factors
+func Scale(factors ...float64) func(p Position) Position {
+	return func(p Position) Position {
+		for i := 0; i < len(p) && i < len(factors); i++ {
+			p[i] *= factors[i]
+		}
+		return p
+	}
+}
+
+// Rotate returns a Transform function rotating the x and y of a position
+// counterclockwise by degrees around x0 and y0
+func Rotate(degrees, x0, y0 float64) func(p Position) Position {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return func(p Position) Position {
+		if len(p) < 2 {
+			return p
+		}
+		x, y := p[0]-x0, p[1]-y0
+		p[0], p[1] = x0+x*cos-y*sin, y0+x*sin+y*cos
+		return p
+	}
+}
+
+// SwapAxes is a Transform function swapping the x and y of a position, such
+// as for latitude and longitude in the wrong order
+func SwapAxes(p Position) Position {
+	if len(p) >= 2 {
+		p[0], p[1] = p[1], p[0]
+	}
+	return p
+}
+
+// ToWebMercator is a Transform function projecting the longitude and latitude
+// of a position to Web Mercator (EPSG:3857) meters
+func ToWebMercator(p Position) Position {
+	if len(p) < 2 {
+		return p
+	}
+	lat := math.Max(-MaxWebMercatorLatitude, math.Min(MaxWebMercatorLatitude, p[1]))
+	p[0] = earthRadius * p[0] * math.Pi / 180
+	p[1] = earthRadius * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return p
+}
+
+// FromWebMercator is a Transform function unprojecting a position in Web
+// Mercator (EPSG:3857) meters to a longitude and latitude
+func FromWebMercator(p Position) Position {
+	if len(p) < 2 {
+		return p
+	}
+	p[0] = p[0] / earthRadius * 180 / math.Pi
+	p[1] = (2*math.Atan(math.Exp(p[1]/earthRadius)) - math.Pi/2) * 180 / math.Pi
+	return p
+}
@@ -0,0 +1,95 @@
+package geojson
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAllPositions(t *testing.T) {
+	fc := walkCollection()
+
+	// Success iterating over every position
+	n := 0
+	for p := range AllPositions(GeoJSON{FeatureCollection: fc}) {
+		(*p)[0]++
+		n++
+	}
+	if n != 11 {
+		t.Errorf("expected 11 positions but got %d", n)
+	}
+	if p := fc.Features[0].Geometry.Point.Coordinates; !reflect.DeepEqual(p, Position{2, 2}) {
+		t.Errorf("expected %v but got %v", Position{2, 2}, p)
+	}
+
+	// Success leaving the loop early
+	n = 0
+	for range AllPositions(GeoJSON{FeatureCollection: fc}) {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected 3 positions but got %d", n)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	for _, test := range []struct {
+		fn       func(Position) Position
+		p        Position
+		expected Position
+	}{
+		{Translate(1, -2), Position{1, 2, 3}, Position{2, 0, 3}},
+		{Translate(1, 2, 3), Position{1, 2}, Position{2, 4}},
+		{Scale(2, 3, 4), Position{1, 2, 3}, Position{2, 6, 12}},
+		{Rotate(90, 0, 0), Position{1, 0}, Position{0, 1}},
+		{Rotate(180, 1, 1), Position{2, 1, 5}, Position{0, 1, 5}},
+		{SwapAxes, Position{1, 2, 3}, Position{2, 1, 3}},
+		{SwapAxes, Position{1}, Position{1}},
+	} {
+		// Success transforming a position
+		p := test.fn(append(Position(nil), test.p...))
+		for i := range p {
+			p[i] = math.Round(p[i]*1e9) / 1e9
+		}
+		if !reflect.DeepEqual(p, test.expected) {
+			t.Errorf("expected %v for %v but got %v", test.expected, test.p, p)
+		}
+	}
+
+	// Success transforming every geometry type
+	fc := walkCollection()
+	Transform(GeoJSON{FeatureCollection: fc}, SwapAxes)
+	Transform(GeoJSON{Geometry: fc.Features[0].Geometry}, Translate(10, 10))
+	b, _ := json.Marshal(fc)
+	expected := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[12,11]},"properties":null},` +
+		`{"type":"Feature","id":2,"geometry":null,"properties":null},` +
+		`{"type":"Feature","id":3,"geometry":{"type":"GeometryCollection","geometries":[` +
+		`{"type":"LineString","coordinates":[[2,1],[4,3]]},` +
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[0,1],[1,1],[0,0]]],[[[5,5],[5,6],[6,6],[5,5]]]]}` +
+		`]},"properties":null}]}`
+	if string(b) != expected {
+		t.Errorf("expected %s but got %s", expected, b)
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	// Success projecting to Web Mercator and back
+	p := ToWebMercator(Position{-122.4, 37.8, 12})
+	if math.Abs(p[0]+13625505.67) > 0.01 || math.Abs(p[1]-4551210.92) > 0.01 || p[2] != 12 {
+		t.Errorf("expected [-13625505.67, 4551210.92, 12] but got %v", p)
+	}
+	p = FromWebMercator(p)
+	if math.Abs(p[0]+122.4) > 1e-9 || math.Abs(p[1]-37.8) > 1e-9 || p[2] != 12 {
+		t.Errorf("expected [-122.4, 37.8, 12] but got %v", p)
+	}
+
+	// Success clamping latitudes to where Web Mercator is square
+	if p, q := ToWebMercator(Position{0, 90}), ToWebMercator(Position{0, MaxWebMercatorLatitude}); p[1] != q[1] {
+		t.Errorf("expected %v but got %v", q[1], p[1])
+	}
+}
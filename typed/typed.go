@@ -0,0 +1,118 @@
+// Package typed provides GeoJSON features whose properties are a Go type
+// rather than a map.
+//
+// They marshal to the same JSON as geojson.Feature and
+// geojson.FeatureCollection:
+//
+//	type Road struct {
+//		Name  string `json:"name"`
+//		Lanes int    `json:"lanes"`
+//	}
+//
+//	var fc typed.FeatureCollection[Road]
+//	err := json.Unmarshal(b, &fc)
+//	lanes := fc.Features[0].Properties.Lanes
+package typed
+
+import (
+	"encoding/json"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// Feature is a GeoJSON feature object whose properties are of type P
+type Feature[P any] struct {
+	// Object is the common GeoJSON object
+	geojson.Object
+	// ID is the optional commonly used identifier for the Feature
+	ID interface{} `json:"id,omitempty"`
+	// Geometry represents a GeoJSON Geometry object with one of the Geometry
+	// types filled in
+	Geometry *geojson.Geometry `json:"geometry"`
+	// Properties are the properties of the Feature, usually a struct with json
+	// tags
+	Properties P `json:"properties"`
+}
+
+// FeatureCollection contains multiple features whose properties are of
+// type P
+type FeatureCollection[P any] struct {
+	// Object is the common GeoJSON object
+	geojson.Object
+	// Features is the set of Feature objects to group together
+	Features []Feature[P] `json:"features"`
+}
+
+// MarshalJSON will correctly marshal a Feature (with Type) into JSON
+func (f Feature[P]) MarshalJSON() ([]byte, error) {
+	f.Type = "Feature"
+	// anonymous struct so we don't recurse
+	return json.Marshal(struct {
+		geojson.Object
+		ID         interface{}       `json:"id,omitempty"`
+		Geometry   *geojson.Geometry `json:"geometry"`
+		Properties P                 `json:"properties"`
+	}{
+		Object:     f.Object,
+		ID:         f.ID,
+		Geometry:   f.Geometry,
+		Properties: f.Properties,
+	})
+}
+
+// MarshalJSON will correctly marshal a FeatureCollection (with Type) into JSON
+func (f FeatureCollection[P]) MarshalJSON() ([]byte, error) {
+	f.Type = "FeatureCollection"
+	// anonymous struct so we don't recurse
+	return json.Marshal(struct {
+		geojson.Object
+		Features []Feature[P] `json:"features"`
+	}{
+		Object:   f.Object,
+		Features: f.Features,
+	})
+}
+
+// FromFeature returns the Feature f with its properties decoded into P
+func FromFeature[P any](f geojson.Feature) (Feature[P], error) {
+	t := Feature[P]{Object: f.Object, ID: f.ID, Geometry: f.Geometry}
+	if err := f.DecodeProperties(&t.Properties); err != nil {
+		return Feature[P]{}, err
+	}
+	return t, nil
+}
+
+// Feature returns f with its properties encoded into a geojson.Properties
+func (f Feature[P]) Feature() (geojson.Feature, error) {
+	g := geojson.Feature{Object: f.Object, ID: f.ID, Geometry: f.Geometry}
+	if err := g.EncodeProperties(f.Properties); err != nil {
+		return geojson.Feature{}, err
+	}
+	return g, nil
+}
+
+// FromFeatureCollection returns fc with the properties of its features
+// decoded into P
+func FromFeatureCollection[P any](fc geojson.FeatureCollection) (FeatureCollection[P], error) {
+	t := FeatureCollection[P]{Object: fc.Object, Features: make([]Feature[P], len(fc.Features))}
+	for i, f := range fc.Features {
+		var err error
+		if t.Features[i], err = FromFeature[P](f); err != nil {
+			return FeatureCollection[P]{}, err
+		}
+	}
+	return t, nil
+}
+
+// FeatureCollection returns fc with the properties of its features encoded
+// into a geojson.Properties
+func (f FeatureCollection[P]) FeatureCollection() (geojson.FeatureCollection, error) {
+	fc := geojson.FeatureCollection{Object: f.Object, Features: make([]geojson.Feature, len(f.Features))}
+	for i, t := range f.Features {
+		var err error
+		if fc.Features[i], err = t.Feature(); err != nil {
+			return geojson.FeatureCollection{}, err
+		}
+	}
+	return fc, nil
+}
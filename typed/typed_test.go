@@ -0,0 +1,81 @@
+package typed
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+type road struct {
+	Name  string `json:"name"`
+	Lanes int    `json:"lanes,omitempty"`
+}
+
+const testCollection = `{"type":"FeatureCollection","features":[{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"Main Street","lanes":2}},{"type":"Feature","geometry":null,"properties":{"name":"Back Lane"}}]}`
+
+func TestFeatureCollection(t *testing.T) {
+	// Success unmarshaling into typed properties
+	var fc FeatureCollection[road]
+	if err := json.Unmarshal([]byte(testCollection), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 2 || fc.Features[0].Properties.Lanes != 2 || fc.Features[1].Properties.Name != "Back Lane" {
+		t.Errorf("expected the roads but got %#v", fc)
+	}
+
+	// Success marshaling the same JSON as geojson.FeatureCollection
+	b, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != testCollection {
+		t.Errorf("expected %q but got %q", testCollection, b)
+	}
+
+	// Success setting the type members
+	b, err = json.Marshal(FeatureCollection[road]{Features: []Feature[road]{{Properties: road{Name: "x"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":{"name":"x"}}]}`
+	if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, b)
+	}
+
+	// Success with pointer properties marshaling to null
+	if b, err = json.Marshal(Feature[*road]{}); err != nil {
+		t.Error(err)
+	} else if string(b) != `{"type":"Feature","geometry":null,"properties":null}` {
+		t.Errorf("expected null properties but got %q", b)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var g geojson.FeatureCollection
+	if err := json.Unmarshal([]byte(testCollection), &g); err != nil {
+		t.Fatal(err)
+	}
+
+	// Success converting from and back to geojson.FeatureCollection
+	fc, err := FromFeatureCollection[road](g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc.Features[0].ID != "a" || fc.Features[0].Properties.Name != "Main Street" || fc.Features[0].Geometry.Point == nil {
+		t.Errorf("expected the first road but got %#v", fc.Features[0])
+	}
+	back, err := fc.FeatureCollection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Features[0].Properties["lanes"] != 2.0 {
+		t.Errorf("expected 2 lanes but got %v", back.Features[0].Properties["lanes"])
+	}
+
+	// Fail decoding properties of the wrong type
+	g.Features[0].Properties["lanes"] = "two"
+	if _, err := FromFeatureCollection[road](g); err == nil {
+		t.Error("expected an error decoding a string into an int")
+	}
+}
@@ -0,0 +1,145 @@
+package geojson
+
+import "errors"
+
+// SkipChildren is returned by a Visitor to skip the children of the object it
+// was called with, or the remaining positions of the geometry of a position.
+// Walk doesn't return it as an error.
+var SkipChildren = errors.New("skip children")
+
+// Visitor visits the objects of GeoJSON with Walk
+type Visitor interface {
+	// Visit is called with every *FeatureCollection, *Feature and *Geometry,
+	// each before its children. The object can be changed or replaced
+	// through the pointer, Walk going on with the children it then has.
+	Visit(v interface{}) error
+}
+
+// PositionVisitor is a Visitor that's also called with every position of the
+// geometries, which can be changed or replaced through the pointer
+type PositionVisitor interface {
+	Visitor
+	VisitPosition(p *Position) error
+}
+
+// VisitFunc is a function used as a Visitor
+type VisitFunc func(v interface{}) error
+
+// Visit calls f(v)
+func (f VisitFunc) Visit(v interface{}) error {
+	return f(v)
+}
+
+// Walk calls v with the FeatureCollection, Feature or Geometry of g and all
+// of the objects they contain, down through the geometries of
+// GeometryCollections. Walk stops at the first error of v other than
+// SkipChildren and returns it.
+func Walk(g GeoJSON, v Visitor) error {
+	w := walker{v: v}
+	w.positions, _ = v.(PositionVisitor)
+
+	var err error
+	switch {
+	case g.FeatureCollection != nil:
+		err = w.featureCollection(g.FeatureCollection)
+	case g.Feature != nil:
+		err = w.feature(g.Feature)
+	case g.Geometry != nil:
+		err = w.geometry(g.Geometry)
+	}
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
+
+// walker walks GeoJSON with a Visitor, and its positions when it's a
+// PositionVisitor
+type walker struct {
+	v         Visitor
+	positions PositionVisitor
+}
+
+// visit calls the Visitor with v, reporting whether to go on with its
+// children
+func (w *walker) visit(v interface{}) (bool, error) {
+	switch err := w.v.Visit(v); err {
+	case nil:
+		return true, nil
+	case SkipChildren:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (w *walker) featureCollection(fc *FeatureCollection) error {
+	if children, err := w.visit(fc); !children {
+		return err
+	}
+	for i := range fc.Features {
+		if err := w.feature(&fc.Features[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) feature(f *Feature) error {
+	if children, err := w.visit(f); !children || f.Geometry == nil {
+		return err
+	}
+	return w.geometry(f.Geometry)
+}
+
+func (w *walker) geometry(g *Geometry) error {
+	if children, err := w.visit(g); !children {
+		return err
+	}
+	if c := g.GeometryCollection; c != nil {
+		for i := range c.Geometries {
+			if err := w.geometry(&c.Geometries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if w.positions == nil {
+		return nil
+	}
+	if err := eachPositionPointer(g.Value(), w.positions.VisitPosition); err != SkipChildren {
+		return err
+	}
+	return nil
+}
+
+// eachPositionPointer calls fn with a pointer to every position of the
+// geometry t, other than those of the geometries of a GeometryCollection,
+// until fn returns an error
+func eachPositionPointer(t GeometryType, fn func(p *Position) error) error {
+	var rings []Positions
+	switch t := t.(type) {
+	case *Point:
+		return fn(&t.Coordinates)
+	case *MultiPoint:
+		rings = []Positions{t.Coordinates}
+	case *LineString:
+		rings = []Positions{t.Coordinates}
+	case *MultiLineString:
+		rings = t.Coordinates
+	case *Polygon:
+		rings = t.Coordinates
+	case *MultiPolygon:
+		for _, polygon := range t.Coordinates {
+			rings = append(rings, polygon...)
+		}
+	}
+	for _, ring := range rings {
+		for i := range ring {
+			if err := fn(&ring[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
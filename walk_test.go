@@ -0,0 +1,133 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// positionVisitor is a PositionVisitor with functions
+type positionVisitor struct {
+	VisitFunc
+	position func(p *Position) error
+}
+
+func (v positionVisitor) VisitPosition(p *Position) error {
+	return v.position(p)
+}
+
+// walkCollection returns a new collection of every kind of object for the
+// tests that change it
+func walkCollection() *FeatureCollection {
+	return &FeatureCollection{Features: []Feature{
+		{ID: 1.0, Geometry: NewGeometry(&Point{Coordinates: Position{1, 2}})},
+		{ID: 2.0},
+		{ID: 3.0, Geometry: NewGeometry(&GeometryCollection{Geometries: []Geometry{
+			*NewGeometry(&LineString{Coordinates: Positions{{1, 2}, {3, 4}}}),
+			*NewGeometry(&MultiPolygon{Coordinates: [][]Positions{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+			}}),
+		}})},
+	}}
+}
+
+func TestWalk(t *testing.T) {
+	fc := walkCollection()
+
+	// Success visiting every object, parents first
+	var visited []string
+	err := Walk(GeoJSON{FeatureCollection: fc}, VisitFunc(func(v interface{}) error {
+		switch v := v.(type) {
+		case *FeatureCollection:
+			visited = append(visited, "FeatureCollection")
+		case *Feature:
+			visited = append(visited, fmt.Sprint("Feature ", v.ID))
+		case *Geometry:
+			visited = append(visited, v.Type)
+		}
+		return nil
+	}))
+	expected := []string{"FeatureCollection", "Feature 1", "Point", "Feature 2", "Feature 3", "GeometryCollection", "LineString", "MultiPolygon"}
+	if err != nil || !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %q but got %q, %v", expected, visited, err)
+	}
+
+	// Success skipping children and walking the children of replacements
+	visited = nil
+	err = Walk(GeoJSON{FeatureCollection: fc}, VisitFunc(func(v interface{}) error {
+		switch v := v.(type) {
+		case *Feature:
+			if v.ID == 1.0 {
+				return SkipChildren
+			}
+		case *Geometry:
+			visited = append(visited, v.Type)
+			if v.GeometryCollection != nil {
+				*v = *NewGeometry(&GeometryCollection{Geometries: []Geometry{*NewGeometry(&Point{Coordinates: Position{7, 8}})}})
+			}
+		}
+		return nil
+	}))
+	expected = []string{"GeometryCollection", "Point"}
+	if err != nil || !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %q but got %q, %v", expected, visited, err)
+	}
+
+	// Fail with the first error
+	errStop := errors.New("stop")
+	n := 0
+	err = Walk(GeoJSON{FeatureCollection: fc}, VisitFunc(func(v interface{}) error {
+		n++
+		if _, ok := v.(*Geometry); ok {
+			return errStop
+		}
+		return nil
+	}))
+	if err != errStop || n != 3 {
+		t.Errorf("expected %q after 3 objects but got %v after %d", errStop, err, n)
+	}
+
+	// Success walking nothing
+	if err := Walk(GeoJSON{}, VisitFunc(func(interface{}) error { return errStop })); err != nil {
+		t.Errorf("expected no error but got %v", err)
+	}
+}
+
+func TestWalkPositions(t *testing.T) {
+	fc := walkCollection()
+
+	// Success changing every position
+	n := 0
+	err := Walk(GeoJSON{FeatureCollection: fc}, positionVisitor{
+		VisitFunc: func(interface{}) error { return nil },
+		position: func(p *Position) error {
+			n++
+			*p = Position{(*p)[1], (*p)[0]}
+			return nil
+		},
+	})
+	if err != nil || n != 11 {
+		t.Errorf("expected 11 positions but got %d, %v", n, err)
+	}
+	b, _ := json.Marshal(fc.Features[2].Geometry)
+	expected := `{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[2,1],[4,3]]},{"type":"MultiPolygon","coordinates":[[[[0,0],[0,1],[1,1],[0,0]]],[[[5,5],[5,6],[6,6],[5,5]]]]}]}`
+	if string(b) != expected {
+		t.Errorf("expected %s but got %s", expected, b)
+	}
+
+	// Success skipping the remaining positions of a geometry
+	n = 0
+	err = Walk(GeoJSON{Feature: &fc.Features[2]}, positionVisitor{
+		VisitFunc: func(interface{}) error { return nil },
+		position: func(p *Position) error {
+			n++
+			return SkipChildren
+		},
+	})
+	if err != nil || n != 2 {
+		t.Errorf("expected 2 positions but got %d, %v", n, err)
+	}
+}
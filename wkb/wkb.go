@@ -0,0 +1,511 @@
+// Package wkb encodes and decodes GeoJSON geometries using the OGC Well-Known
+// Binary representation.
+//
+// Geometries are encoded as little endian ISO WKB. Decoding accepts either
+// byte order as well as the PostGIS EWKB flags for Z, M and SRID. M values of
+// XYM geometries are dropped since a GeoJSON position can't represent them.
+package wkb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/losinggeneration/geojson"
+)
+
+var (
+	// ErrInvalidWKB happens when decoding data that isn't valid WKB
+	ErrInvalidWKB = errors.New("invalid WKB")
+	// ErrMixedDimensions happens when encoding a geometry whose positions don't
+	// all have the same number of values
+	ErrMixedDimensions = errors.New("positions must all have the same dimension")
+)
+
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// Marshal returns the WKB representation of a Geometry. Exactly one of the
+// geometry types must be filled in.
+func Marshal(g *geojson.Geometry) ([]byte, error) {
+	var b bytes.Buffer
+	if err := NewEncoder(&b).Encode(g); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Unmarshal parses a single WKB geometry and fills in the appropriate geometry
+// type of g.
+func Unmarshal(data []byte, g *geojson.Geometry) error {
+	d := NewDecoder(bytes.NewReader(data))
+	if err := d.Decode(g); err != nil {
+		if err == io.EOF {
+			return ErrInvalidWKB
+		}
+		return err
+	}
+	if _, err := d.r.ReadByte(); err != io.EOF {
+		return ErrInvalidWKB
+	}
+	return nil
+}
+
+// An Encoder writes WKB geometries to an output stream. Successive geometries
+// are written back to back since a WKB record is self delimiting.
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder returns a new Encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the WKB encoding of g to the stream
+func (e *Encoder) Encode(g *geojson.Geometry) error {
+	b, err := appendGeometry(e.buf[:0], g)
+	if err != nil {
+		return err
+	}
+	e.buf = b
+
+	_, err = e.w.Write(b)
+	return err
+}
+
+func appendGeometry(b []byte, g *geojson.Geometry) ([]byte, error) {
+	if g == nil {
+		return nil, geojson.ErrNoGeometry
+	}
+
+	i := 0
+	for _, set := range []bool{
+		g.Point != nil, g.MultiPoint != nil,
+		g.LineString != nil, g.MultiLineString != nil,
+		g.Polygon != nil, g.MultiPolygon != nil,
+		g.GeometryCollection != nil,
+	} {
+		if set {
+			i++
+		}
+	}
+
+	// Exactly one geometry must be specified
+	if i == 0 {
+		return nil, geojson.ErrNoGeometry
+	} else if i >= 2 {
+		return nil, geojson.ErrMultipleGeometries
+	}
+
+	if g.GeometryCollection != nil {
+		c := g.GeometryCollection.Geometries
+		b = appendHeader(b, wkbGeometryCollection, 2)
+		b = appendUint32(b, uint32(len(c)))
+		for i := range c {
+			var err error
+			if b, err = appendGeometry(b, &c[i]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+
+	var t uint32
+	var rings [][]geojson.Positions
+	switch {
+	case g.Point != nil:
+		t = wkbPoint
+		rings = [][]geojson.Positions{{{g.Point.Coordinates}}}
+	case g.MultiPoint != nil:
+		t = wkbMultiPoint
+		for _, p := range g.MultiPoint.Coordinates {
+			rings = append(rings, []geojson.Positions{{p}})
+		}
+	case g.LineString != nil:
+		t = wkbLineString
+		rings = [][]geojson.Positions{{g.LineString.Coordinates}}
+	case g.MultiLineString != nil:
+		t = wkbMultiLineString
+		for _, l := range g.MultiLineString.Coordinates {
+			rings = append(rings, []geojson.Positions{l})
+		}
+	case g.Polygon != nil:
+		t = wkbPolygon
+		rings = [][]geojson.Positions{g.Polygon.Coordinates}
+	case g.MultiPolygon != nil:
+		t = wkbMultiPolygon
+		rings = g.MultiPolygon.Coordinates
+	}
+
+	dim, err := dimension(rings)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case wkbPoint:
+		b = appendHeader(b, t, dim)
+		p := g.Point.Coordinates
+		if len(p) == 0 {
+			// An empty point is conventionally written with NaN coordinates
+			p = geojson.Position{math.NaN(), math.NaN()}
+		}
+		return appendPosition(b, p), nil
+	case wkbLineString:
+		b = appendHeader(b, t, dim)
+		return appendPositions(b, g.LineString.Coordinates), nil
+	case wkbPolygon:
+		b = appendHeader(b, t, dim)
+		return appendRings(b, g.Polygon.Coordinates), nil
+	}
+
+	b = appendHeader(b, t, dim)
+	b = appendUint32(b, uint32(len(rings)))
+	for _, r := range rings {
+		switch t {
+		case wkbMultiPoint:
+			b = appendHeader(b, wkbPoint, dim)
+			b = appendPosition(b, r[0][0])
+		case wkbMultiLineString:
+			b = appendHeader(b, wkbLineString, dim)
+			b = appendPositions(b, r[0])
+		case wkbMultiPolygon:
+			b = appendHeader(b, wkbPolygon, dim)
+			b = appendRings(b, r)
+		}
+	}
+	return b, nil
+}
+
+// dimension returns the number of values in every position or an error if
+// they differ
+func dimension(rings [][]geojson.Positions) (int, error) {
+	dim := 0
+	for _, r := range rings {
+		for _, ps := range r {
+			for _, p := range ps {
+				if len(p) == 0 {
+					continue
+				}
+				if dim == 0 {
+					dim = len(p)
+				} else if len(p) != dim {
+					return 0, ErrMixedDimensions
+				}
+			}
+		}
+	}
+	if dim == 0 {
+		dim = 2
+	}
+	if dim < 2 || dim > 4 {
+		return 0, ErrMixedDimensions
+	}
+	return dim, nil
+}
+
+func appendHeader(b []byte, t uint32, dim int) []byte {
+	b = append(b, 1) // little endian
+	switch dim {
+	case 3:
+		t += 1000
+	case 4:
+		t += 3000
+	}
+	return appendUint32(b, t)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(b, v)
+}
+
+func appendPosition(b []byte, p geojson.Position) []byte {
+	for _, v := range p {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+	}
+	return b
+}
+
+func appendPositions(b []byte, ps geojson.Positions) []byte {
+	b = appendUint32(b, uint32(len(ps)))
+	for _, p := range ps {
+		b = appendPosition(b, p)
+	}
+	return b
+}
+
+func appendRings(b []byte, rings []geojson.Positions) []byte {
+	b = appendUint32(b, uint32(len(rings)))
+	for _, r := range rings {
+		b = appendPositions(b, r)
+	}
+	return b
+}
+
+// A Decoder reads WKB geometries from an input stream of back to back records
+type Decoder struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+// NewDecoder returns a new Decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next WKB geometry from the stream into g. It returns io.EOF
+// when there are no more geometries to read.
+func (d *Decoder) Decode(g *geojson.Geometry) error {
+	if _, err := d.r.Peek(1); err == io.EOF {
+		return io.EOF
+	}
+
+	r, err := d.geometry()
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrInvalidWKB
+	} else if err != nil {
+		return err
+	}
+
+	*g = *r
+	return nil
+}
+
+func (d *Decoder) read(n int) ([]byte, error) {
+	if _, err := io.ReadFull(d.r, d.buf[:n]); err != nil {
+		return nil, err
+	}
+	return d.buf[:n], nil
+}
+
+func (d *Decoder) uint32() (uint32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *Decoder) float64() (float64, error) {
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(d.order.Uint64(b)), nil
+}
+
+// header reads the byte order and type returning the base type along with the
+// number of values per position and whether the M value should be dropped
+func (d *Decoder) header() (t uint32, dim int, dropM bool, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	switch b[0] {
+	case 0:
+		d.order = binary.BigEndian
+	case 1:
+		d.order = binary.LittleEndian
+	default:
+		return 0, 0, false, ErrInvalidWKB
+	}
+
+	if t, err = d.uint32(); err != nil {
+		return 0, 0, false, err
+	}
+
+	hasZ, hasM := t&ewkbZ != 0, t&ewkbM != 0
+	if t&ewkbSRID != 0 {
+		if _, err := d.uint32(); err != nil {
+			return 0, 0, false, err
+		}
+	}
+	t &^= ewkbZ | ewkbM | ewkbSRID
+
+	switch t / 1000 {
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ, hasM = true, true
+	}
+	t %= 1000
+
+	dim = 2
+	if hasZ {
+		dim++
+	}
+	if hasM {
+		dim++
+	}
+	return t, dim, hasM && !hasZ, nil
+}
+
+func (d *Decoder) position(dim int, dropM bool) (geojson.Position, error) {
+	p := make(geojson.Position, dim)
+	for i := range p {
+		v, err := d.float64()
+		if err != nil {
+			return nil, err
+		}
+		p[i] = v
+	}
+	if dropM {
+		p = p[:2]
+	}
+	return p, nil
+}
+
+func (d *Decoder) positions(dim int, dropM bool) (geojson.Positions, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	ps := make(geojson.Positions, 0, min(n, 1024))
+	for ; n > 0; n-- {
+		p, err := d.position(dim, dropM)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+func (d *Decoder) rings(dim int, dropM bool) ([]geojson.Positions, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]geojson.Positions, 0, min(n, 1024))
+	for ; n > 0; n-- {
+		r, err := d.positions(dim, dropM)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+// member reads a geometry nested in a multi geometry and checks its type
+func (d *Decoder) member(want uint32) (*geojson.Geometry, error) {
+	g, err := d.geometry()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case want == wkbPoint && g.Point != nil,
+		want == wkbLineString && g.LineString != nil,
+		want == wkbPolygon && g.Polygon != nil:
+		return g, nil
+	}
+	return nil, ErrInvalidWKB
+}
+
+func (d *Decoder) geometry() (*geojson.Geometry, error) {
+	t, dim, dropM, err := d.header()
+	if err != nil {
+		return nil, err
+	}
+
+	g := new(geojson.Geometry)
+	switch t {
+	case wkbPoint:
+		p, err := d.position(dim, dropM)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(p[0]) && math.IsNaN(p[1]) {
+			p = geojson.Position{}
+		}
+		g.Type = "Point"
+		g.Point = &geojson.Point{Object: g.Object, Coordinates: p}
+	case wkbLineString:
+		ps, err := d.positions(dim, dropM)
+		if err != nil {
+			return nil, err
+		}
+		g.Type = "LineString"
+		g.LineString = &geojson.LineString{Object: g.Object, Coordinates: ps}
+	case wkbPolygon:
+		rs, err := d.rings(dim, dropM)
+		if err != nil {
+			return nil, err
+		}
+		g.Type = "Polygon"
+		g.Polygon = &geojson.Polygon{Object: g.Object, Coordinates: rs}
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		members := make([]*geojson.Geometry, 0, min(n, 1024))
+		for ; n > 0; n-- {
+			var m *geojson.Geometry
+			switch t {
+			case wkbMultiPoint:
+				m, err = d.member(wkbPoint)
+			case wkbMultiLineString:
+				m, err = d.member(wkbLineString)
+			case wkbMultiPolygon:
+				m, err = d.member(wkbPolygon)
+			default:
+				m, err = d.geometry()
+			}
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, m)
+		}
+
+		switch t {
+		case wkbMultiPoint:
+			g.Type = "MultiPoint"
+			g.MultiPoint = &geojson.MultiPoint{Object: g.Object, Coordinates: geojson.Positions{}}
+			for _, m := range members {
+				g.MultiPoint.Coordinates = append(g.MultiPoint.Coordinates, m.Point.Coordinates)
+			}
+		case wkbMultiLineString:
+			g.Type = "MultiLineString"
+			g.MultiLineString = &geojson.MultiLineString{Object: g.Object, Coordinates: []geojson.Positions{}}
+			for _, m := range members {
+				g.MultiLineString.Coordinates = append(g.MultiLineString.Coordinates, m.LineString.Coordinates)
+			}
+		case wkbMultiPolygon:
+			g.Type = "MultiPolygon"
+			g.MultiPolygon = &geojson.MultiPolygon{Object: g.Object, Coordinates: [][]geojson.Positions{}}
+			for _, m := range members {
+				g.MultiPolygon.Coordinates = append(g.MultiPolygon.Coordinates, m.Polygon.Coordinates)
+			}
+		default:
+			g.Type = "GeometryCollection"
+			g.GeometryCollection = &geojson.GeometryCollection{Object: g.Object, Geometries: []geojson.Geometry{}}
+			for _, m := range members {
+				g.GeometryCollection.Geometries = append(g.GeometryCollection.Geometries, *m)
+			}
+		}
+	default:
+		return nil, ErrInvalidWKB
+	}
+
+	return g, nil
+}
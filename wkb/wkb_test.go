@@ -0,0 +1,166 @@
+package wkb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	// Success on type Point
+	g := geojson.Geometry{
+		Point: &geojson.Point{
+			Coordinates: geojson.Position{1, 2},
+		},
+	}
+	expected := "0101000000000000000000f03f0000000000000040"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if hex.EncodeToString(b) != expected {
+		t.Errorf("expected %q but got %q", expected, hex.EncodeToString(b))
+	}
+
+	// Success on type Point with Z
+	g = geojson.Geometry{
+		Point: &geojson.Point{
+			Coordinates: geojson.Position{1, 2, 3},
+		},
+	}
+	expected = "01e9030000000000000000f03f00000000000000400000000000000840"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if hex.EncodeToString(b) != expected {
+		t.Errorf("expected %q but got %q", expected, hex.EncodeToString(b))
+	}
+
+	// Success on type LineString
+	g = geojson.Geometry{
+		LineString: &geojson.LineString{
+			Coordinates: geojson.Positions{{1, 2}, {3, 4}},
+		},
+	}
+	expected = "010200000002000000000000000000f03f000000000000004000000000000008400000000000001040"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if hex.EncodeToString(b) != expected {
+		t.Errorf("expected %q but got %q", expected, hex.EncodeToString(b))
+	}
+
+	// Fail without a geometry
+	g = geojson.Geometry{}
+	if _, err := Marshal(&g); err != geojson.ErrNoGeometry {
+		t.Errorf("expected '%v' but got '%v'", geojson.ErrNoGeometry, err)
+	}
+
+	// Fail with mixed dimensions
+	g = geojson.Geometry{
+		LineString: &geojson.LineString{
+			Coordinates: geojson.Positions{{1, 2}, {3, 4, 5}},
+		},
+	}
+	if _, err := Marshal(&g); err != ErrMixedDimensions {
+		t.Errorf("expected '%v' but got '%v'", ErrMixedDimensions, err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	// Success on little endian Point
+	b, _ := hex.DecodeString("0101000000000000000000f03f0000000000000040")
+	g := geojson.Geometry{}
+	if err := Unmarshal(b, &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Type != "Point" || g.Point == nil || g.Point.Type != "Point" {
+		t.Errorf("expected Point but got %#v", g)
+	} else if !reflect.DeepEqual(g.Point.Coordinates, geojson.Position{1, 2}) {
+		t.Errorf("expected %v but got %v", geojson.Position{1, 2}, g.Point.Coordinates)
+	}
+
+	// Success on big endian Point
+	b, _ = hex.DecodeString("00000000013ff00000000000004000000000000000")
+	g = geojson.Geometry{}
+	if err := Unmarshal(b, &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Point == nil || !reflect.DeepEqual(g.Point.Coordinates, geojson.Position{1, 2}) {
+		t.Errorf("expected Point [1 2] but got %#v", g)
+	}
+
+	// Success on EWKB Point with Z and SRID
+	b, _ = hex.DecodeString("01010000a0e6100000000000000000f03f00000000000000400000000000000840")
+	g = geojson.Geometry{}
+	if err := Unmarshal(b, &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Point == nil || !reflect.DeepEqual(g.Point.Coordinates, geojson.Position{1, 2, 3}) {
+		t.Errorf("expected Point [1 2 3] but got %#v", g)
+	}
+
+	// Fail on trailing data
+	b, _ = hex.DecodeString("0101000000000000000000f03f000000000000004000")
+	g = geojson.Geometry{}
+	if err := Unmarshal(b, &g); err != ErrInvalidWKB {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidWKB, err)
+	}
+
+	// Fail on truncated data
+	b, _ = hex.DecodeString("0101000000000000000000f03f")
+	g = geojson.Geometry{}
+	if err := Unmarshal(b, &g); err != ErrInvalidWKB {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidWKB, err)
+	}
+
+	// Fail on unknown type
+	b, _ = hex.DecodeString("0109000000")
+	g = geojson.Geometry{}
+	if err := Unmarshal(b, &g); err != ErrInvalidWKB {
+		t.Errorf("expected '%v' but got '%v'", ErrInvalidWKB, err)
+	}
+}
+
+func TestThereAndBackAgain(t *testing.T) {
+	geometries := []geojson.Geometry{
+		{MultiPoint: &geojson.MultiPoint{Coordinates: geojson.Positions{{1, 2}, {3, 4}}}},
+		{MultiLineString: &geojson.MultiLineString{Coordinates: []geojson.Positions{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}}}},
+		{Polygon: &geojson.Polygon{Coordinates: []geojson.Positions{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}}}}},
+		{MultiPolygon: &geojson.MultiPolygon{Coordinates: [][]geojson.Positions{
+			{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+		}}},
+		{GeometryCollection: &geojson.GeometryCollection{Geometries: []geojson.Geometry{
+			{Point: &geojson.Point{Coordinates: geojson.Position{1, 2}}},
+			{LineString: &geojson.LineString{Coordinates: geojson.Positions{{1, 2}, {3, 4}}}},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	for i := range geometries {
+		if err := e.Encode(&geometries[i]); err != nil {
+			t.Errorf("expected nil but got '%v'", err)
+			return
+		}
+	}
+
+	d := NewDecoder(&buf)
+	for i := range geometries {
+		g := geojson.Geometry{}
+		if err := d.Decode(&g); err != nil {
+			t.Errorf("expected nil but got '%v'", err)
+			return
+		}
+
+		expected, _ := Marshal(&geometries[i])
+		if actual, err := Marshal(&g); err != nil {
+			t.Errorf("expected nil but got '%v'", err)
+		} else if !bytes.Equal(expected, actual) {
+			t.Errorf("expected %x but got %x", expected, actual)
+		}
+	}
+
+	g := geojson.Geometry{}
+	if err := d.Decode(&g); err != io.EOF {
+		t.Errorf("expected '%v' but got '%v'", io.EOF, err)
+	}
+}
@@ -0,0 +1,483 @@
+// Package wkt encodes and decodes GeoJSON geometries using the OGC Well-Known
+// Text representation.
+//
+// Positions with three values are written with the Z tag and positions with
+// four values with the ZM tag. When decoding, M values of XYM geometries are
+// dropped since a GeoJSON position has no way to represent them.
+package wkt
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/geojson"
+)
+
+// ErrInvalidWKT happens when Unmarshal is given text that isn't valid WKT
+var ErrInvalidWKT = errors.New("invalid WKT")
+
+// Marshal returns the WKT representation of a Geometry. Exactly one of the
+// geometry types must be filled in.
+func Marshal(g *geojson.Geometry) ([]byte, error) {
+	return appendGeometry(nil, g)
+}
+
+func appendGeometry(b []byte, g *geojson.Geometry) ([]byte, error) {
+	if g == nil {
+		return nil, geojson.ErrNoGeometry
+	}
+
+	i := 0
+	for _, set := range []bool{
+		g.Point != nil, g.MultiPoint != nil,
+		g.LineString != nil, g.MultiLineString != nil,
+		g.Polygon != nil, g.MultiPolygon != nil,
+		g.GeometryCollection != nil,
+	} {
+		if set {
+			i++
+		}
+	}
+
+	// Exactly one geometry must be specified
+	if i == 0 {
+		return nil, geojson.ErrNoGeometry
+	} else if i >= 2 {
+		return nil, geojson.ErrMultipleGeometries
+	}
+
+	switch {
+	case g.Point != nil:
+		c := g.Point.Coordinates
+		b = appendTag(b, "POINT", len(c))
+		if len(c) == 0 {
+			return append(b, "EMPTY"...), nil
+		}
+		b = append(b, '(')
+		b = appendPosition(b, c)
+		return append(b, ')'), nil
+	case g.MultiPoint != nil:
+		c := g.MultiPoint.Coordinates
+		b = appendTag(b, "MULTIPOINT", dimension(c))
+		if len(c) == 0 {
+			return append(b, "EMPTY"...), nil
+		}
+		b = append(b, '(')
+		for i, p := range c {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = append(b, '(')
+			b = appendPosition(b, p)
+			b = append(b, ')')
+		}
+		return append(b, ')'), nil
+	case g.LineString != nil:
+		c := g.LineString.Coordinates
+		b = appendTag(b, "LINESTRING", dimension(c))
+		return appendPositions(b, c), nil
+	case g.MultiLineString != nil:
+		c := g.MultiLineString.Coordinates
+		b = appendTag(b, "MULTILINESTRING", dimension(flatten(c)))
+		return appendRings(b, c), nil
+	case g.Polygon != nil:
+		c := g.Polygon.Coordinates
+		b = appendTag(b, "POLYGON", dimension(flatten(c)))
+		return appendRings(b, c), nil
+	case g.MultiPolygon != nil:
+		c := g.MultiPolygon.Coordinates
+		var all []geojson.Positions
+		for _, p := range c {
+			all = append(all, p...)
+		}
+		b = appendTag(b, "MULTIPOLYGON", dimension(flatten(all)))
+		if len(c) == 0 {
+			return append(b, "EMPTY"...), nil
+		}
+		b = append(b, '(')
+		for i, p := range c {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = appendRings(b, p)
+		}
+		return append(b, ')'), nil
+	}
+
+	c := g.GeometryCollection.Geometries
+	b = append(b, "GEOMETRYCOLLECTION "...)
+	if len(c) == 0 {
+		return append(b, "EMPTY"...), nil
+	}
+	b = append(b, '(')
+	for i := range c {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		var err error
+		if b, err = appendGeometry(b, &c[i]); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, ')'), nil
+}
+
+func flatten(rings []geojson.Positions) geojson.Positions {
+	for _, r := range rings {
+		if len(r) > 0 {
+			return r
+		}
+	}
+	return nil
+}
+
+func dimension(p geojson.Positions) int {
+	if len(p) == 0 {
+		return 0
+	}
+	return len(p[0])
+}
+
+func appendTag(b []byte, tag string, dim int) []byte {
+	b = append(b, tag...)
+	switch dim {
+	case 3:
+		b = append(b, " Z"...)
+	case 4:
+		b = append(b, " ZM"...)
+	}
+	return append(b, ' ')
+}
+
+func appendPosition(b []byte, p geojson.Position) []byte {
+	for i, v := range p {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = strconv.AppendFloat(b, v, 'f', -1, 64)
+	}
+	return b
+}
+
+func appendPositions(b []byte, ps geojson.Positions) []byte {
+	if len(ps) == 0 {
+		return append(b, "EMPTY"...)
+	}
+	b = append(b, '(')
+	for i, p := range ps {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendPosition(b, p)
+	}
+	return append(b, ')')
+}
+
+func appendRings(b []byte, rings []geojson.Positions) []byte {
+	if len(rings) == 0 {
+		return append(b, "EMPTY"...)
+	}
+	b = append(b, '(')
+	for i, r := range rings {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendPositions(b, r)
+	}
+	return append(b, ')')
+}
+
+// Unmarshal parses WKT text and fills in the appropriate geometry type of g.
+// An optional EWKT "SRID=...;" prefix is accepted and ignored.
+func Unmarshal(data []byte, g *geojson.Geometry) error {
+	p := parser{s: string(data)}
+
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(p.s)), "SRID=") {
+		i := strings.IndexByte(p.s, ';')
+		if i < 0 {
+			return ErrInvalidWKT
+		}
+		p.pos = i + 1
+	}
+
+	r, err := p.geometry()
+	if err != nil {
+		return err
+	}
+	if p.next() != "" {
+		return ErrInvalidWKT
+	}
+
+	*g = *r
+	return nil
+}
+
+type parser struct {
+	s   string
+	pos int
+	// peeked holds a token read by peek but not yet consumed
+	peeked string
+	// dropM is set while parsing an XYM geometry
+	dropM bool
+}
+
+func (p *parser) next() string {
+	if p.peeked != "" {
+		t := p.peeked
+		p.peeked = ""
+		return t
+	}
+
+	for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		return ""
+	}
+
+	start := p.pos
+	switch p.s[p.pos] {
+	case '(', ')', ',':
+		p.pos++
+		return p.s[start:p.pos]
+	}
+	for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && !strings.ContainsRune("(),", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *parser) peek() string {
+	if p.peeked == "" {
+		p.peeked = p.next()
+	}
+	return p.peeked
+}
+
+func (p *parser) expect(t string) error {
+	if p.next() != t {
+		return ErrInvalidWKT
+	}
+	return nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// geometry parses a tagged geometry: TYPE [Z|M|ZM] (EMPTY | body)
+func (p *parser) geometry() (*geojson.Geometry, error) {
+	tag := strings.ToUpper(p.next())
+
+	p.dropM = false
+	switch strings.ToUpper(p.peek()) {
+	case "Z", "ZM":
+		p.next()
+	case "M":
+		p.next()
+		p.dropM = true
+	}
+
+	empty := false
+	if strings.ToUpper(p.peek()) == "EMPTY" {
+		p.next()
+		empty = true
+	}
+
+	g := new(geojson.Geometry)
+	var err error
+
+	switch tag {
+	case "POINT":
+		g.Point = &geojson.Point{Coordinates: geojson.Position{}}
+		if !empty {
+			g.Point.Coordinates, err = p.point()
+		}
+	case "MULTIPOINT":
+		g.MultiPoint = &geojson.MultiPoint{Coordinates: geojson.Positions{}}
+		if !empty {
+			g.MultiPoint.Coordinates, err = p.multiPoint()
+		}
+	case "LINESTRING":
+		g.LineString = &geojson.LineString{Coordinates: geojson.Positions{}}
+		if !empty {
+			g.LineString.Coordinates, err = p.positions()
+		}
+	case "MULTILINESTRING":
+		g.MultiLineString = &geojson.MultiLineString{Coordinates: []geojson.Positions{}}
+		if !empty {
+			g.MultiLineString.Coordinates, err = p.rings()
+		}
+	case "POLYGON":
+		g.Polygon = &geojson.Polygon{Coordinates: []geojson.Positions{}}
+		if !empty {
+			g.Polygon.Coordinates, err = p.rings()
+		}
+	case "MULTIPOLYGON":
+		g.MultiPolygon = &geojson.MultiPolygon{Coordinates: [][]geojson.Positions{}}
+		if !empty {
+			g.MultiPolygon.Coordinates, err = p.polygons()
+		}
+	case "GEOMETRYCOLLECTION":
+		g.GeometryCollection = &geojson.GeometryCollection{Geometries: []geojson.Geometry{}}
+		if !empty {
+			g.GeometryCollection.Geometries, err = p.geometries()
+		}
+	default:
+		return nil, ErrInvalidWKT
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setType(g)
+	return g, nil
+}
+
+// setType fills in Type the same way unmarshalling GeoJSON does
+func setType(g *geojson.Geometry) {
+	var o *geojson.Object
+	switch {
+	case g.Point != nil:
+		g.Type, o = "Point", &g.Point.Object
+	case g.MultiPoint != nil:
+		g.Type, o = "MultiPoint", &g.MultiPoint.Object
+	case g.LineString != nil:
+		g.Type, o = "LineString", &g.LineString.Object
+	case g.MultiLineString != nil:
+		g.Type, o = "MultiLineString", &g.MultiLineString.Object
+	case g.Polygon != nil:
+		g.Type, o = "Polygon", &g.Polygon.Object
+	case g.MultiPolygon != nil:
+		g.Type, o = "MultiPolygon", &g.MultiPolygon.Object
+	case g.GeometryCollection != nil:
+		g.Type, o = "GeometryCollection", &g.GeometryCollection.Object
+	}
+	o.Type = g.Type
+}
+
+// position parses whitespace separated coordinate values
+func (p *parser) position() (geojson.Position, error) {
+	var pos geojson.Position
+	for {
+		t := p.peek()
+		if t == "" || t == "," || t == ")" || t == "(" {
+			break
+		}
+		p.next()
+		v, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil, ErrInvalidWKT
+		}
+		pos = append(pos, v)
+	}
+	if len(pos) < 2 {
+		return nil, ErrInvalidWKT
+	}
+	if p.dropM && len(pos) == 3 {
+		pos = pos[:2]
+	}
+	return pos, nil
+}
+
+func (p *parser) point() (geojson.Position, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	pos, err := p.position()
+	if err != nil {
+		return nil, err
+	}
+	return pos, p.expect(")")
+}
+
+// list parses a parenthesised, comma separated list calling item for each
+// element
+func (p *parser) list(item func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		switch p.next() {
+		case ",":
+		case ")":
+			return nil
+		default:
+			return ErrInvalidWKT
+		}
+	}
+}
+
+func (p *parser) positions() (geojson.Positions, error) {
+	var ps geojson.Positions
+	err := p.list(func() error {
+		pos, err := p.position()
+		ps = append(ps, pos)
+		return err
+	})
+	return ps, err
+}
+
+// multiPoint accepts both MULTIPOINT ((1 2), (3 4)) and MULTIPOINT (1 2, 3 4)
+func (p *parser) multiPoint() (geojson.Positions, error) {
+	var ps geojson.Positions
+	err := p.list(func() error {
+		var pos geojson.Position
+		var err error
+		if p.peek() == "(" {
+			pos, err = p.point()
+		} else {
+			pos, err = p.position()
+		}
+		ps = append(ps, pos)
+		return err
+	})
+	return ps, err
+}
+
+func (p *parser) rings() ([]geojson.Positions, error) {
+	var rs []geojson.Positions
+	err := p.list(func() error {
+		if strings.ToUpper(p.peek()) == "EMPTY" {
+			p.next()
+			rs = append(rs, geojson.Positions{})
+			return nil
+		}
+		ps, err := p.positions()
+		rs = append(rs, ps)
+		return err
+	})
+	return rs, err
+}
+
+func (p *parser) polygons() ([][]geojson.Positions, error) {
+	var ps [][]geojson.Positions
+	err := p.list(func() error {
+		if strings.ToUpper(p.peek()) == "EMPTY" {
+			p.next()
+			ps = append(ps, []geojson.Positions{})
+			return nil
+		}
+		rs, err := p.rings()
+		ps = append(ps, rs)
+		return err
+	})
+	return ps, err
+}
+
+func (p *parser) geometries() ([]geojson.Geometry, error) {
+	var gs []geojson.Geometry
+	err := p.list(func() error {
+		g, err := p.geometry()
+		if err != nil {
+			return err
+		}
+		gs = append(gs, *g)
+		return nil
+	})
+	return gs, err
+}
@@ -0,0 +1,210 @@
+package wkt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	// Success on type Point
+	g := geojson.Geometry{
+		Point: &geojson.Point{
+			Coordinates: geojson.Position{1.5, 10},
+		},
+	}
+	expected := "POINT (1.5 10)"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Success on type Point with Z
+	g = geojson.Geometry{
+		Point: &geojson.Point{
+			Coordinates: geojson.Position{1.5, 10, 100},
+		},
+	}
+	expected = "POINT Z (1.5 10 100)"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Success on type MultiPoint
+	g = geojson.Geometry{
+		MultiPoint: &geojson.MultiPoint{
+			Coordinates: geojson.Positions{{1, 2}, {3, 4}},
+		},
+	}
+	expected = "MULTIPOINT ((1 2), (3 4))"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Success on type Polygon
+	g = geojson.Geometry{
+		Polygon: &geojson.Polygon{
+			Coordinates: []geojson.Positions{
+				{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			},
+		},
+	}
+	expected = "POLYGON ((0 0, 1 0, 1 1, 0 0))"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Success on type MultiPolygon
+	g = geojson.Geometry{
+		MultiPolygon: &geojson.MultiPolygon{
+			Coordinates: [][]geojson.Positions{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+			},
+		},
+	}
+	expected = "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Success on type GeometryCollection
+	g = geojson.Geometry{
+		GeometryCollection: &geojson.GeometryCollection{
+			Geometries: []geojson.Geometry{
+				{Point: &geojson.Point{Coordinates: geojson.Position{1, 2}}},
+				{LineString: &geojson.LineString{Coordinates: geojson.Positions{{1, 2}, {3, 4}}}},
+			},
+		},
+	}
+	expected = "GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (1 2, 3 4))"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Success on empty geometries
+	g = geojson.Geometry{
+		LineString: &geojson.LineString{},
+	}
+	expected = "LINESTRING EMPTY"
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != expected {
+		t.Errorf("expected %q but got %q", expected, string(b))
+	}
+
+	// Fail without a geometry
+	g = geojson.Geometry{}
+	if _, err := Marshal(&g); err != geojson.ErrNoGeometry {
+		t.Errorf("expected '%v' but got '%v'", geojson.ErrNoGeometry, err)
+	}
+
+	// Fail with multiple geometries
+	g = geojson.Geometry{
+		Point:      &geojson.Point{Coordinates: geojson.Position{1, 2}},
+		LineString: &geojson.LineString{Coordinates: geojson.Positions{{1, 2}, {3, 4}}},
+	}
+	if _, err := Marshal(&g); err != geojson.ErrMultipleGeometries {
+		t.Errorf("expected '%v' but got '%v'", geojson.ErrMultipleGeometries, err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	// Success on type Point
+	g := geojson.Geometry{}
+	if err := Unmarshal([]byte("POINT (1.5 10)"), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Type != "Point" || g.Point == nil || g.Point.Type != "Point" {
+		t.Errorf("expected Point but got %#v", g)
+	} else if !reflect.DeepEqual(g.Point.Coordinates, geojson.Position{1.5, 10}) {
+		t.Errorf("expected %v but got %v", geojson.Position{1.5, 10}, g.Point.Coordinates)
+	}
+
+	// Success on lower case, Z and EWKT input
+	g = geojson.Geometry{}
+	if err := Unmarshal([]byte("SRID=4326;point z(1 2 3)"), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Point == nil || !reflect.DeepEqual(g.Point.Coordinates, geojson.Position{1, 2, 3}) {
+		t.Errorf("expected Point [1 2 3] but got %#v", g)
+	}
+
+	// Success on XYM input which drops the measure
+	g = geojson.Geometry{}
+	if err := Unmarshal([]byte("LINESTRING M (1 2 3, 4 5 6)"), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if expected := (geojson.Positions{{1, 2}, {4, 5}}); g.LineString == nil || !reflect.DeepEqual(g.LineString.Coordinates, expected) {
+		t.Errorf("expected LineString %v but got %#v", expected, g)
+	}
+
+	// Success on both MultiPoint forms
+	for _, s := range []string{"MULTIPOINT ((1 2), (3 4))", "MULTIPOINT (1 2, 3 4)"} {
+		g = geojson.Geometry{}
+		if err := Unmarshal([]byte(s), &g); err != nil {
+			t.Errorf("expected nil but got '%v'", err)
+		} else if expected := (geojson.Positions{{1, 2}, {3, 4}}); g.MultiPoint == nil || !reflect.DeepEqual(g.MultiPoint.Coordinates, expected) {
+			t.Errorf("expected MultiPoint %v but got %#v", expected, g)
+		}
+	}
+
+	// Success on type MultiPolygon
+	g = geojson.Geometry{}
+	if err := Unmarshal([]byte("MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5), (5.2 5.2, 5.8 5.2, 5.8 5.8, 5.2 5.2)))"), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.MultiPolygon == nil || len(g.MultiPolygon.Coordinates) != 2 || len(g.MultiPolygon.Coordinates[1]) != 2 {
+		t.Errorf("expected MultiPolygon with 2 polygons but got %#v", g)
+	}
+
+	// Success on type GeometryCollection
+	g = geojson.Geometry{}
+	if err := Unmarshal([]byte("GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (1 2, 3 4))"), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.GeometryCollection == nil || len(g.GeometryCollection.Geometries) != 2 {
+		t.Errorf("expected GeometryCollection with 2 geometries but got %#v", g)
+	} else if g.GeometryCollection.Geometries[1].Type != "LineString" {
+		t.Errorf("expected %q but got %q", "LineString", g.GeometryCollection.Geometries[1].Type)
+	}
+
+	// Success on EMPTY
+	g = geojson.Geometry{}
+	if err := Unmarshal([]byte("POLYGON EMPTY"), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if g.Polygon == nil || len(g.Polygon.Coordinates) != 0 {
+		t.Errorf("expected empty Polygon but got %#v", g)
+	}
+
+	// Fail on invalid input
+	for _, s := range []string{"", "POINT", "POINT (1)", "POINT (1 2", "POINT (1 2) x", "CIRCLE (1 2)", "POINT (a b)"} {
+		g = geojson.Geometry{}
+		if err := Unmarshal([]byte(s), &g); err != ErrInvalidWKT {
+			t.Errorf("expected '%v' for %q but got '%v'", ErrInvalidWKT, s, err)
+		}
+	}
+}
+
+func TestThereAndBackAgain(t *testing.T) {
+	s := "MULTILINESTRING Z ((1 2 3, 4 5 6), (7 8 9, 10 11 12))"
+
+	g := geojson.Geometry{}
+	if err := Unmarshal([]byte(s), &g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+		return
+	}
+
+	if b, err := Marshal(&g); err != nil {
+		t.Errorf("expected nil but got '%v'", err)
+	} else if string(b) != s {
+		t.Errorf("expected %q but got %q", s, string(b))
+	}
+}